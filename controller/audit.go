@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// AuditActionCreate is the action of an audit entry for a created record
+	AuditActionCreate = "create"
+	// AuditActionUpdate is the action of an audit entry for an updated record
+	AuditActionUpdate = "update"
+	// AuditActionDelete is the action of an audit entry for a deleted record
+	AuditActionDelete = "delete"
+)
+
+// AuditEntry describes a single record change submitted to the DNS provider
+type AuditEntry struct {
+	Time          time.Time        `json:"time"`
+	Action        string           `json:"action"`
+	DNSName       string           `json:"dnsName"`
+	RecordType    string           `json:"recordType"`
+	SetIdentifier string           `json:"setIdentifier,omitempty"`
+	Resource      string           `json:"resource,omitempty"`
+	Owner         string           `json:"owner,omitempty"`
+	OldTargets    endpoint.Targets `json:"oldTargets,omitempty"`
+	NewTargets    endpoint.Targets `json:"newTargets,omitempty"`
+	// Accepted tells whether the provider applied the record change
+	Accepted bool   `json:"accepted"`
+	Error    string `json:"error,omitempty"`
+}
+
+// AuditLog is an append-only sink writing one JSON encoded AuditEntry per line
+type AuditLog struct {
+	w      io.Writer
+	closer io.Closer
+	mu     sync.Mutex
+	now    func() time.Time
+}
+
+// NewAuditLog creates an AuditLog appending to the file at path, "-" writes to stdout
+func NewAuditLog(path string) (*AuditLog, error) {
+	if path == "-" {
+		return newAuditLog(os.Stdout), nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	l := newAuditLog(f)
+	l.closer = f
+	return l, nil
+}
+
+func newAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w, now: time.Now}
+}
+
+// Close closes the underlying file
+func (l *AuditLog) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// Record writes an entry for every record change, applyErr is the error returned when applying the changes
+func (l *AuditLog) Record(changes *plan.Changes, applyErr error) error {
+	now := l.now().UTC()
+	entry := func(action string, previous, current *endpoint.Endpoint) *AuditEntry {
+		// the new version carries the labels of the registry after applying the changes
+		labeled := current
+		if labeled == nil {
+			labeled = previous
+		}
+		e := &AuditEntry{Time: now, Action: action, Accepted: true}
+		if err := provider.RecordErrorFor(applyErr, labeled); err != nil {
+			e.Accepted = false
+			e.Error = err.Error()
+		}
+		e.DNSName = labeled.DNSName
+		e.RecordType = labeled.RecordType
+		e.SetIdentifier = labeled.SetIdentifier
+		e.Resource = labeled.Labels[endpoint.ResourceLabelKey]
+		e.Owner = labeled.Labels[endpoint.OwnerLabelKey]
+		if previous != nil {
+			e.OldTargets = previous.Targets
+		}
+		if current != nil {
+			e.NewTargets = current.Targets
+		}
+		return e
+	}
+
+	entries := []*AuditEntry{}
+	for _, ep := range changes.Create {
+		entries = append(entries, entry(AuditActionCreate, nil, ep))
+	}
+	for i := range changes.UpdateNew {
+		var old *endpoint.Endpoint
+		if i < len(changes.UpdateOld) {
+			old = changes.UpdateOld[i]
+		}
+		entries = append(entries, entry(AuditActionUpdate, old, changes.UpdateNew[i]))
+	}
+	for _, ep := range changes.Delete {
+		entries = append(entries, entry(AuditActionDelete, ep, nil))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	enc := json.NewEncoder(l.w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+func TestAuditLogRecord(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := newAuditLog(buf)
+	l.now = func() time.Time { return time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC) }
+
+	labels := endpoint.Labels{endpoint.OwnerLabelKey: "owner", endpoint.ResourceLabelKey: "service/default/web"}
+	changes := &plan.Changes{
+		Create:    []*endpoint.Endpoint{{DNSName: "new.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}, Labels: labels}},
+		UpdateOld: []*endpoint.Endpoint{{DNSName: "web.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8"}, Labels: labels}},
+		UpdateNew: []*endpoint.Endpoint{{DNSName: "web.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.4.4"}, Labels: labels}},
+		Delete:    []*endpoint.Endpoint{{DNSName: "old.example.org", RecordType: endpoint.RecordTypeCNAME, SetIdentifier: "eu", Targets: endpoint.Targets{"lb.example.org"}, Labels: labels}},
+	}
+
+	require.NoError(t, l.Record(changes, nil))
+	require.NoError(t, l.Record(&plan.Changes{Delete: changes.Delete}, errors.New("throttled")))
+
+	assert.Equal(t, `{"time":"2022-10-01T12:00:00Z","action":"create","dnsName":"new.example.org","recordType":"A","resource":"service/default/web","owner":"owner","newTargets":["1.2.3.4"],"accepted":true}
+{"time":"2022-10-01T12:00:00Z","action":"update","dnsName":"web.example.org","recordType":"A","resource":"service/default/web","owner":"owner","oldTargets":["8.8.8.8"],"newTargets":["8.8.4.4"],"accepted":true}
+{"time":"2022-10-01T12:00:00Z","action":"delete","dnsName":"old.example.org","recordType":"CNAME","setIdentifier":"eu","resource":"service/default/web","owner":"owner","oldTargets":["lb.example.org"],"accepted":true}
+{"time":"2022-10-01T12:00:00Z","action":"delete","dnsName":"old.example.org","recordType":"CNAME","setIdentifier":"eu","resource":"service/default/web","owner":"owner","oldTargets":["lb.example.org"],"accepted":false,"error":"throttled"}
+`, buf.String())
+}
+
+func TestRunOnceAuditLog(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "create-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+	}, nil)
+
+	provider := newMockProvider(
+		[]*endpoint.Endpoint{},
+		&plan.Changes{
+			Create: []*endpoint.Endpoint{
+				{DNSName: "create-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+	)
+
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := NewAuditLog(path)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		AuditLog:           auditLog,
+	}
+
+	assert.NoError(t, ctrl.RunOnce(context.Background()))
+	require.NoError(t, auditLog.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"action":"create","dnsName":"create-record"`)
+	assert.Contains(t, string(data), `"accepted":true`)
+}
@@ -18,6 +18,9 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
 	"sync"
 	"time"
 
@@ -118,6 +121,30 @@ var (
 			Help:      "Number of DNS A-records that exists both in source and registry.",
 		},
 	)
+	changeBudgetExceededTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "change_budget_exceeded_total",
+			Help:      "Number of reconcile loops aborted because the changes exceeded the change budget.",
+		},
+	)
+	quarantinedRecords = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "quarantined_records",
+			Help:      "Number of records whose changes are not retried before their backoff expired because the provider failed to apply them.",
+		},
+	)
+	leader = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "leader",
+			Help:      "Whether this instance holds the leader election lease, 1 if it does and 0 otherwise.",
+		},
+	)
 )
 
 func init() {
@@ -132,6 +159,9 @@ func init() {
 	prometheus.MustRegister(registryARecords)
 	prometheus.MustRegister(sourceARecords)
 	prometheus.MustRegister(verifiedARecords)
+	prometheus.MustRegister(changeBudgetExceededTotal)
+	prometheus.MustRegister(quarantinedRecords)
+	prometheus.MustRegister(leader)
 }
 
 // Controller is responsible for orchestrating the different components.
@@ -145,6 +175,12 @@ type Controller struct {
 	Registry registry.Registry
 	// The policy that defines which changes to DNS records are allowed
 	Policy plan.Policy
+	// The resolver that decides which resource acquires a DNS name claimed by several resources
+	ConflictResolver plan.ConflictResolver
+	// The budget that aborts synchronizations deleting too many records (default: disabled)
+	ChangeBudget *plan.ChangeBudgetPolicy
+	// AdoptUnowned updates existing records without owner desired by endpoints labeled for adoption, so that the registry takes their ownership
+	AdoptUnowned bool
 	// The interval between individual synchronizations
 	Interval time.Duration
 	// The DomainFilter defines which DNS records to keep or exclude
@@ -157,6 +193,23 @@ type Controller struct {
 	ManagedRecordTypes []string
 	// MinEventSyncInterval is used as window for batching events
 	MinEventSyncInterval time.Duration
+	// PlanOutput is the file every calculated plan is written to, "-" writes to stdout (default: disabled)
+	PlanOutput string
+	// PlanOutputFormat is the format the plan is written in, either json or yaml
+	PlanOutputFormat string
+	// AuditLog records every change submitted to the registry (default: disabled)
+	AuditLog *AuditLog
+	// Reporter reports the outcome of each synchronization to the resources the records originate from (default: disabled)
+	Reporter Reporter
+	// QuarantineBackoff is the initial backoff of records the provider failed to change, it doubles with each failure (default: disabled)
+	QuarantineBackoff time.Duration
+	// QuarantineMaxBackoff is the maximum backoff of records the provider failed to change
+	QuarantineMaxBackoff time.Duration
+	// The quarantine of records the provider failed to change
+	quarantine *quarantine
+	// The ownership of the records read from the registry by the last synchronization
+	ownership    *ownershipSnapshot
+	ownershipMux sync.Mutex
 }
 
 // RunOnce runs a single iteration of a reconciliation loop.
@@ -168,7 +221,27 @@ func (c *Controller) RunOnce(ctx context.Context) error {
 		return err
 	}
 
+	// The registry maintains its ownership records, e.g. adopts or migrates them, before the actual plan is
+	// calculated, so that the plan is based on their new ownership.
+	var maintenancePlan *plan.Plan
+	if maintenance := registry.MaintenanceChanges(c.Registry); maintenance != nil && maintenance.HasChanges() {
+		maintenancePlan = c.planMaintenance(maintenance)
+		if err := c.applyMaintenance(ctx, maintenancePlan); err != nil {
+			registryErrorsTotal.Inc()
+			deprecatedRegistryErrors.Inc()
+			// the maintenance is planned again by the next synchronization
+			log.Errorf("Failed to maintain the ownership records: %v", err)
+		} else if maintenancePlan.Changes.HasChanges() {
+			if records, err = c.Registry.Records(ctx); err != nil {
+				registryErrorsTotal.Inc()
+				deprecatedRegistryErrors.Inc()
+				return err
+			}
+		}
+	}
+
 	missingRecords := c.Registry.MissingRecords()
+	c.setOwnership(records, registry.OrphanedRecords(c.Registry))
 
 	registryEndpointsTotal.Set(float64(len(records)))
 	regARecords := filterARecords(records)
@@ -201,7 +274,7 @@ func (c *Controller) RunOnce(ctx context.Context) error {
 		}
 		missingRecordsPlan = missingRecordsPlan.Calculate()
 		if missingRecordsPlan.Changes.HasChanges() {
-			err = c.Registry.ApplyChanges(ctx, missingRecordsPlan.Changes)
+			err = c.applyChanges(ctx, missingRecordsPlan.Changes)
 			if err != nil {
 				registryErrorsTotal.Inc()
 				deprecatedRegistryErrors.Inc()
@@ -211,33 +284,243 @@ func (c *Controller) RunOnce(ctx context.Context) error {
 		}
 	}
 
+	policies := []plan.Policy{c.Policy}
+	if c.ChangeBudget != nil {
+		policies = append(policies, c.ChangeBudget.WithCurrent(records))
+	}
+
 	plan := &plan.Plan{
-		Policies:           []plan.Policy{c.Policy},
+		Policies:           policies,
 		Current:            records,
 		Desired:            endpoints,
 		DomainFilter:       endpoint.MatchAllDomainFilters{c.DomainFilter, c.Registry.GetDomainFilter()},
 		PropertyComparator: c.Registry.PropertyValuesEqual,
 		ManagedRecords:     c.ManagedRecordTypes,
+		Resolver:           c.ConflictResolver,
+		AdoptUnowned:       c.AdoptUnowned,
 	}
 
 	plan = plan.Calculate()
 
-	if plan.Changes.HasChanges() {
-		err = c.Registry.ApplyChanges(ctx, plan.Changes)
-		if err != nil {
-			registryErrorsTotal.Inc()
-			deprecatedRegistryErrors.Inc()
-			return err
+	if c.PlanOutput != "" {
+		out := plan.Output()
+		if maintenancePlan != nil {
+			out.Maintenance = maintenancePlan.Output()
 		}
+		if err := c.writePlan(out); err != nil {
+			log.Errorf("Failed to write plan to %s: %v", c.PlanOutput, err)
+		}
+	}
+
+	if c.ChangeBudget != nil {
+		if dropped, exceeded := plan.Filtered[c.ChangeBudget.Name()]; exceeded {
+			changeBudgetExceededTotal.Inc()
+			return fmt.Errorf("change budget exceeded, none of the planned changes including %d deletions were applied", len(dropped.Delete))
+		}
+	}
+
+	changes := plan.Changes
+	if c.QuarantineBackoff > 0 {
+		changes = c.getQuarantine().filter(changes)
+	}
+
+	if changes.HasChanges() {
+		err = c.applyChanges(ctx, changes)
 	} else {
 		controllerNoChangesTotal.Inc()
 		log.Info("All records are already up to date")
 	}
+	c.report(ctx, records, endpoints, changes, plan.Conflicts, err)
+	if err != nil {
+		registryErrorsTotal.Inc()
+		deprecatedRegistryErrors.Inc()
+	}
+	// the records which failed are quarantined while all others have been applied
+	if c.QuarantineBackoff > 0 && c.quarantine.update(changes, err) {
+		quarantinedRecords.Set(float64(c.quarantine.size()))
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	lastSyncTimestamp.SetToCurrentTime()
+	return nil
+}
+
+// ApplyPlan applies the changes of a previously calculated plan, e.g. read from a plan output.
+// It refuses to apply them if the records to update or delete do not exist anymore
+// in their planned state or if the records to create already exist. Plans containing
+// changes of the ownership records are refused as well, they are only applied by a synchronization.
+// The new states of the records to update carry the labels of the current records, hence a plan cannot change their owners.
+func (c *Controller) ApplyPlan(ctx context.Context, out *plan.Output) error {
+	if out.Maintenance != nil && out.Maintenance.Changes != nil && out.Maintenance.Changes.HasChanges() {
+		return fmt.Errorf("plan contains changes of the ownership records, which are applied by the next synchronization only")
+	}
+	changes := out.Changes
+	if len(changes.UpdateNew) != len(changes.UpdateOld) {
+		return fmt.Errorf("plan is invalid: %d records to update but %d new states", len(changes.UpdateOld), len(changes.UpdateNew))
+	}
+	for i, ep := range changes.UpdateNew {
+		if !sameRecord(changes.UpdateOld[i], ep) {
+			return fmt.Errorf("plan is invalid: new state %v does not match the record to update %v", ep, changes.UpdateOld[i])
+		}
+	}
+
+	records, err := c.Registry.Records(ctx)
+	if err != nil {
+		registryErrorsTotal.Inc()
+		deprecatedRegistryErrors.Inc()
+		return err
+	}
 
+	verified := &plan.Changes{Create: changes.Create}
+	for _, ep := range changes.Create {
+		if current := findRecord(records, ep); current != nil {
+			return fmt.Errorf("plan is stale: record to create already exists: %v", current)
+		}
+	}
+	for _, ep := range changes.UpdateOld {
+		current := findRecord(records, ep)
+		if current == nil || !current.Targets.Same(ep.Targets) || current.Labels[endpoint.OwnerLabelKey] != ep.Labels[endpoint.OwnerLabelKey] {
+			return fmt.Errorf("plan is stale: record to update has changed: %v", ep)
+		}
+		// use the current record, the registry may rely on labels not contained in the plan
+		verified.UpdateOld = append(verified.UpdateOld, current)
+	}
+	for i, ep := range changes.UpdateNew {
+		verified.UpdateNew = append(verified.UpdateNew, withCurrentLabels(ep, verified.UpdateOld[i]))
+	}
+	for _, ep := range changes.Delete {
+		current := findRecord(records, ep)
+		if current == nil || !current.Targets.Same(ep.Targets) || current.Labels[endpoint.OwnerLabelKey] != ep.Labels[endpoint.OwnerLabelKey] {
+			return fmt.Errorf("plan is stale: record to delete has changed: %v", ep)
+		}
+		verified.Delete = append(verified.Delete, current)
+	}
+
+	if !verified.HasChanges() {
+		log.Info("Plan contains no changes")
+		return nil
+	}
+
+	ctx = context.WithValue(ctx, provider.RecordsContextKey, records)
+	err = c.applyChanges(ctx, verified)
+	c.report(ctx, records, nil, verified, nil, err)
+	if err != nil {
+		registryErrorsTotal.Inc()
+		deprecatedRegistryErrors.Inc()
+		return err
+	}
 	lastSyncTimestamp.SetToCurrentTime()
 	return nil
 }
 
+// withCurrentLabels returns a copy of the new state of a record carrying the labels of its current record, the labels
+// of the new state are kept except for the owner, which the plan cannot change like the planner never does either
+func withCurrentLabels(ep, current *endpoint.Endpoint) *endpoint.Endpoint {
+	updated := ep.DeepCopy()
+	updated.Labels = endpoint.NewLabels()
+	for k, v := range current.Labels {
+		updated.Labels[k] = v
+	}
+	for k, v := range ep.Labels {
+		if k != endpoint.OwnerLabelKey {
+			updated.Labels[k] = v
+		}
+	}
+	updated.Labels[endpoint.OwnerLabelKey] = current.Labels[endpoint.OwnerLabelKey]
+	return updated
+}
+
+// ApplyMaintenance applies changes of the ownership records, e.g. the deletions of a garbage collection,
+// under the policies like the changes of a synchronization
+func (c *Controller) ApplyMaintenance(ctx context.Context, changes *plan.Changes) error {
+	maintenancePlan := c.planMaintenance(changes)
+	if c.PlanOutput != "" {
+		if err := c.writePlan(&plan.Output{Policies: []string{}, Changes: &plan.Changes{}, Maintenance: maintenancePlan.Output()}); err != nil {
+			log.Errorf("Failed to write plan to %s: %v", c.PlanOutput, err)
+		}
+	}
+	if err := c.applyMaintenance(ctx, maintenancePlan); err != nil {
+		registryErrorsTotal.Inc()
+		deprecatedRegistryErrors.Inc()
+		return err
+	}
+	return nil
+}
+
+// planMaintenance calculates which changes of the ownership records pass the policies. The change budget does not
+// apply, it refers to the records while the registry limits the deletions of the ownership records itself.
+func (c *Controller) planMaintenance(maintenance *plan.Changes) *plan.Plan {
+	maintenancePlan := &plan.Plan{
+		Policies:    []plan.Policy{c.Policy},
+		Maintenance: maintenance,
+	}
+	return maintenancePlan.Calculate()
+}
+
+// applyMaintenance applies the changes of the ownership records planned by planMaintenance through the registry
+// and records them in the audit log.
+func (c *Controller) applyMaintenance(ctx context.Context, maintenancePlan *plan.Plan) error {
+	changes := maintenancePlan.Changes
+	if !changes.HasChanges() {
+		return nil
+	}
+	err := registry.ApplyMaintenanceChanges(ctx, c.Registry, changes)
+	c.audit(changes, err)
+	return err
+}
+
+// getQuarantine returns the quarantine of records the provider failed to change
+func (c *Controller) getQuarantine() *quarantine {
+	if c.quarantine == nil {
+		c.quarantine = newQuarantine(c.QuarantineBackoff, c.QuarantineMaxBackoff)
+	}
+	return c.quarantine
+}
+
+// applyChanges applies the changes to the registry and records them in the audit log
+func (c *Controller) applyChanges(ctx context.Context, changes *plan.Changes) error {
+	err := c.Registry.ApplyChanges(ctx, changes)
+	c.audit(changes, err)
+	return err
+}
+
+// audit records the applied changes in the audit log, if enabled
+func (c *Controller) audit(changes *plan.Changes, err error) {
+	if c.AuditLog != nil {
+		if auditErr := c.AuditLog.Record(changes, err); auditErr != nil {
+			log.Errorf("Failed to write audit log: %v", auditErr)
+		}
+	}
+}
+
+// findRecord returns the record with the same name, type and set identifier as ep
+func findRecord(records []*endpoint.Endpoint, ep *endpoint.Endpoint) *endpoint.Endpoint {
+	for _, r := range records {
+		if sameRecord(r, ep) {
+			return r
+		}
+	}
+	return nil
+}
+
+// writePlan writes the calculated plan to PlanOutput. Files are overwritten
+// on each run so that they always hold the latest plan.
+func (c *Controller) writePlan(out *plan.Output) error {
+	var w io.Writer = os.Stdout
+	if c.PlanOutput != "-" {
+		f, err := os.Create(c.PlanOutput)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return out.Write(w, c.PlanOutputFormat)
+}
+
 // Checks and returns the intersection of A records in endpoint and registry.
 func fetchMatchingARecords(endpoints []*endpoint.Endpoint, registryRecords []*endpoint.Endpoint) []string {
 	aRecords := filterARecords(endpoints)
@@ -18,8 +18,11 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"math"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -31,6 +34,7 @@ import (
 	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/inmemory"
 	"sigs.k8s.io/external-dns/registry"
 
 	"github.com/stretchr/testify/assert"
@@ -195,6 +199,348 @@ func TestRunOnce(t *testing.T) {
 	assert.Equal(t, math.Float64bits(1), valueFromMetric(verifiedARecords))
 }
 
+// TestRunOncePlanOutput tests that RunOnce writes the calculated plan.
+func TestRunOncePlanOutput(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "create-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+	}, nil)
+
+	provider := newMockProvider(
+		[]*endpoint.Endpoint{},
+		&plan.Changes{
+			Create: []*endpoint.Endpoint{
+				{DNSName: "create-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+	)
+
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+
+	output := filepath.Join(t.TempDir(), "plan.json")
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		PlanOutput:         output,
+		PlanOutputFormat:   plan.OutputFormatJSON,
+	}
+
+	assert.NoError(t, ctrl.RunOnce(context.Background()))
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	written := &plan.Output{}
+	require.NoError(t, json.Unmarshal(data, written))
+	assert.Equal(t, []string{"sync"}, written.Policies)
+	require.Len(t, written.Changes.Create, 1)
+	assert.Equal(t, "create-record", written.Changes.Create[0].DNSName)
+}
+
+// TestRunOnceChangeBudgetExceeded tests that RunOnce refuses to apply changes exceeding the change budget.
+func TestRunOnceChangeBudgetExceeded(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{}, nil)
+
+	provider := &mockProvider{
+		RecordsStore: []*endpoint.Endpoint{
+			{DNSName: "delete-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"4.3.2.1"}},
+		},
+	}
+	provider.ExpectChanges = &plan.Changes{Delete: provider.RecordsStore}
+
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		ChangeBudget:       &plan.ChangeBudgetPolicy{MaxDeletePercentage: 50},
+	}
+
+	assert.Error(t, ctrl.RunOnce(context.Background()))
+
+	ctrl.ChangeBudget = &plan.ChangeBudgetPolicy{MaxDeletes: 1}
+	assert.NoError(t, ctrl.RunOnce(context.Background()))
+}
+
+// TestRunOnceMaintenance tests that RunOnce applies the changes of the ownership records under the policy
+// before the plan is calculated, and writes them to the plan output and the audit log.
+func TestRunOnceMaintenance(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone("example.org"))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("adopted.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("adopted.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=old\""),
+			endpoint.NewEndpoint("a-adopted.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=old\""),
+			endpoint.NewEndpoint("gone.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=owner\""),
+		},
+	}))
+
+	r, err := registry.NewTXTRegistry(p, "", "", "owner", 0, "", []string{endpoint.RecordTypeA},
+		registry.WithTXTPreviousOwnerIDs([]string{"old"}),
+		registry.WithTXTGarbageCollection(time.Hour, 0),
+	)
+	require.NoError(t, err)
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("adopted.example.org", endpoint.RecordTypeA, "5.6.7.8"),
+	}, nil)
+
+	dir := t.TempDir()
+	auditLog, err := NewAuditLog(filepath.Join(dir, "audit.log"))
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.UpsertOnlyPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		PlanOutput:         filepath.Join(dir, "plan.json"),
+		PlanOutputFormat:   plan.OutputFormatJSON,
+		AuditLog:           auditLog,
+	}
+	require.NoError(t, ctrl.RunOnce(ctx))
+	require.NoError(t, auditLog.Close())
+
+	// the adopted record is updated by the new owner within the same run
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	targets := map[string]string{}
+	for _, record := range records {
+		targets[record.DNSName+"/"+record.RecordType] = record.Targets[0]
+	}
+	assert.Equal(t, map[string]string{
+		"adopted.example.org/A":     "5.6.7.8",
+		"adopted.example.org/TXT":   "\"heritage=external-dns,external-dns/owner=owner\"",
+		"a-adopted.example.org/TXT": "\"heritage=external-dns,external-dns/owner=owner\"",
+		"gone.example.org/TXT":      "\"heritage=external-dns,external-dns/owner=owner\"",
+	}, targets, "the orphaned TXT record is not deleted under the upsert-only policy")
+
+	data, err := os.ReadFile(filepath.Join(dir, "plan.json"))
+	require.NoError(t, err)
+	written := &plan.Output{}
+	require.NoError(t, json.Unmarshal(data, written))
+	require.NotNil(t, written.Maintenance)
+	assert.Len(t, written.Maintenance.Changes.UpdateNew, 2)
+	require.Contains(t, written.Maintenance.Filtered, "upsert-only")
+	require.Len(t, written.Maintenance.Filtered["upsert-only"].Delete, 1)
+	assert.Equal(t, "gone.example.org", written.Maintenance.Filtered["upsert-only"].Delete[0].DNSName)
+
+	data, err = os.ReadFile(filepath.Join(dir, "audit.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"action":"update","dnsName":"a-adopted.example.org","recordType":"TXT"`)
+}
+
+// TestApplyMaintenance tests that ApplyMaintenance deletes orphaned TXT records regardless of the change budget.
+func TestApplyMaintenance(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone("example.org"))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("gone.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=owner\""),
+			endpoint.NewEndpoint("a-gone.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=owner\""),
+		},
+	}))
+	r, err := registry.NewTXTRegistry(p, "", "", "owner", 0, "", []string{endpoint.RecordTypeA})
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Registry:     r,
+		Policy:       &plan.SyncPolicy{},
+		ChangeBudget: &plan.ChangeBudgetPolicy{MaxDeletes: 1, OwnerIDs: []string{"owner"}},
+	}
+	garbage, err := r.GarbageChanges(ctx)
+	require.NoError(t, err)
+	require.Len(t, garbage.Delete, 2)
+	assert.NoError(t, ctrl.ApplyMaintenance(ctx, garbage))
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "foo.example.org", records[0].DNSName)
+}
+
+// TestRunOnceTXTFormatTypedChangeBudget tests that the deletions of the TXT records in the old format are limited
+// by the registry and do not count against the change budget.
+func TestRunOnceTXTFormatTypedChangeBudget(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone("example.org"))
+	endpoints := []*endpoint.Endpoint{}
+	txtRecords := []*endpoint.Endpoint{}
+	for _, name := range []string{"a.example.org", "b.example.org", "c.example.org"} {
+		endpoints = append(endpoints, endpoint.NewEndpoint(name, endpoint.RecordTypeA, "1.2.3.4"))
+		txtRecords = append(txtRecords,
+			endpoint.NewEndpoint(name, endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=owner\""),
+			endpoint.NewEndpoint("a-"+name, endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=owner\""),
+		)
+	}
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: append(endpoints, txtRecords...)}))
+
+	r, err := registry.NewTXTRegistry(p, "", "", "owner", 0, "", []string{endpoint.RecordTypeA},
+		registry.WithTXTFormat(registry.TXTFormatTyped),
+		registry.WithTXTGarbageCollection(0, 2),
+	)
+	require.NoError(t, err)
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return(endpoints, nil)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		ChangeBudget:       &plan.ChangeBudgetPolicy{MaxDeletes: 1, MaxDeletePercentage: 10, OwnerIDs: []string{"owner"}},
+	}
+	legacyTXTRecords := func() []string {
+		records, err := p.Records(ctx)
+		require.NoError(t, err)
+		names := []string{}
+		for _, record := range records {
+			if record.RecordType == endpoint.RecordTypeTXT && record.DNSName[:2] != "a-" {
+				names = append(names, record.DNSName)
+			}
+		}
+		return names
+	}
+
+	require.NoError(t, ctrl.RunOnce(ctx))
+	assert.Equal(t, []string{"c.example.org"}, legacyTXTRecords(), "at most two TXT records in the old format are deleted at once")
+	require.NoError(t, ctrl.RunOnce(ctx))
+	assert.Empty(t, legacyTXTRecords())
+}
+
+func TestApplyPlan(t *testing.T) {
+	current := []*endpoint.Endpoint{
+		{DNSName: "update-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "owner"}},
+		{DNSName: "delete-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"4.3.2.1"}},
+	}
+	for _, tc := range []struct {
+		name        string
+		changes     *plan.Changes
+		maintenance *plan.Changes
+		wantErr     bool
+	}{
+		{
+			name: "matching plan is applied",
+			changes: &plan.Changes{
+				Create:    []*endpoint.Endpoint{{DNSName: "create-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}}},
+				UpdateOld: []*endpoint.Endpoint{{DNSName: "update-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "owner"}}},
+				UpdateNew: []*endpoint.Endpoint{{DNSName: "update-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.4.4"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "owner"}}},
+				Delete:    []*endpoint.Endpoint{{DNSName: "delete-record.", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"4.3.2.1"}}},
+			},
+		},
+		{
+			name: "record to create exists",
+			changes: &plan.Changes{
+				Create: []*endpoint.Endpoint{{DNSName: "delete-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "record to update has changed targets",
+			changes: &plan.Changes{
+				UpdateOld: []*endpoint.Endpoint{{DNSName: "update-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"9.9.9.9"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "owner"}}},
+				UpdateNew: []*endpoint.Endpoint{{DNSName: "update-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.4.4"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "owner"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "record to update has changed owner",
+			changes: &plan.Changes{
+				UpdateOld: []*endpoint.Endpoint{{DNSName: "update-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "other"}}},
+				UpdateNew: []*endpoint.Endpoint{{DNSName: "update-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.4.4"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "other"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "record to delete is gone",
+			changes: &plan.Changes{
+				Delete: []*endpoint.Endpoint{{DNSName: "gone-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"4.3.2.1"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "new state of another record",
+			changes: &plan.Changes{
+				UpdateOld: []*endpoint.Endpoint{{DNSName: "update-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "owner"}}},
+				UpdateNew: []*endpoint.Endpoint{{DNSName: "delete-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.4.4"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "owner"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "new state missing",
+			changes: &plan.Changes{
+				UpdateOld: []*endpoint.Endpoint{{DNSName: "update-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "owner"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "changes of the ownership records",
+			changes: &plan.Changes{},
+			maintenance: &plan.Changes{
+				Delete: []*endpoint.Endpoint{{DNSName: "gone-record", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"\"heritage=external-dns,external-dns/owner=owner\""}}},
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := &filteredMockProvider{RecordsStore: current}
+			r, err := registry.NewNoopRegistry(provider)
+			require.NoError(t, err)
+
+			ctrl := &Controller{Registry: r}
+			out := &plan.Output{Changes: tc.changes}
+			if tc.maintenance != nil {
+				out.Maintenance = &plan.Output{Changes: tc.maintenance}
+			}
+			err = ctrl.ApplyPlan(context.Background(), out)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.Empty(t, provider.ApplyChangesCalls)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, provider.ApplyChangesCalls, 1)
+			assert.Equal(t, current[1], provider.ApplyChangesCalls[0].Delete[0])
+		})
+	}
+}
+
+func TestApplyPlanUpdateLabels(t *testing.T) {
+	current := []*endpoint.Endpoint{
+		{DNSName: "update-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "owner", endpoint.ResourceLabelKey: "ingress/default/old"}},
+	}
+	provider := &filteredMockProvider{RecordsStore: current}
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+
+	ctrl := &Controller{Registry: r}
+	updateNew := &endpoint.Endpoint{DNSName: "update-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.4.4"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "other", endpoint.ResourceLabelKey: "ingress/default/new"}}
+	require.NoError(t, ctrl.ApplyPlan(context.Background(), &plan.Output{Changes: &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{{DNSName: "update-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "owner"}}},
+		UpdateNew: []*endpoint.Endpoint{updateNew},
+	}}))
+
+	require.Len(t, provider.ApplyChangesCalls, 1)
+	require.Len(t, provider.ApplyChangesCalls[0].UpdateNew, 1)
+	applied := provider.ApplyChangesCalls[0].UpdateNew[0]
+	assert.Equal(t, "owner", applied.Labels[endpoint.OwnerLabelKey], "the plan cannot change the owner of the record")
+	assert.Equal(t, "ingress/default/new", applied.Labels[endpoint.ResourceLabelKey])
+	assert.Equal(t, endpoint.Targets{"8.8.4.4"}, applied.Targets)
+	assert.Equal(t, "other", updateNew.Labels[endpoint.OwnerLabelKey], "the plan is not modified")
+}
+
 func valueFromMetric(metric prometheus.Gauge) uint64 {
 	ref := reflect.ValueOf(metric)
 	return reflect.Indirect(ref).FieldByName("valBits").Uint()
@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// RecordCreated is the reason of a record created at the DNS provider
+	RecordCreated = "RecordCreated"
+	// RecordUpdated is the reason of a record updated at the DNS provider
+	RecordUpdated = "RecordUpdated"
+	// RecordDeleted is the reason of a record deleted at the DNS provider
+	RecordDeleted = "RecordDeleted"
+	// RecordConflicted is the reason of a desired record whose DNS name is claimed by another resource
+	RecordConflicted = "RecordConflicted"
+	// RecordFailed is the reason of a record the DNS provider failed to change
+	RecordFailed = "RecordFailed"
+)
+
+// RecordOutcome is the outcome of a single record of a synchronization
+type RecordOutcome struct {
+	// Endpoint is the record, for updates the desired version of it
+	Endpoint *endpoint.Endpoint
+	// Reason is one of RecordCreated, RecordUpdated, RecordDeleted, RecordConflicted or RecordFailed
+	Reason string
+	// Action is the change which failed, one of AuditActionCreate, AuditActionUpdate or AuditActionDelete
+	Action string
+	// Err is the error returned by the registry for failed records
+	Err error
+}
+
+// SyncResult is the outcome of a synchronization
+type SyncResult struct {
+	// Records at the DNS provider before the changes were applied
+	Current []*endpoint.Endpoint
+	// Records desired by the sources
+	Desired []*endpoint.Endpoint
+	// Outcomes of the changed and conflicted records
+	Outcomes []RecordOutcome
+}
+
+// Reporter reports the outcome of each synchronization back to the resources the records originate from
+type Reporter interface {
+	Report(ctx context.Context, result *SyncResult)
+}
+
+// report passes the outcome of the applied changes to the Reporter, applyErr is the error returned when applying the changes
+func (c *Controller) report(ctx context.Context, current, desired []*endpoint.Endpoint, changes *plan.Changes, conflicts []*endpoint.Endpoint, applyErr error) {
+	if c.Reporter == nil {
+		return
+	}
+	outcome := func(ep *endpoint.Endpoint, reason, action string) RecordOutcome {
+		if err := provider.RecordErrorFor(applyErr, ep); err != nil {
+			return RecordOutcome{Endpoint: ep, Reason: RecordFailed, Action: action, Err: err}
+		}
+		return RecordOutcome{Endpoint: ep, Reason: reason, Action: action}
+	}
+
+	result := &SyncResult{Current: current, Desired: desired}
+	for _, ep := range changes.Create {
+		result.Outcomes = append(result.Outcomes, outcome(ep, RecordCreated, AuditActionCreate))
+	}
+	for _, ep := range changes.UpdateNew {
+		result.Outcomes = append(result.Outcomes, outcome(ep, RecordUpdated, AuditActionUpdate))
+	}
+	for _, ep := range changes.Delete {
+		result.Outcomes = append(result.Outcomes, outcome(ep, RecordDeleted, AuditActionDelete))
+	}
+	for _, ep := range conflicts {
+		result.Outcomes = append(result.Outcomes, RecordOutcome{Endpoint: ep, Reason: RecordConflicted})
+	}
+	c.Reporter.Report(ctx, result)
+}
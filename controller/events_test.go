@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+type recordingReporter struct {
+	results []*SyncResult
+}
+
+func (r *recordingReporter) Report(ctx context.Context, result *SyncResult) {
+	r.results = append(r.results, result)
+}
+
+func TestRunOnceReport(t *testing.T) {
+	desired := []*endpoint.Endpoint{
+		{DNSName: "create-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "service/default/a"}},
+		{DNSName: "create-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"5.6.7.8"}, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "service/default/b"}},
+	}
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return(desired, nil)
+
+	provider := &mockProvider{
+		RecordsStore: []*endpoint.Endpoint{
+			{DNSName: "delete-record", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"4.3.2.1"}},
+		},
+		ExpectChanges: &plan.Changes{Create: desired[:1]},
+	}
+	provider.ExpectChanges.Delete = provider.RecordsStore
+
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+
+	reporter := &recordingReporter{}
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Reporter:           reporter,
+	}
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	require.Len(t, reporter.results, 1)
+
+	result := reporter.results[0]
+	assert.Equal(t, desired, result.Desired)
+	assert.Equal(t, provider.RecordsStore, result.Current)
+	assert.ElementsMatch(t, []RecordOutcome{
+		{Endpoint: desired[0], Reason: RecordCreated, Action: AuditActionCreate},
+		{Endpoint: provider.RecordsStore[0], Reason: RecordDeleted, Action: AuditActionDelete},
+		{Endpoint: desired[1], Reason: RecordConflicted},
+	}, result.Outcomes)
+}
@@ -0,0 +1,346 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// crdResourceKind is the kind the crd source uses in the resource label of its endpoints
+const crdResourceKind = "crd"
+
+// sourceResources maps the sources to the kind they use in the resource label of their endpoints and to the API resource
+// of that kind. The version of the API resource is discovered, the kinds are only known for the configured sources.
+var sourceResources = map[string]struct {
+	kind     string
+	resource schema.GroupResource
+}{
+	"service":              {"service", schema.GroupResource{Resource: "services"}},
+	"ingress":              {"ingress", schema.GroupResource{Group: "networking.k8s.io", Resource: "ingresses"}},
+	"contour-httpproxy":    {"HTTPProxy", schema.GroupResource{Group: "projectcontour.io", Resource: "httpproxies"}},
+	"istio-gateway":        {"gateway", schema.GroupResource{Group: "networking.istio.io", Resource: "gateways"}},
+	"istio-virtualservice": {"virtualservice", schema.GroupResource{Group: "networking.istio.io", Resource: "virtualservices"}},
+	"kong-tcpingress":      {"tcpingress", schema.GroupResource{Group: "configuration.konghq.com", Resource: "tcpingresses"}},
+	"openshift-route":      {"route", schema.GroupResource{Group: "route.openshift.io", Resource: "routes"}},
+	"skipper-routegroup":   {"routegroup", schema.GroupResource{Group: "zalando.org", Resource: "routegroups"}},
+	"gateway-httproute":    {"httproute", schema.GroupResource{Group: "gateway.networking.k8s.io", Resource: "httproutes"}},
+	"gateway-tcproute":     {"tcproute", schema.GroupResource{Group: "gateway.networking.k8s.io", Resource: "tcproutes"}},
+	"gateway-tlsroute":     {"tlsroute", schema.GroupResource{Group: "gateway.networking.k8s.io", Resource: "tlsroutes"}},
+	"gateway-udproute":     {"udproute", schema.GroupResource{Group: "gateway.networking.k8s.io", Resource: "udproutes"}},
+}
+
+// EventSourceComponent is the component Events are reported by
+const EventSourceComponent = "external-dns"
+
+// informerSyncTimeout is the maximum duration to wait for the informer of a resource kind reported to the first time
+const informerSyncTimeout = 60 * time.Second
+
+// KubernetesReporter emits Kubernetes Events on the resources the records originate from
+// and maintains the status of DNSEndpoints. Only changes are reported, e.g. a conflict persisting
+// over several synchronizations results in a single Event, and a status is only written if it changed.
+// The resources are looked up in informers, one per resource kind shared by all reports.
+type KubernetesReporter struct {
+	recorder    record.EventRecorder
+	client      dynamic.Interface
+	informers   dynamicinformer.DynamicSharedInformerFactory
+	stopCh      <-chan struct{}
+	mapper      meta.RESTMapper
+	kinds       map[string]schema.GroupResource
+	crdResource schema.GroupVersionResource
+	// reported holds the Events of the last report by resource and record
+	reported map[string]string
+	// statuses holds the statuses written by the last report by DNSEndpoint
+	statuses map[string]string
+}
+
+// NewKubernetesReporter creates a KubernetesReporter for the resources of the given sources, mapper discovers the versions
+// of their API resources. crdResource is the API resource of the DNSEndpoints of the crd source. The informers of the
+// resources are started on their first lookup and run until stopCh is closed.
+func NewKubernetesReporter(recorder record.EventRecorder, client dynamic.Interface, informers dynamicinformer.DynamicSharedInformerFactory, stopCh <-chan struct{}, mapper meta.RESTMapper, sources []string, crdResource schema.GroupVersionResource) *KubernetesReporter {
+	kinds := map[string]schema.GroupResource{}
+	for _, source := range sources {
+		if r, ok := sourceResources[source]; ok {
+			kinds[r.kind] = r.resource
+		}
+	}
+	return &KubernetesReporter{
+		recorder:    recorder,
+		client:      client,
+		informers:   informers,
+		stopCh:      stopCh,
+		mapper:      mapper,
+		kinds:       kinds,
+		crdResource: crdResource,
+		reported:    map[string]string{},
+		statuses:    map[string]string{},
+	}
+}
+
+// Report emits an Event for each new outcome on the resources of the record and updates the changed status of the desired DNSEndpoints
+func (r *KubernetesReporter) Report(ctx context.Context, result *SyncResult) {
+	reported := map[string]string{}
+	for _, outcome := range result.Outcomes {
+		message := outcomeMessage(outcome)
+		for _, resource := range resourcesOf(outcome.Endpoint) {
+			key := resource + "::" + recordKey(outcome.Endpoint)
+			reported[key] = outcome.Reason + ": " + message
+			if r.reported[key] == reported[key] {
+				continue
+			}
+			obj := r.lookup(ctx, resource)
+			if obj == nil {
+				continue
+			}
+			eventType := corev1.EventTypeNormal
+			if outcome.Reason == RecordConflicted || outcome.Reason == RecordFailed {
+				eventType = corev1.EventTypeWarning
+			}
+			r.recorder.Event(obj, eventType, outcome.Reason, message)
+		}
+	}
+	r.reported = reported
+
+	desired := map[string][]*endpoint.Endpoint{}
+	for _, ep := range result.Desired {
+		resource := ep.Labels[endpoint.ResourceLabelKey]
+		if strings.HasPrefix(resource, crdResourceKind+"/") {
+			desired[resource] = append(desired[resource], ep)
+		}
+	}
+	statuses := map[string]string{}
+	for resource, endpoints := range desired {
+		endpointStatuses := make([]endpoint.EndpointStatus, 0, len(endpoints))
+		for _, ep := range endpoints {
+			endpointStatuses = append(endpointStatuses, endpointStatus(ep, resource, result))
+		}
+		// the endpoints are part of the fingerprint, the observed generation changes along with them
+		fingerprint, err := json.Marshal([]interface{}{endpoints, endpointStatuses})
+		if err != nil {
+			log.Warnf("Could not update status of %s: %v", resource, err)
+			continue
+		}
+		if r.statuses[resource] == string(fingerprint) {
+			statuses[resource] = string(fingerprint)
+			continue
+		}
+		if obj := r.lookup(ctx, resource); obj != nil {
+			if err := r.updateStatus(ctx, obj, endpointStatuses); err != nil {
+				log.Warnf("Could not update status of %s: %v", resource, err)
+				continue
+			}
+			statuses[resource] = string(fingerprint)
+		}
+	}
+	r.statuses = statuses
+}
+
+// lookup returns the object of the resource label value from the informer of its kind
+func (r *KubernetesReporter) lookup(ctx context.Context, resource string) *unstructured.Unstructured {
+	parts := strings.SplitN(resource, "/", 3)
+	if len(parts) != 3 {
+		return nil
+	}
+	gvr, err := r.resourceOf(parts[0])
+	if err != nil {
+		log.Debugf("Not reporting to %s: %v", resource, err)
+		return nil
+	}
+	lister, err := r.listerFor(ctx, gvr)
+	if err != nil {
+		log.Warnf("Not reporting to %s: %v", resource, err)
+		return nil
+	}
+	obj, err := lister.ByNamespace(parts[1]).Get(parts[2])
+	if err != nil {
+		// the resource of deleted records is usually gone as well
+		log.Debugf("Not reporting to %s: %v", resource, err)
+		return nil
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	return u
+}
+
+// listerFor returns the lister of the API resource, its informer is started and synced on the first lookup
+func (r *KubernetesReporter) listerFor(ctx context.Context, gvr schema.GroupVersionResource) (cache.GenericLister, error) {
+	informer := r.informers.ForResource(gvr)
+	if !informer.Informer().HasSynced() {
+		r.informers.Start(r.stopCh)
+		ctx, cancel := context.WithTimeout(ctx, informerSyncTimeout)
+		defer cancel()
+		if !cache.WaitForCacheSync(ctx.Done(), informer.Informer().HasSynced) {
+			return nil, fmt.Errorf("failed to sync the informer of %v", gvr)
+		}
+	}
+	return informer.Lister(), nil
+}
+
+// resourceOf returns the API resource of the kind used in resource labels, its preferred version is discovered
+func (r *KubernetesReporter) resourceOf(kind string) (schema.GroupVersionResource, error) {
+	if kind == crdResourceKind {
+		return r.crdResource, nil
+	}
+	resource, ok := r.kinds[kind]
+	if !ok {
+		return schema.GroupVersionResource{}, fmt.Errorf("unknown resource kind %s", kind)
+	}
+	return r.mapper.ResourceFor(resource.WithVersion(""))
+}
+
+func (r *KubernetesReporter) updateStatus(ctx context.Context, obj *unstructured.Unstructured, endpointStatuses []endpoint.EndpointStatus) error {
+	dnsEndpoint := &endpoint.DNSEndpoint{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), dnsEndpoint); err != nil {
+		return err
+	}
+	status := dnsEndpoint.Status.DeepCopy()
+
+	status.Endpoints = endpointStatuses
+	published := 0
+	for _, epStatus := range endpointStatuses {
+		if epStatus.State == endpoint.EndpointStatePublished {
+			published++
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               endpoint.DNSEndpointConditionPublished,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: dnsEndpoint.Generation,
+		Reason:             "Published",
+		Message:            fmt.Sprintf("%d of %d endpoints are published", published, len(endpointStatuses)),
+	}
+	if published < len(endpointStatuses) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "NotPublished"
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+
+	if apiequality.Semantic.DeepEqual(status, &dnsEndpoint.Status) {
+		return nil
+	}
+	dnsEndpoint.Status = *status
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(dnsEndpoint)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.Resource(r.crdResource).Namespace(dnsEndpoint.Namespace).UpdateStatus(ctx, &unstructured.Unstructured{Object: content}, metav1.UpdateOptions{})
+	return err
+}
+
+// endpointStatus determines the published state of a desired endpoint of the resource
+func endpointStatus(ep *endpoint.Endpoint, resource string, result *SyncResult) endpoint.EndpointStatus {
+	status := endpoint.EndpointStatus{
+		DNSName:       ep.DNSName,
+		RecordType:    ep.RecordType,
+		SetIdentifier: ep.SetIdentifier,
+		State:         endpoint.EndpointStateSkipped,
+	}
+
+	for _, outcome := range result.Outcomes {
+		if !sameRecord(outcome.Endpoint, ep) || !hasResource(outcome.Endpoint, resource) {
+			continue
+		}
+		switch outcome.Reason {
+		case RecordFailed:
+			status.State = endpoint.EndpointStateFailed
+			status.Message = outcomeMessage(outcome)
+			return status
+		case RecordConflicted:
+			status.State = endpoint.EndpointStateConflicted
+			status.Message = "the DNS name is claimed by another resource"
+			return status
+		case RecordCreated, RecordUpdated:
+			status.State = endpoint.EndpointStatePublished
+			return status
+		}
+	}
+
+	for _, current := range result.Current {
+		if sameRecord(current, ep) && (current.Targets.Same(ep.Targets) || hasResource(current, resource)) {
+			status.State = endpoint.EndpointStatePublished
+			return status
+		}
+	}
+	status.Message = "the record is not managed, e.g. because of the policy or the domain filter"
+	return status
+}
+
+func outcomeMessage(outcome RecordOutcome) string {
+	ep := outcome.Endpoint
+	switch outcome.Reason {
+	case RecordCreated:
+		return fmt.Sprintf("Created %s record %s with targets %s", ep.RecordType, ep.DNSName, ep.Targets)
+	case RecordUpdated:
+		return fmt.Sprintf("Updated %s record %s to targets %s", ep.RecordType, ep.DNSName, ep.Targets)
+	case RecordDeleted:
+		return fmt.Sprintf("Deleted %s record %s", ep.RecordType, ep.DNSName)
+	case RecordConflicted:
+		return fmt.Sprintf("%s record %s is claimed by another resource", ep.RecordType, ep.DNSName)
+	default:
+		return fmt.Sprintf("Failed to %s %s record %s: %v", outcome.Action, ep.RecordType, ep.DNSName, outcome.Err)
+	}
+}
+
+// resourcesOf returns the resources the record originates from, merged records originate from several ones
+func resourcesOf(ep *endpoint.Endpoint) []string {
+	label := ep.Labels[endpoint.ResourceLabelKey]
+	if label == "" {
+		return nil
+	}
+	return strings.Split(label, endpoint.ResourceLabelSeparator)
+}
+
+func hasResource(ep *endpoint.Endpoint, resource string) bool {
+	for _, r := range resourcesOf(ep) {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// recordKey returns the key of the record by its name, type and set identifier
+func recordKey(ep *endpoint.Endpoint) string {
+	return strings.ToLower(strings.TrimSuffix(ep.DNSName, ".")) + "/" + ep.RecordType + "/" + ep.SetIdentifier
+}
+
+func sameRecord(a, b *endpoint.Endpoint) bool {
+	return strings.EqualFold(strings.TrimSuffix(a.DNSName, "."), strings.TrimSuffix(b.DNSName, ".")) &&
+		a.RecordType == b.RecordType && a.SetIdentifier == b.SetIdentifier
+}
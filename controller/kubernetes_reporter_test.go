@@ -0,0 +1,197 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	fakeDynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+var (
+	dnsEndpointResource = schema.GroupVersionResource{Group: "externaldns.k8s.io", Version: "v1alpha1", Resource: "dnsendpoints"}
+	serviceResource     = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+)
+
+// newReporterRESTMapper returns a RESTMapper knowing Services in version v1 and Istio Gateways in version v1beta1
+func newReporterRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{{Version: "v1"}, {Group: "networking.istio.io", Version: "v1beta1"}})
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Service"}, meta.RESTScopeNamespace)
+	gateways := schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"}
+	mapper.AddSpecific(gateways.GroupVersion().WithKind("Gateway"), gateways, gateways.GroupVersion().WithResource("gateway"), meta.RESTScopeNamespace)
+	return mapper
+}
+
+func newReporterObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetGeneration(2)
+	return obj
+}
+
+func newReporterEndpoint(name, resource string) *endpoint.Endpoint {
+	return &endpoint.Endpoint{
+		DNSName:    name,
+		RecordType: endpoint.RecordTypeA,
+		Targets:    endpoint.Targets{"1.2.3.4"},
+		Labels:     endpoint.Labels{endpoint.ResourceLabelKey: resource},
+	}
+}
+
+func TestKubernetesReporter(t *testing.T) {
+	recorder := record.NewFakeRecorder(100)
+	recorder.IncludeObject = true
+	dynamicClient := fakeDynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		dnsEndpointResource: "DNSEndpointList",
+		serviceResource:     "ServiceList",
+	},
+		newReporterObject("v1", "Service", "default", "web"),
+		newReporterObject("externaldns.k8s.io/v1alpha1", "DNSEndpoint", "default", "records"),
+	)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informers := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0)
+	reporter := NewKubernetesReporter(recorder, dynamicClient, informers, stopCh, newReporterRESTMapper(), []string{"service", "crd"}, dnsEndpointResource)
+
+	web := newReporterEndpoint("web.example.org", "service/default/web")
+	created := newReporterEndpoint("created.example.org", "crd/default/records")
+	unchanged := newReporterEndpoint("unchanged.example.org", "crd/default/records")
+	conflicted := newReporterEndpoint("conflicted.example.org", "crd/default/records")
+	failed := newReporterEndpoint("failed.example.org", "crd/default/records")
+	skipped := newReporterEndpoint("skipped.example.org", "crd/default/records")
+	gone := newReporterEndpoint("gone.example.org", "service/default/gone")
+
+	result := &SyncResult{
+		Current: []*endpoint.Endpoint{
+			{DNSName: "unchanged.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+			gone,
+		},
+		Desired: []*endpoint.Endpoint{web, created, unchanged, conflicted, failed, skipped},
+		Outcomes: []RecordOutcome{
+			{Endpoint: web, Reason: RecordCreated, Action: AuditActionCreate},
+			{Endpoint: created, Reason: RecordCreated, Action: AuditActionCreate},
+			{Endpoint: conflicted, Reason: RecordConflicted},
+			{Endpoint: failed, Reason: RecordFailed, Action: AuditActionUpdate, Err: errors.New("throttled")},
+			{Endpoint: gone, Reason: RecordDeleted, Action: AuditActionDelete},
+		},
+	}
+	reporter.Report(context.Background(), result)
+
+	// the fake recorder hands out every Event once, hence they are collected
+	events := []string{}
+	eventMessages := func() []string {
+		for {
+			select {
+			case event := <-recorder.Events:
+				events = append(events, event)
+			default:
+				sort.Strings(events)
+				return events
+			}
+		}
+	}
+	dnsEndpointObject := " involvedObject{kind=DNSEndpoint,apiVersion=externaldns.k8s.io/v1alpha1}"
+	expected := []string{
+		corev1.EventTypeNormal + " RecordCreated Created A record created.example.org with targets 1.2.3.4" + dnsEndpointObject,
+		corev1.EventTypeNormal + " RecordCreated Created A record web.example.org with targets 1.2.3.4 involvedObject{kind=Service,apiVersion=v1}",
+		corev1.EventTypeWarning + " RecordConflicted A record conflicted.example.org is claimed by another resource" + dnsEndpointObject,
+		corev1.EventTypeWarning + " RecordFailed Failed to update A record failed.example.org: throttled" + dnsEndpointObject,
+	}
+	assert.Equal(t, expected, eventMessages())
+
+	obj, err := dynamicClient.Resource(dnsEndpointResource).Namespace("default").Get(context.Background(), "records", metav1.GetOptions{})
+	require.NoError(t, err)
+	dnsEndpoint := &endpoint.DNSEndpoint{}
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), dnsEndpoint))
+
+	states := map[string]string{}
+	for _, status := range dnsEndpoint.Status.Endpoints {
+		states[status.DNSName] = status.State
+	}
+	assert.Equal(t, map[string]string{
+		"created.example.org":    endpoint.EndpointStatePublished,
+		"unchanged.example.org":  endpoint.EndpointStatePublished,
+		"conflicted.example.org": endpoint.EndpointStateConflicted,
+		"failed.example.org":     endpoint.EndpointStateFailed,
+		"skipped.example.org":    endpoint.EndpointStateSkipped,
+	}, states)
+
+	require.Len(t, dnsEndpoint.Status.Conditions, 1)
+	condition := dnsEndpoint.Status.Conditions[0]
+	assert.Equal(t, endpoint.DNSEndpointConditionPublished, condition.Type)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "2 of 5 endpoints are published", condition.Message)
+	assert.Equal(t, int64(2), condition.ObservedGeneration)
+
+	// a persisting conflict and failure are reported once, the unchanged status is not written
+	dynamicClient.ClearActions()
+	reporter.Report(context.Background(), &SyncResult{
+		Current: append(result.Current, created),
+		Desired: result.Desired,
+		Outcomes: []RecordOutcome{
+			{Endpoint: conflicted, Reason: RecordConflicted},
+			{Endpoint: failed, Reason: RecordFailed, Action: AuditActionUpdate, Err: errors.New("throttled")},
+		},
+	})
+	assert.Equal(t, expected, eventMessages())
+	for _, action := range dynamicClient.Actions() {
+		// the informers may still start watching, the resources are not read otherwise
+		assert.Equal(t, "watch", action.GetVerb())
+	}
+
+	// the conflict is reported again once it reappears
+	reporter.Report(context.Background(), &SyncResult{Current: result.Current, Desired: result.Desired})
+	reporter.Report(context.Background(), &SyncResult{
+		Current:  result.Current,
+		Desired:  result.Desired,
+		Outcomes: []RecordOutcome{{Endpoint: conflicted, Reason: RecordConflicted}},
+	})
+	assert.Len(t, eventMessages(), len(expected)+1)
+}
+
+func TestKubernetesReporterResources(t *testing.T) {
+	reporter := NewKubernetesReporter(record.NewFakeRecorder(1), nil, nil, nil, newReporterRESTMapper(), []string{"istio-gateway", "crd"}, dnsEndpointResource)
+
+	gvr, err := reporter.resourceOf("gateway")
+	require.NoError(t, err)
+	assert.Equal(t, schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"}, gvr, "the version is discovered")
+
+	gvr, err = reporter.resourceOf(crdResourceKind)
+	require.NoError(t, err)
+	assert.Equal(t, dnsEndpointResource, gvr)
+
+	_, err = reporter.resourceOf("service")
+	assert.Error(t, err, "the kinds of other sources are unknown")
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// LeaderElectionConfig configures the Lease based leader election of RunWithLeaderElection
+type LeaderElectionConfig struct {
+	// Client is used to acquire and renew the Lease
+	Client kubernetes.Interface
+	// Namespace and Name of the Lease
+	Namespace string
+	Name      string
+	// Identity of this instance, it must be unique among all instances competing for the Lease
+	Identity string
+	// LeaseDuration is the duration standby instances wait before they take over a Lease which is not renewed
+	LeaseDuration time.Duration
+	// RenewDeadline is the duration the leader retries to renew the Lease before it gives up leadership
+	RenewDeadline time.Duration
+	// RetryPeriod is the duration between two attempts to acquire or renew the Lease
+	RetryPeriod time.Duration
+}
+
+// RunWithLeaderElection runs Run as long as this instance holds the Lease until the context is canceled.
+// Standby instances keep competing for the Lease, their sources and informers stay up to date in the meantime
+// so that they are ready to synchronize as soon as they take over. The Lease is only released once Run has
+// returned, so that the next leader never synchronizes concurrently. Losing the Lease is returned as an error
+// after Run has returned, the instance is expected to exit and compete again once it is restarted.
+func (c *Controller) RunWithLeaderElection(ctx context.Context, cfg LeaderElectionConfig) error {
+	// the election outlives ctx so that the Lease is renewed until the synchronization in progress has finished
+	electionCtx, stopElection := context.WithCancel(context.Background())
+	defer stopElection()
+
+	// receives the context of the leadership, which is canceled once the Lease is lost
+	leading := make(chan context.Context, 1)
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta: metav1.ObjectMeta{
+				Namespace: cfg.Namespace,
+				Name:      cfg.Name,
+			},
+			Client: cfg.Client.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{
+				Identity: cfg.Identity,
+			},
+		},
+		LeaseDuration:   cfg.LeaseDuration,
+		RenewDeadline:   cfg.RenewDeadline,
+		RetryPeriod:     cfg.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            cfg.Name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				leading <- leaderCtx
+			},
+			OnStoppedLeading: func() {
+				leader.Set(0)
+			},
+			OnNewLeader: func(identity string) {
+				if identity != cfg.Identity {
+					log.Infof("Waiting for leader election lease %s/%s held by %s", cfg.Namespace, cfg.Name, identity)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		elector.Run(electionCtx)
+	}()
+
+	var leaderCtx context.Context
+	select {
+	case <-ctx.Done():
+		stopElection()
+		<-stopped
+		return nil
+	case leaderCtx = <-leading:
+	}
+
+	log.Infof("Acquired leader election lease %s/%s as %s", cfg.Namespace, cfg.Name, cfg.Identity)
+	leader.Set(1)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-leaderCtx.Done():
+			cancel()
+		case <-runCtx.Done():
+		}
+	}()
+	c.ScheduleRunOnce(time.Now())
+	c.Run(runCtx)
+
+	lost := leaderCtx.Err() != nil && ctx.Err() == nil
+	stopElection()
+	<-stopped
+	if lost {
+		return fmt.Errorf("lost leader election lease %s/%s", cfg.Namespace, cfg.Name)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+// newLeaderElectionController returns a controller and a channel receiving a value for each synchronization
+func newLeaderElectionController(t *testing.T) (*Controller, chan struct{}) {
+	synced := make(chan struct{}, 10)
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{}, nil).Run(func(mock.Arguments) { synced <- struct{}{} })
+
+	r, err := registry.NewNoopRegistry(&filteredMockProvider{})
+	require.NoError(t, err)
+	return &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Interval:           time.Hour,
+	}, synced
+}
+
+func newLeaderElectionConfig(client *fake.Clientset, identity string) LeaderElectionConfig {
+	return LeaderElectionConfig{
+		Client:        client,
+		Namespace:     "default",
+		Name:          "external-dns",
+		Identity:      identity,
+		LeaseDuration: 3 * time.Second,
+		RenewDeadline: 2 * time.Second,
+		RetryPeriod:   100 * time.Millisecond,
+	}
+}
+
+func TestRunWithLeaderElection(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	ctrl, synced := newLeaderElectionController(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- ctrl.RunWithLeaderElection(ctx, newLeaderElectionConfig(client, "leader")) }()
+
+	select {
+	case <-synced:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the leader should synchronize")
+	}
+	lease, err := client.CoordinationV1().Leases("default").Get(context.Background(), "external-dns", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "leader", *lease.Spec.HolderIdentity)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	// the lease is released on shutdown so that a standby instance takes over immediately
+	lease, err = client.CoordinationV1().Leases("default").Get(context.Background(), "external-dns", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, *lease.Spec.HolderIdentity)
+}
+
+func TestRunWithLeaderElectionStandby(t *testing.T) {
+	holder := "other"
+	duration := int32(60)
+	now := metav1.NewMicroTime(time.Now())
+	client := fake.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "external-dns"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &duration,
+			AcquireTime:          &now,
+			RenewTime:            &now,
+		},
+	})
+	ctrl, synced := newLeaderElectionController(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, ctrl.RunWithLeaderElection(ctx, newLeaderElectionConfig(client, "standby")))
+
+	assert.Empty(t, synced, "a standby instance must not synchronize")
+	lease, err := client.CoordinationV1().Leases("default").Get(context.Background(), "external-dns", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "other", *lease.Spec.HolderIdentity)
+}
+
+func TestRunWithLeaderElectionLost(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	ctrl, synced := newLeaderElectionController(t)
+
+	done := make(chan error)
+	go func() {
+		done <- ctrl.RunWithLeaderElection(context.Background(), newLeaderElectionConfig(client, "leader"))
+	}()
+
+	select {
+	case <-synced:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the leader should synchronize")
+	}
+
+	// the Lease can no longer be renewed, e.g. because the API server is not reachable
+	client.PrependReactor("update", "leases", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	select {
+	case err := <-done:
+		assert.EqualError(t, err, "lost leader election lease default/external-dns")
+	case <-time.After(10 * time.Second):
+		t.Fatal("losing the lease should stop the controller")
+	}
+	assert.Empty(t, synced, "the controller must not synchronize after losing the lease")
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"net/http"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+// ownershipSnapshot holds the records and orphaned ownership records read from the registry
+type ownershipSnapshot struct {
+	records []*endpoint.Endpoint
+	orphans []*endpoint.Endpoint
+}
+
+func (c *Controller) setOwnership(records, orphans []*endpoint.Endpoint) {
+	c.ownershipMux.Lock()
+	defer c.ownershipMux.Unlock()
+	// the registry may reuse the slices, e.g. its records cache, hence the snapshot holds copies
+	c.ownership = &ownershipSnapshot{
+		records: append([]*endpoint.Endpoint(nil), records...),
+		orphans: append([]*endpoint.Endpoint(nil), orphans...),
+	}
+}
+
+// OwnershipReportHandler serves the ownership of the records read by the last synchronization.
+// The format is selected by the format query parameter, one of registry.OwnershipReportFormats (default: json).
+// The registry is not read by the handler itself, it responds with 503 until the first synchronization read the records.
+func (c *Controller) OwnershipReportHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c.ownershipMux.Lock()
+		snapshot := c.ownership
+		c.ownershipMux.Unlock()
+		if snapshot == nil {
+			http.Error(w, "no synchronization has read the records yet", http.StatusServiceUnavailable)
+			return
+		}
+
+		format := req.URL.Query().Get("format")
+		if format == "" {
+			format = "json"
+		}
+		var buf bytes.Buffer
+		if err := registry.WriteOwnershipReport(&buf, registry.NewOwnershipReport(snapshot.records, snapshot.orphans), format); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if format == "json" {
+			w.Header().Set("Content-Type", "application/json")
+		} else {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+		w.Write(buf.Bytes())
+	})
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+func TestOwnershipReportHandler(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone("example.org"))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("owned.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("owned.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=owner,external-dns/resource=ingress/default/owned\""),
+			endpoint.NewEndpoint("unowned.example.org", endpoint.RecordTypeA, "1.2.3.5"),
+			endpoint.NewEndpoint("gone.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=other\""),
+		},
+	}))
+	r, err := registry.NewTXTRegistry(p, "", "", "owner", 0, "", []string{endpoint.RecordTypeA})
+	require.NoError(t, err)
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{}, nil)
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.UpsertOnlyPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+	}
+	handler := ctrl.OwnershipReportHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ownership", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "no report before the first synchronization")
+
+	require.NoError(t, ctrl.RunOnce(ctx))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ownership", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var entries []registry.OwnershipReportEntry
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &entries))
+	assert.Equal(t, []registry.OwnershipReportEntry{
+		{DNSName: "gone.example.org", RecordType: endpoint.RecordTypeTXT, Targets: []string{"\"heritage=external-dns,external-dns/owner=other\""}, Owner: "other", Status: registry.OwnershipOrphaned},
+		{DNSName: "owned.example.org", RecordType: endpoint.RecordTypeA, Targets: []string{"1.2.3.4"}, Owner: "owner", Resource: "ingress/default/owned", Status: registry.OwnershipOwned},
+		{DNSName: "unowned.example.org", RecordType: endpoint.RecordTypeA, Targets: []string{"1.2.3.5"}, Status: registry.OwnershipUnowned},
+	}, entries)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ownership?format=table", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "owned.example.org")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ownership?format=xml", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// quarantine keeps track of records the provider failed to change. Changes of a failing
+// record are not retried before its backoff expired, the backoff doubles with each failure.
+type quarantine struct {
+	mu         sync.Mutex
+	entries    map[string]*quarantineEntry
+	backoff    time.Duration
+	maxBackoff time.Duration
+	now        func() time.Time
+}
+
+type quarantineEntry struct {
+	failures int
+	until    time.Time
+	err      error
+}
+
+func newQuarantine(backoff, maxBackoff time.Duration) *quarantine {
+	return &quarantine{
+		entries:    map[string]*quarantineEntry{},
+		backoff:    backoff,
+		maxBackoff: maxBackoff,
+		now:        time.Now,
+	}
+}
+
+// filter returns the changes without the ones of records whose backoff did not expire yet.
+// Records which are not part of the changes anymore are released from the quarantine.
+func (q *quarantine) filter(changes *plan.Changes) *plan.Changes {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	planned := map[string]bool{}
+	skip := func(ep *endpoint.Endpoint) bool {
+		key := quarantineKey(ep)
+		planned[key] = true
+		entry, ok := q.entries[key]
+		if !ok || !q.now().Before(entry.until) {
+			return false
+		}
+		log.Warnf("Skipping change of quarantined %s record %s until %s, it failed %d time(s): %v", ep.RecordType, ep.DNSName, entry.until.Format(time.RFC3339), entry.failures, entry.err)
+		return true
+	}
+
+	filtered := &plan.Changes{}
+	for _, ep := range changes.Create {
+		if !skip(ep) {
+			filtered.Create = append(filtered.Create, ep)
+		}
+	}
+	for i, ep := range changes.UpdateNew {
+		if !skip(ep) {
+			filtered.UpdateOld = append(filtered.UpdateOld, changes.UpdateOld[i])
+			filtered.UpdateNew = append(filtered.UpdateNew, ep)
+		}
+	}
+	for _, ep := range changes.Delete {
+		if !skip(ep) {
+			filtered.Delete = append(filtered.Delete, ep)
+		}
+	}
+
+	for key := range q.entries {
+		if !planned[key] {
+			delete(q.entries, key)
+		}
+	}
+	return filtered
+}
+
+// update quarantines the records which failed according to err and releases the ones which were applied.
+// It returns false if err is not a provider.ChangeErrors, i.e. if it is unknown which records failed.
+func (q *quarantine) update(changes *plan.Changes, err error) bool {
+	var changeErrors provider.ChangeErrors
+	if err != nil && !errors.As(err, &changeErrors) {
+		return false
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, eps := range [][]*endpoint.Endpoint{changes.Create, changes.UpdateNew, changes.Delete} {
+		for _, ep := range eps {
+			key := quarantineKey(ep)
+			recordErr := provider.RecordErrorFor(err, ep)
+			if recordErr == nil {
+				delete(q.entries, key)
+				continue
+			}
+			entry, ok := q.entries[key]
+			if !ok {
+				entry = &quarantineEntry{}
+				q.entries[key] = entry
+			}
+			entry.failures++
+			entry.err = recordErr
+			entry.until = q.now().Add(q.backoffFor(entry.failures))
+			log.Errorf("Quarantining %s record %s until %s: %v", ep.RecordType, ep.DNSName, entry.until.Format(time.RFC3339), recordErr)
+		}
+	}
+	return true
+}
+
+// backoffFor returns the exponential backoff after the given number of failures
+func (q *quarantine) backoffFor(failures int) time.Duration {
+	backoff := q.backoff
+	for i := 1; i < failures; i++ {
+		backoff *= 2
+		if q.maxBackoff > 0 && backoff >= q.maxBackoff {
+			return q.maxBackoff
+		}
+	}
+	if q.maxBackoff > 0 && backoff > q.maxBackoff {
+		return q.maxBackoff
+	}
+	return backoff
+}
+
+// size returns the number of quarantined records
+func (q *quarantine) size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries)
+}
+
+func quarantineKey(ep *endpoint.Endpoint) string {
+	return strings.ToLower(strings.TrimSuffix(ep.DNSName, ".")) + "/" + ep.RecordType + "/" + ep.SetIdentifier
+}
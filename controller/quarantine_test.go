@@ -0,0 +1,155 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+// partiallyFailingProvider fails to apply the changes of the records in failing
+type partiallyFailingProvider struct {
+	provider.BaseProvider
+	failing           map[string]bool
+	ApplyChangesCalls []*plan.Changes
+}
+
+func (p *partiallyFailingProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	return nil, nil
+}
+
+func (p *partiallyFailingProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	p.ApplyChangesCalls = append(p.ApplyChangesCalls, changes)
+	var changeErrors provider.ChangeErrors
+	for _, ep := range changes.Create {
+		if p.failing[ep.DNSName] {
+			changeErrors = append(changeErrors, &provider.RecordError{Endpoint: ep, Err: errors.New("throttled")})
+		}
+	}
+	if len(changeErrors) > 0 {
+		return changeErrors
+	}
+	return nil
+}
+
+func newQuarantineEndpoint(name string) *endpoint.Endpoint {
+	return &endpoint.Endpoint{DNSName: name, RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}}
+}
+
+func TestQuarantineBackoff(t *testing.T) {
+	q := newQuarantine(time.Minute, 5*time.Minute)
+	for failures, expected := range map[int]time.Duration{
+		1: time.Minute,
+		2: 2 * time.Minute,
+		3: 4 * time.Minute,
+		4: 5 * time.Minute,
+		9: 5 * time.Minute,
+	} {
+		assert.Equal(t, expected, q.backoffFor(failures), "failures: %d", failures)
+	}
+}
+
+func TestQuarantine(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	q := newQuarantine(time.Minute, time.Hour)
+	q.now = func() time.Time { return now }
+
+	failed := newQuarantineEndpoint("failed.example.org")
+	applied := newQuarantineEndpoint("applied.example.org")
+	changes := &plan.Changes{Create: []*endpoint.Endpoint{failed, applied}}
+
+	assert.False(t, q.update(changes, errors.New("timeout")), "plain errors must not be quarantined")
+	assert.Equal(t, 0, q.size())
+
+	require.True(t, q.update(changes, provider.ChangeErrors{{Endpoint: failed, Err: errors.New("throttled")}}))
+	assert.Equal(t, 1, q.size())
+	assert.Equal(t, &plan.Changes{Create: []*endpoint.Endpoint{applied}}, q.filter(changes))
+
+	// the backoff expired, the record is retried and fails again
+	now = now.Add(time.Minute)
+	assert.Equal(t, changes, q.filter(changes))
+	require.True(t, q.update(changes, provider.ChangeErrors{{Endpoint: failed, Err: errors.New("throttled")}}))
+	assert.Equal(t, now.Add(2*time.Minute), q.entries[quarantineKey(failed)].until)
+
+	// records which are not planned anymore are released
+	q.filter(&plan.Changes{Create: []*endpoint.Endpoint{applied}})
+	assert.Equal(t, 0, q.size())
+
+	// records which were applied are released
+	require.True(t, q.update(changes, provider.ChangeErrors{{Endpoint: failed, Err: errors.New("throttled")}}))
+	now = now.Add(time.Minute)
+	require.True(t, q.update(changes, nil))
+	assert.Equal(t, 0, q.size())
+}
+
+func TestQuarantineFilterUpdates(t *testing.T) {
+	q := newQuarantine(time.Minute, time.Hour)
+	failedOld, failedNew := newQuarantineEndpoint("failed.example.org"), newQuarantineEndpoint("failed.example.org")
+	appliedOld, appliedNew := newQuarantineEndpoint("applied.example.org"), newQuarantineEndpoint("applied.example.org")
+	changes := &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{failedOld, appliedOld},
+		UpdateNew: []*endpoint.Endpoint{failedNew, appliedNew},
+	}
+
+	require.True(t, q.update(changes, provider.ChangeErrors{{Endpoint: failedNew, Err: errors.New("throttled")}}))
+	assert.Equal(t, &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{appliedOld},
+		UpdateNew: []*endpoint.Endpoint{appliedNew},
+	}, q.filter(changes))
+}
+
+func TestRunOnceQuarantine(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		newQuarantineEndpoint("failed.example.org"),
+		newQuarantineEndpoint("applied.example.org"),
+	}, nil)
+
+	p := &partiallyFailingProvider{failing: map[string]bool{"failed.example.org": true}}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+	}
+
+	// without quarantine the failure aborts the synchronization
+	assert.Error(t, ctrl.RunOnce(context.Background()))
+
+	ctrl.QuarantineBackoff = time.Hour
+	assert.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 1, ctrl.quarantine.size())
+
+	// the failed record is skipped by the next synchronization
+	assert.NoError(t, ctrl.RunOnce(context.Background()))
+	require.Len(t, p.ApplyChangesCalls, 3)
+	assert.Equal(t, []*endpoint.Endpoint{newQuarantineEndpoint("applied.example.org")}, p.ApplyChangesCalls[2].Create)
+}
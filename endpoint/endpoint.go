@@ -30,6 +30,8 @@ import (
 const (
 	// RecordTypeA is a RecordType enum value
 	RecordTypeA = "A"
+	// RecordTypeAAAA is a RecordType enum value
+	RecordTypeAAAA = "AAAA"
 	// RecordTypeCNAME is a RecordType enum value
 	RecordTypeCNAME = "CNAME"
 	// RecordTypeTXT is a RecordType enum value
@@ -40,6 +42,10 @@ const (
 	RecordTypeNS = "NS"
 	// RecordTypePTR is a RecordType enum value
 	RecordTypePTR = "PTR"
+	// RecordTypeMX is a RecordType enum value
+	RecordTypeMX = "MX"
+	// RecordTypeCAA is a RecordType enum value
+	RecordTypeCAA = "CAA"
 )
 
 // TTL is a structure defining the TTL of a DNS record
@@ -250,6 +256,43 @@ type DNSEndpointStatus struct {
 	// The generation observed by the external-dns controller.
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the DNSEndpoint, the Published condition is true once all endpoints are published.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// The published state of each endpoint.
+	// +optional
+	Endpoints []EndpointStatus `json:"endpoints,omitempty"`
+}
+
+const (
+	// DNSEndpointConditionPublished is the condition type telling whether all endpoints of a DNSEndpoint are published
+	DNSEndpointConditionPublished = "Published"
+
+	// EndpointStatePublished is the state of an endpoint whose record exists at the DNS provider
+	EndpointStatePublished = "Published"
+	// EndpointStateConflicted is the state of an endpoint whose DNS name is claimed by another resource
+	EndpointStateConflicted = "Conflicted"
+	// EndpointStateFailed is the state of an endpoint whose record the DNS provider failed to change
+	EndpointStateFailed = "Failed"
+	// EndpointStateSkipped is the state of an endpoint which is not published, e.g. because of a policy or a domain filter
+	EndpointStateSkipped = "Skipped"
+)
+
+// EndpointStatus is the published state of an endpoint of a DNSEndpoint
+type EndpointStatus struct {
+	// The hostname of the DNS record
+	DNSName string `json:"dnsName"`
+	// RecordType type of record, e.g. CNAME, A, SRV, TXT etc
+	// +optional
+	RecordType string `json:"recordType,omitempty"`
+	// Identifier to distinguish multiple records with the same name and type (e.g. Route53 records with routing policies other than 'simple')
+	// +optional
+	SetIdentifier string `json:"setIdentifier,omitempty"`
+	// State is one of Published, Conflicted, Failed or Skipped
+	State string `json:"state"`
+	// Message explains why the endpoint is not published
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +genclient
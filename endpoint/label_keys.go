@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnprotected is returned when the labels are expected to be encrypted or signed but are plain
+	ErrUnprotected = errors.New("labels are neither encrypted nor signed")
+	// ErrUnverifiable is returned when the labels cannot be decrypted or their signature is invalid
+	ErrUnverifiable = errors.New("labels cannot be verified")
+)
+
+const (
+	// encryptedToken holds the encrypted labels, it replaces the plain labels
+	encryptedToken = "encrypted"
+	// signatureToken holds the signature of all preceding tokens, it is always the last token
+	signatureToken = "signature"
+)
+
+// LabelKeys protect the labels stored in TXT records from being read or forged.
+// The labels are encrypted with AES-GCM and signed with HMAC-SHA256, either of them is optional.
+// The encryption is deterministic, i.e. the nonce is derived from the labels, because the TXT records
+// of deleted and updated records are reconstructed from their labels and must match the stored ones.
+// Keys bound to a record by ForRecord authenticate its name, type and set identifier along with the labels.
+type LabelKeys struct {
+	encryption    cipher.AEAD
+	encryptionKey []byte
+	signingKey    []byte
+	// record identifies the record the keys are bound to
+	record []byte
+}
+
+// NewLabelKeys returns the LabelKeys for the given keys. The encryption key must be 16, 24 or 32 bytes long
+// to select AES-128, AES-192 or AES-256. Empty keys disable encryption or signing respectively, nil is returned
+// if both are empty.
+func NewLabelKeys(encryptionKey, signingKey []byte) (*LabelKeys, error) {
+	if len(encryptionKey) == 0 && len(signingKey) == 0 {
+		return nil, nil
+	}
+	keys := &LabelKeys{encryptionKey: encryptionKey, signingKey: signingKey}
+	if len(encryptionKey) > 0 {
+		block, err := aes.NewCipher(encryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key: %w", err)
+		}
+		keys.encryption, err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// ForRecord returns the keys bound to the record with the given name, type and set identifier. Labels protected
+// by them are neither verified nor decrypted by the keys of another record, i.e. they cannot be copied to it.
+func (k *LabelKeys) ForRecord(dnsName, recordType, setIdentifier string) *LabelKeys {
+	if k == nil {
+		return nil
+	}
+	bound := *k
+	bound.record = []byte(strings.Join([]string{strings.ToLower(strings.TrimSuffix(dnsName, ".")), recordType, setIdentifier}, "\x00"))
+	return &bound
+}
+
+// protect returns the heritage and label tokens with the labels encrypted and signed as configured
+func (k *LabelKeys) protect(heritageToken string, tokens []string) string {
+	if k == nil {
+		return strings.Join(append([]string{heritageToken}, tokens...), ",")
+	}
+	if k.encryption != nil {
+		plain := []byte(strings.Join(tokens, ","))
+		// the nonce depends on the record as well, a nonce must never be reused with other additional data
+		nonce := hmacSHA256(k.encryptionKey, k.record, plain)[:k.encryption.NonceSize()]
+		sealed := k.encryption.Seal(nonce, nonce, plain, k.record)
+		tokens = []string{encryptedToken + "=" + base64.RawURLEncoding.EncodeToString(sealed)}
+	}
+	text := strings.Join(append([]string{heritageToken}, tokens...), ",")
+	if len(k.signingKey) > 0 {
+		text += "," + signatureToken + "=" + base64.RawURLEncoding.EncodeToString(hmacSHA256(k.signingKey, k.record, []byte(text)))
+	}
+	return text
+}
+
+// unprotect verifies the signature of the tokens and decrypts them as configured.
+// Without keys encrypted tokens cannot be read, signatures are not verified.
+func (k *LabelKeys) unprotect(tokens []string) ([]string, error) {
+	protected := false
+	if k != nil && len(k.signingKey) > 0 {
+		last := tokens[len(tokens)-1]
+		if !strings.HasPrefix(last, signatureToken+"=") {
+			return nil, k.missingProtection(tokens)
+		}
+		signature, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(last, signatureToken+"="))
+		if err != nil {
+			return nil, ErrUnverifiable
+		}
+		tokens = tokens[:len(tokens)-1]
+		if !hmac.Equal(signature, hmacSHA256(k.signingKey, k.record, []byte(strings.Join(tokens, ",")))) {
+			return nil, ErrUnverifiable
+		}
+		protected = true
+	}
+
+	for i, token := range tokens {
+		if !strings.HasPrefix(token, encryptedToken+"=") {
+			continue
+		}
+		if k == nil || k.encryption == nil {
+			return nil, ErrUnverifiable
+		}
+		sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, encryptedToken+"="))
+		if err != nil || len(sealed) < k.encryption.NonceSize() {
+			return nil, ErrUnverifiable
+		}
+		nonce, ciphertext := sealed[:k.encryption.NonceSize()], sealed[k.encryption.NonceSize():]
+		plain, err := k.encryption.Open(nil, nonce, ciphertext, k.record)
+		if err != nil {
+			return nil, ErrUnverifiable
+		}
+		return append(tokens[:i:i], strings.Split(string(plain), ",")...), nil
+	}
+
+	if k != nil && k.encryption != nil && !protected {
+		return nil, ErrUnprotected
+	}
+	return tokens, nil
+}
+
+// missingProtection returns the error of unsigned tokens
+func (k *LabelKeys) missingProtection(tokens []string) error {
+	for _, token := range tokens {
+		if strings.HasPrefix(token, encryptedToken+"=") {
+			return ErrUnverifiable
+		}
+	}
+	return ErrUnprotected
+}
+
+// hmacSHA256 returns the MAC of the record and the data, the record is prefixed with its length to separate it
+func hmacSHA256(key, record, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(record)))
+	mac.Write(length[:])
+	mac.Write(record)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
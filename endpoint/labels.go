@@ -37,10 +37,33 @@ const (
 	// supposed to be inserted by AWS SD Provider, and parsed into OwnerLabelKey and ResourceLabelKey key by AWS SD Registry
 	AWSSDDescriptionLabel = "aws-sd-description"
 
+	// ResourceLabelSeparator separates the resources in the value of ResourceLabelKey when several resources
+	// share the same DNS name
+	ResourceLabelSeparator = ";"
+
+	// ResourceCreationTimestampLabelKey is the name of the label that holds the creation timestamp (RFC 3339) of the k8s resource
+	ResourceCreationTimestampLabelKey = "resource-created"
+
+	// ResourcePriorityLabelKey is the name of the label that holds the priority of the k8s resource when competing for a DNS name
+	ResourcePriorityLabelKey = "resource-priority"
+
+	// AdoptLabelKey is the name of the label that marks an endpoint as allowed to adopt an existing record without owner
+	AdoptLabelKey = "adopt"
+
+	// RequestedOwnerLabelKey is the name of the label that holds the owner id a desired endpoint is requested to be managed under
+	RequestedOwnerLabelKey = "requested-owner"
+
+	// RecordNameLabelKey is the name of the label that holds the DNS name of the record an ownership TXT record belongs to,
+	// it is stored by the TXT registry if the name of the TXT record cannot be mapped back to the one of the record
+	RecordNameLabelKey = "record-name"
+
 	// DualstackLabelKey is the name of the label that identifies dualstack endpoints
 	DualstackLabelKey = "dualstack"
 )
 
+// conflictLabelKeys are the names of the labels which only serve the resolution of conflicts between desired endpoints
+var conflictLabelKeys = []string{ResourceCreationTimestampLabelKey, ResourcePriorityLabelKey}
+
 // Labels store metadata related to the endpoint
 // it is then stored in a persistent storage via serialization
 type Labels map[string]string
@@ -50,10 +73,25 @@ func NewLabels() Labels {
 	return map[string]string{}
 }
 
+// Persistent returns a copy of the labels to be stored by a registry, i.e. without the labels
+// which only serve the resolution of conflicts between desired endpoints
+func (l Labels) Persistent() Labels {
+	persistent := NewLabels()
+	for k, v := range l {
+		persistent[k] = v
+	}
+	for _, k := range conflictLabelKeys {
+		delete(persistent, k)
+	}
+	return persistent
+}
+
 // NewLabelsFromString constructs endpoints labels from a provided format string
 // if heritage set to another value is found then error is returned
-// no heritage automatically assumes is not owned by external-dns and returns invalidHeritage error
-func NewLabelsFromString(labelText string) (Labels, error) {
+// no heritage automatically assumes is not owned by external-dns and returns invalidHeritage error.
+// If keys are given the labels must be protected by them, ErrUnprotected is returned for plain labels
+// and ErrUnverifiable for labels with an invalid signature or which cannot be decrypted.
+func NewLabelsFromString(labelText string, keys *LabelKeys) (Labels, error) {
 	endpointLabels := map[string]string{}
 	labelText = strings.Trim(labelText, "\"") // drop quotes
 	tokens := strings.Split(labelText, ",")
@@ -69,10 +107,6 @@ func NewLabelsFromString(labelText string) (Labels, error) {
 		}
 		if key == "heritage" {
 			foundExternalDNSHeritage = true
-			continue
-		}
-		if strings.HasPrefix(key, heritage) {
-			endpointLabels[strings.TrimPrefix(key, heritage+"/")] = val
 		}
 	}
 
@@ -80,25 +114,40 @@ func NewLabelsFromString(labelText string) (Labels, error) {
 		return nil, ErrInvalidHeritage
 	}
 
+	tokens, err := keys.unprotect(tokens)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		if len(strings.Split(token, "=")) != 2 {
+			continue
+		}
+		key := strings.Split(token, "=")[0]
+		val := strings.Split(token, "=")[1]
+		if strings.HasPrefix(key, heritage) {
+			endpointLabels[strings.TrimPrefix(key, heritage+"/")] = val
+		}
+	}
+
 	return endpointLabels, nil
 }
 
 // Serialize transforms endpoints labels into a external-dns recognizable format string
-// withQuotes adds additional quotes
-func (l Labels) Serialize(withQuotes bool) string {
+// withQuotes adds additional quotes, keys optionally encrypt and sign the labels
+func (l Labels) Serialize(withQuotes bool, keys *LabelKeys) string {
 	var tokens []string
-	tokens = append(tokens, fmt.Sprintf("heritage=%s", heritage))
-	var keys []string
+	var names []string
 	for key := range l {
-		keys = append(keys, key)
+		names = append(names, key)
 	}
-	sort.Strings(keys) // sort for consistency
+	sort.Strings(names) // sort for consistency
 
-	for _, key := range keys {
+	for _, key := range names {
 		tokens = append(tokens, fmt.Sprintf("%s/%s=%s", heritage, key, l[key]))
 	}
+	text := keys.protect(fmt.Sprintf("heritage=%s", heritage), tokens)
 	if withQuotes {
-		return fmt.Sprintf("\"%s\"", strings.Join(tokens, ","))
+		return fmt.Sprintf("\"%s\"", text)
 	}
-	return strings.Join(tokens, ",")
+	return text
 }
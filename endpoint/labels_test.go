@@ -18,6 +18,7 @@ package endpoint
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -56,36 +57,118 @@ func (suite *LabelsSuite) SetupTest() {
 }
 
 func (suite *LabelsSuite) TestSerialize() {
-	suite.Equal(suite.fooAsText, suite.foo.Serialize(false), "should serializeLabel")
-	suite.Equal(suite.fooAsTextWithQuotes, suite.foo.Serialize(true), "should serializeLabel")
+	suite.Equal(suite.fooAsText, suite.foo.Serialize(false, nil), "should serializeLabel")
+	suite.Equal(suite.fooAsTextWithQuotes, suite.foo.Serialize(true, nil), "should serializeLabel")
 }
 
 func (suite *LabelsSuite) TestDeserialize() {
-	foo, err := NewLabelsFromString(suite.fooAsText)
+	foo, err := NewLabelsFromString(suite.fooAsText, nil)
 	suite.NoError(err, "should succeed for valid label text")
 	suite.Equal(suite.foo, foo, "should reconstruct original label map")
 
-	foo, err = NewLabelsFromString(suite.fooAsTextWithQuotes)
+	foo, err = NewLabelsFromString(suite.fooAsTextWithQuotes, nil)
 	suite.NoError(err, "should succeed for valid label text")
 	suite.Equal(suite.foo, foo, "should reconstruct original label map")
 
-	bar, err := NewLabelsFromString(suite.barText)
+	bar, err := NewLabelsFromString(suite.barText, nil)
 	suite.NoError(err, "should succeed for valid label text")
 	suite.Equal(suite.barTextAsMap, bar, "should reconstruct original label map")
 
-	noHeritage, err := NewLabelsFromString(suite.noHeritageText)
+	noHeritage, err := NewLabelsFromString(suite.noHeritageText, nil)
 	suite.Equal(ErrInvalidHeritage, err, "should fail if no heritage is found")
 	suite.Nil(noHeritage, "should return nil")
 
-	wrongHeritage, err := NewLabelsFromString(suite.wrongHeritageText)
+	wrongHeritage, err := NewLabelsFromString(suite.wrongHeritageText, nil)
 	suite.Equal(ErrInvalidHeritage, err, "should fail if wrong heritage is found")
 	suite.Nil(wrongHeritage, "if error should return nil")
 
-	multipleHeritage, err := NewLabelsFromString(suite.multipleHeritageText)
+	multipleHeritage, err := NewLabelsFromString(suite.multipleHeritageText, nil)
 	suite.Equal(ErrInvalidHeritage, err, "should fail if multiple heritage is found")
 	suite.Nil(multipleHeritage, "if error should return nil")
 }
 
+func (suite *LabelsSuite) TestPersistent() {
+	labels := Labels{
+		OwnerLabelKey:                     "foo-owner",
+		ResourceLabelKey:                  "foo-resource",
+		ResourceCreationTimestampLabelKey: "2022-01-01T00:00:00Z",
+		ResourcePriorityLabelKey:          "10",
+	}
+	suite.Equal(suite.foo, labels.Persistent(), "should drop the labels for the resolution of conflicts")
+	suite.Len(labels, 4, "should not change the labels")
+	suite.Equal(Labels{}, Labels(nil).Persistent())
+}
+
+func (suite *LabelsSuite) TestProtected() {
+	encrypted, err := NewLabelKeys([]byte("0123456789abcdef0123456789abcdef"), nil)
+	suite.Require().NoError(err)
+	signed, err := NewLabelKeys(nil, []byte("signing-key"))
+	suite.Require().NoError(err)
+	both, err := NewLabelKeys([]byte("0123456789abcdef"), []byte("signing-key"))
+	suite.Require().NoError(err)
+	otherKeys, err := NewLabelKeys([]byte("fedcba9876543210"), []byte("other-signing-key"))
+	suite.Require().NoError(err)
+
+	for _, keys := range []*LabelKeys{encrypted, signed, both} {
+		text := suite.foo.Serialize(true, keys)
+		suite.Equal(text, suite.foo.Serialize(true, keys), "should be deterministic")
+		suite.True(strings.HasPrefix(text, `"heritage=external-dns,`), "should keep the heritage readable")
+
+		foo, err := NewLabelsFromString(text, keys)
+		suite.NoError(err, "should succeed for protected label text")
+		suite.Equal(suite.foo, foo, "should reconstruct original label map")
+
+		_, err = NewLabelsFromString(suite.fooAsText, keys)
+		suite.Equal(ErrUnprotected, err, "should fail for plain label text")
+
+		_, err = NewLabelsFromString(suite.noHeritageText, keys)
+		suite.Equal(ErrInvalidHeritage, err, "should fail if no heritage is found")
+
+		_, err = NewLabelsFromString(text, otherKeys)
+		suite.Equal(ErrUnverifiable, err, "should fail for other keys")
+	}
+
+	for _, keys := range []*LabelKeys{encrypted, signed, both} {
+		text := suite.foo.Serialize(true, keys.ForRecord("foo.example.org", "TXT", "a"))
+		foo, err := NewLabelsFromString(text, keys.ForRecord("FOO.example.org.", "TXT", "a"))
+		suite.NoError(err, "should succeed for the same record")
+		suite.Equal(suite.foo, foo, "should reconstruct original label map")
+
+		for _, other := range []*LabelKeys{
+			keys,
+			keys.ForRecord("bar.example.org", "TXT", "a"),
+			keys.ForRecord("foo.example.org", "A", "a"),
+			keys.ForRecord("foo.example.org", "TXT", "b"),
+		} {
+			_, err = NewLabelsFromString(text, other)
+			suite.Equal(ErrUnverifiable, err, "should fail for another record")
+		}
+	}
+	suite.Nil((*LabelKeys)(nil).ForRecord("foo.example.org", "TXT", ""))
+
+	text := suite.foo.Serialize(false, encrypted)
+	suite.NotContains(text, "foo-owner", "should not reveal the labels")
+	_, err = NewLabelsFromString(text, nil)
+	suite.Equal(ErrUnverifiable, err, "encrypted labels cannot be read without key")
+
+	text = suite.foo.Serialize(false, signed)
+	suite.Contains(text, "external-dns/owner=foo-owner", "should not encrypt the labels")
+	foo, err := NewLabelsFromString(text, nil)
+	suite.NoError(err, "signed labels can be read without key")
+	suite.Equal(suite.foo, foo, "should reconstruct original label map")
+
+	forged := strings.Replace(text, "foo-owner", "bar-owner", 1)
+	_, err = NewLabelsFromString(forged, signed)
+	suite.Equal(ErrUnverifiable, err, "should fail for forged labels")
+
+	_, err = NewLabelKeys([]byte("too-short"), nil)
+	suite.Error(err, "should fail for invalid AES key size")
+
+	keys, err := NewLabelKeys(nil, nil)
+	suite.NoError(err)
+	suite.Nil(keys, "should not protect labels without keys")
+}
+
 func TestLabels(t *testing.T) {
 	suite.Run(t, new(LabelsSuite))
 }
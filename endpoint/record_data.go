@@ -0,0 +1,312 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// maxCharacterStringLength is the maximum length of a single character string of a TXT record
+const maxCharacterStringLength = 255
+
+// MXTarget is the data of a MX record, written as "<preference> <exchange>", e.g. "10 mail.example.com"
+type MXTarget struct {
+	Preference uint16
+	Exchange   string
+}
+
+// ParseMXTarget parses the target of a MX record
+func ParseMXTarget(target string) (*MXTarget, error) {
+	fields := strings.Fields(target)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid MX target %q: expected \"<preference> <exchange>\"", target)
+	}
+	preference, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MX target %q: invalid preference %q", target, fields[0])
+	}
+	exchange, err := parseTargetHostname(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid MX target %q: %w", target, err)
+	}
+	return &MXTarget{Preference: uint16(preference), Exchange: exchange}, nil
+}
+
+// String returns the MX target in its canonical form
+func (t *MXTarget) String() string {
+	return fmt.Sprintf("%d %s", t.Preference, t.Exchange)
+}
+
+// SRVTarget is the data of a SRV record, written as "<priority> <weight> <port> <target>",
+// e.g. "10 5 5060 sip.example.com"
+type SRVTarget struct {
+	Priority uint16
+	Weight   uint16
+	Port     uint16
+	Target   string
+}
+
+// ParseSRVTarget parses the target of a SRV record
+func ParseSRVTarget(target string) (*SRVTarget, error) {
+	fields := strings.Fields(target)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("invalid SRV target %q: expected \"<priority> <weight> <port> <target>\"", target)
+	}
+	var values [3]uint16
+	for i, name := range []string{"priority", "weight", "port"} {
+		value, err := strconv.ParseUint(fields[i], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SRV target %q: invalid %s %q", target, name, fields[i])
+		}
+		values[i] = uint16(value)
+	}
+	host, err := parseTargetHostname(fields[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid SRV target %q: %w", target, err)
+	}
+	return &SRVTarget{Priority: values[0], Weight: values[1], Port: values[2], Target: host}, nil
+}
+
+// String returns the SRV target in its canonical form
+func (t *SRVTarget) String() string {
+	return fmt.Sprintf("%d %d %d %s", t.Priority, t.Weight, t.Port, t.Target)
+}
+
+// CAATarget is the data of a CAA record, written as "<flags> <tag> <value>", e.g. `0 issue "letsencrypt.org"`.
+// The value may be quoted.
+type CAATarget struct {
+	Flags uint8
+	Tag   string
+	Value string
+}
+
+// ParseCAATarget parses the target of a CAA record
+func ParseCAATarget(target string) (*CAATarget, error) {
+	fields := strings.Fields(target)
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("invalid CAA target %q: expected \"<flags> <tag> <value>\"", target)
+	}
+	flags, err := strconv.ParseUint(fields[0], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CAA target %q: invalid flags %q", target, fields[0])
+	}
+	tag := fields[1]
+	if len(tag) > 15 || strings.IndexFunc(tag, func(r rune) bool { return !isAlphanumeric(r) }) >= 0 {
+		return nil, fmt.Errorf("invalid CAA target %q: the tag %q must consist of at most 15 alphanumeric characters", target, tag)
+	}
+	// the value is everything after the tag, it may contain spaces
+	value := strings.TrimSpace(target)
+	for _, field := range fields[:2] {
+		value = strings.TrimSpace(strings.TrimPrefix(value, field))
+	}
+	if strings.HasPrefix(value, `"`) {
+		values, err := parseCharacterStrings(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CAA target %q: %w", target, err)
+		}
+		if len(values) != 1 {
+			return nil, fmt.Errorf("invalid CAA target %q: the value must be a single string", target)
+		}
+		value = values[0]
+	}
+	return &CAATarget{Flags: uint8(flags), Tag: tag, Value: value}, nil
+}
+
+// String returns the CAA target in its canonical form, the value is always quoted
+func (t *CAATarget) String() string {
+	return fmt.Sprintf("%d %s %s", t.Flags, t.Tag, quoteCharacterString(t.Value))
+}
+
+// TXTTarget is the data of a TXT record, a list of character strings of at most 255 characters each.
+// A target is either a plain value, which is split into as many character strings as needed, or
+// a list of quoted character strings like `"first part" "second part"`.
+type TXTTarget []string
+
+// NewTXTTarget returns the TXT target holding the value, split into character strings of at most 255 characters
+func NewTXTTarget(value string) TXTTarget {
+	t := TXTTarget{}
+	for len(value) > maxCharacterStringLength {
+		t = append(t, value[:maxCharacterStringLength])
+		value = value[maxCharacterStringLength:]
+	}
+	return append(t, value)
+}
+
+// ParseTXTTarget parses the target of a TXT record
+func ParseTXTTarget(target string) (TXTTarget, error) {
+	if !strings.HasPrefix(strings.TrimSpace(target), `"`) {
+		return NewTXTTarget(target), nil
+	}
+	values, err := parseCharacterStrings(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TXT target: %w", err)
+	}
+	t := TXTTarget{}
+	for _, value := range values {
+		t = append(t, NewTXTTarget(value)...)
+	}
+	return t, nil
+}
+
+// Value returns the concatenated character strings
+func (t TXTTarget) Value() string {
+	return strings.Join(t, "")
+}
+
+// String returns the TXT target as a list of quoted character strings
+func (t TXTTarget) String() string {
+	quoted := make([]string, len(t))
+	for i, value := range t {
+		quoted[i] = quoteCharacterString(value)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// NormalizeTarget validates the target of a record of the given type and returns it in its canonical form,
+// which is the form providers return it in. Targets of record types without validation are returned unchanged.
+// TXT targets which fit into a single character string are returned unchanged as well, longer ones are
+// split into a list of quoted character strings.
+func NormalizeTarget(recordType, target string) (string, error) {
+	switch recordType {
+	case RecordTypeA:
+		ip, err := netip.ParseAddr(target)
+		if err != nil || !ip.Unmap().Is4() {
+			return "", fmt.Errorf("invalid A target %q: not an IPv4 address", target)
+		}
+		return target, nil
+	case RecordTypeAAAA:
+		ip, err := netip.ParseAddr(target)
+		if err != nil || !ip.Is6() || ip.Is4In6() {
+			return "", fmt.Errorf("invalid AAAA target %q: not an IPv6 address", target)
+		}
+		return ip.String(), nil
+	case RecordTypeMX:
+		t, err := ParseMXTarget(target)
+		if err != nil {
+			return "", err
+		}
+		return t.String(), nil
+	case RecordTypeSRV:
+		t, err := ParseSRVTarget(target)
+		if err != nil {
+			return "", err
+		}
+		return t.String(), nil
+	case RecordTypeCAA:
+		t, err := ParseCAATarget(target)
+		if err != nil {
+			return "", err
+		}
+		return t.String(), nil
+	case RecordTypeTXT:
+		t, err := ParseTXTTarget(target)
+		if err != nil {
+			return "", err
+		}
+		if len(t) == 1 {
+			return target, nil
+		}
+		return t.String(), nil
+	}
+	return target, nil
+}
+
+// parseTargetHostname validates the hostname of a MX or SRV target and returns it without the trailing dot.
+// The root "." is returned unchanged, it denotes that there is no mail exchange or service.
+func parseTargetHostname(hostname string) (string, error) {
+	if hostname == "." {
+		return hostname, nil
+	}
+	hostname = strings.TrimSuffix(hostname, ".")
+	if hostname == "" || len(hostname) > 253 {
+		return "", fmt.Errorf("invalid hostname %q", hostname)
+	}
+	for _, label := range strings.Split(hostname, ".") {
+		if label == "" || len(label) > 63 {
+			return "", fmt.Errorf("invalid hostname %q", hostname)
+		}
+	}
+	return hostname, nil
+}
+
+// parseCharacterStrings parses a list of quoted character strings separated by whitespace.
+// Quotes and backslashes inside of a string are escaped by a backslash, other characters by \DDD.
+func parseCharacterStrings(s string) ([]string, error) {
+	var values []string
+	s = strings.TrimSpace(s)
+	for s != "" {
+		if s[0] != '"' {
+			return nil, fmt.Errorf("expected a quoted string at %q", s)
+		}
+		var value strings.Builder
+		i, closed := 1, false
+		for ; i < len(s); i++ {
+			c := s[i]
+			if c == '"' {
+				closed = true
+				break
+			}
+			if c != '\\' {
+				value.WriteByte(c)
+				continue
+			}
+			if i+3 < len(s) && isDigit(s[i+1]) && isDigit(s[i+2]) && isDigit(s[i+3]) {
+				code, _ := strconv.Atoi(s[i+1 : i+4])
+				if code > 255 {
+					return nil, fmt.Errorf("invalid escape sequence %q", s[i:i+4])
+				}
+				value.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+			if i+1 == len(s) {
+				break
+			}
+			i++
+			value.WriteByte(s[i])
+		}
+		if !closed {
+			return nil, fmt.Errorf("unterminated quoted string %q", s)
+		}
+		values = append(values, value.String())
+		rest := s[i+1:]
+		s = strings.TrimSpace(rest)
+		if s != "" && s == rest {
+			return nil, fmt.Errorf("expected whitespace after the quoted string at %q", s)
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no quoted string")
+	}
+	return values, nil
+}
+
+func quoteCharacterString(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlphanumeric(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMXTarget(t *testing.T) {
+	target, err := ParseMXTarget("10 mail.example.com.")
+	assert.NoError(t, err)
+	assert.Equal(t, &MXTarget{Preference: 10, Exchange: "mail.example.com"}, target)
+	assert.Equal(t, "10 mail.example.com", target.String())
+
+	for _, invalid := range []string{"", "mail.example.com", "10", "-1 mail.example.com", "65536 mail.example.com", "10 mail..example.com", "10 mail.example.com extra"} {
+		_, err := ParseMXTarget(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseSRVTarget(t *testing.T) {
+	target, err := ParseSRVTarget("10  5 5060 sip.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, &SRVTarget{Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"}, target)
+	assert.Equal(t, "10 5 5060 sip.example.com", target.String())
+
+	target, err = ParseSRVTarget("0 0 0 .")
+	assert.NoError(t, err)
+	assert.Equal(t, "0 0 0 .", target.String())
+
+	for _, invalid := range []string{"", "10 5 sip.example.com", "10 5 70000 sip.example.com", "a 5 5060 sip.example.com", "10 5 5060 "} {
+		_, err := ParseSRVTarget(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseCAATarget(t *testing.T) {
+	for _, tc := range []struct {
+		target   string
+		expected CAATarget
+	}{
+		{`0 issue "letsencrypt.org"`, CAATarget{Flags: 0, Tag: "issue", Value: "letsencrypt.org"}},
+		{`0 issue letsencrypt.org`, CAATarget{Flags: 0, Tag: "issue", Value: "letsencrypt.org"}},
+		{`128 issuewild ";"`, CAATarget{Flags: 128, Tag: "issuewild", Value: ";"}},
+		{`0 issue "ca.example.net; account=230123"`, CAATarget{Flags: 0, Tag: "issue", Value: "ca.example.net; account=230123"}},
+		{`0 iodef "mailto:\"security\"@example.com"`, CAATarget{Flags: 0, Tag: "iodef", Value: `mailto:"security"@example.com`}},
+	} {
+		target, err := ParseCAATarget(tc.target)
+		assert.NoError(t, err, tc.target)
+		assert.Equal(t, &tc.expected, target, tc.target)
+	}
+
+	target, err := ParseCAATarget(`0 iodef "mailto:\"security\"@example.com"`)
+	assert.NoError(t, err)
+	assert.Equal(t, `0 iodef "mailto:\"security\"@example.com"`, target.String())
+
+	for _, invalid := range []string{"", "0 issue", `256 issue "letsencrypt.org"`, `0 is-sue "letsencrypt.org"`, `0 issue "letsencrypt.org`, `0 issue "a" "b"`} {
+		_, err := ParseCAATarget(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseTXTTarget(t *testing.T) {
+	long := strings.Repeat("a", 300)
+
+	for _, tc := range []struct {
+		target   string
+		expected TXTTarget
+	}{
+		{"v=spf1 -all", TXTTarget{"v=spf1 -all"}},
+		{`"v=spf1 -all"`, TXTTarget{"v=spf1 -all"}},
+		{`"first part" "second part"`, TXTTarget{"first part", "second part"}},
+		{`"escaped \"quote\" and \\ backslash" "\065"`, TXTTarget{`escaped "quote" and \ backslash`, "A"}},
+		{long, TXTTarget{long[:255], long[255:]}},
+		{`"` + long + `"`, TXTTarget{long[:255], long[255:]}},
+	} {
+		target, err := ParseTXTTarget(tc.target)
+		assert.NoError(t, err, tc.target)
+		assert.Equal(t, tc.expected, target, tc.target)
+	}
+
+	for _, invalid := range []string{`"unterminated`, `"a""b"`, `"a" b`, `"\256"`} {
+		_, err := ParseTXTTarget(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	target := TXTTarget{`say "hi"`, "again"}
+	assert.Equal(t, `"say \"hi\"" "again"`, target.String())
+	assert.Equal(t, `say "hi"again`, target.Value())
+}
+
+func TestNormalizeTarget(t *testing.T) {
+	long := strings.Repeat("a", 300)
+
+	for _, tc := range []struct {
+		recordType string
+		target     string
+		expected   string
+	}{
+		{RecordTypeA, "1.2.3.4", "1.2.3.4"},
+		{RecordTypeAAAA, "2001:DB8:0::1", "2001:db8::1"},
+		{RecordTypeCNAME, "foo.example.com", "foo.example.com"},
+		{RecordTypeMX, "10   mail.example.com.", "10 mail.example.com"},
+		{RecordTypeSRV, "1 2 443 svc.example.com.", "1 2 443 svc.example.com"},
+		{RecordTypeCAA, "0 issue letsencrypt.org", `0 issue "letsencrypt.org"`},
+		{RecordTypeTXT, "v=spf1 -all", "v=spf1 -all"},
+		{RecordTypeTXT, `"heritage=external-dns,external-dns/owner=default"`, `"heritage=external-dns,external-dns/owner=default"`},
+		{RecordTypeTXT, long, `"` + long[:255] + `" "` + long[255:] + `"`},
+	} {
+		actual, err := NormalizeTarget(tc.recordType, tc.target)
+		assert.NoError(t, err, tc.target)
+		assert.Equal(t, tc.expected, actual, tc.target)
+	}
+
+	for _, tc := range []struct {
+		recordType string
+		target     string
+	}{
+		{RecordTypeA, "foo.example.com"},
+		{RecordTypeA, "2001:db8::1"},
+		{RecordTypeAAAA, "1.2.3.4"},
+		{RecordTypeAAAA, "::ffff:1.2.3.4"},
+		{RecordTypeMX, "mail.example.com"},
+		{RecordTypeSRV, "1 2 svc.example.com"},
+		{RecordTypeCAA, "issue letsencrypt.org"},
+		{RecordTypeTXT, `"unterminated`},
+	} {
+		_, err := NormalizeTarget(tc.recordType, tc.target)
+		assert.Error(t, err, tc.target)
+	}
+}
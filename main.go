@@ -18,16 +18,32 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/record"
 
 	"sigs.k8s.io/external-dns/controller"
 	"sigs.k8s.io/external-dns/endpoint"
@@ -76,6 +92,8 @@ import (
 	"sigs.k8s.io/external-dns/source"
 )
 
+const inClusterNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
 func main() {
 	cfg := externaldns.NewConfig()
 	if err := cfg.ParseFlags(os.Args[1:]); err != nil {
@@ -90,6 +108,13 @@ func main() {
 		log.Fatalf("config validation failed: %v", err)
 	}
 
+	if cfg.OwnershipReport != "" {
+		// the report only reads the records, make sure the providers never change any
+		cfg.DryRun = true
+		// read the records from the provider rather than a persisted records cache, which holds no orphaned ownership records
+		cfg.TXTCacheInterval = 0
+	}
+
 	if cfg.DryRun {
 		log.Info("running in dry-run mode. No changes to DNS records will be made.")
 	}
@@ -124,7 +149,12 @@ func main() {
 		PublishInternal:                cfg.PublishInternal,
 		PublishHostIP:                  cfg.PublishHostIP,
 		AlwaysPublishNotReadyAddresses: cfg.AlwaysPublishNotReadyAddresses,
+		NodeAddressTypes:               cfg.NodeAddressTypes,
+		NodeAddressFamily:              cfg.NodeAddressFamily,
+		NodeExcludeUnready:             cfg.NodeExcludeUnready,
+		NodeAggregateFQDN:              cfg.NodeAggregateFQDN,
 		ConnectorServer:                cfg.ConnectorSourceServer,
+		ConnectorPollInterval:          cfg.ConnectorSourcePollInterval,
 		CRDSourceAPIVersion:            cfg.CRDSourceAPIVersion,
 		CRDSourceKind:                  cfg.CRDSourceKind,
 		KubeConfig:                     cfg.KubeConfig,
@@ -142,7 +172,7 @@ func main() {
 	}
 
 	// Lookup all the selected sources by names and pass them the desired configuration.
-	sources, err := source.ByNames(ctx, &source.SingletonClientGenerator{
+	clientGenerator := &source.SingletonClientGenerator{
 		KubeConfig:   cfg.KubeConfig,
 		APIServerURL: cfg.APIServerURL,
 		// If update events are enabled, disable timeout.
@@ -152,7 +182,8 @@ func main() {
 			}
 			return cfg.RequestTimeout
 		}(),
-	}, cfg.Sources, sourceCfg)
+	}
+	sources, err := source.ByNames(ctx, clientGenerator, cfg.Sources, sourceCfg)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -164,6 +195,16 @@ func main() {
 	endpointsSource := source.NewDedupSource(source.NewMultiSource(sources, sourceCfg.DefaultTargets))
 	endpointsSource = source.NewTargetFilterSource(endpointsSource, targetFilter)
 
+	// Derive PTR records for the A and AAAA records in the configured reverse zones
+	if cfg.CreatePTR {
+		endpointsSource = source.NewPTRSource(endpointsSource, cfg.PTRZones)
+		cfg.ManagedDNSRecordTypes = append(cfg.ManagedDNSRecordTypes, endpoint.RecordTypePTR)
+		// the reverse zones have to pass a configured domain filter as well
+		if endpoint.NewDomainFilter(cfg.DomainFilter).IsConfigured() {
+			cfg.DomainFilter = append(cfg.DomainFilter, cfg.PTRZones...)
+		}
+	}
+
 	// RegexDomainFilter overrides DomainFilter
 	var domainFilter endpoint.DomainFilter
 	if cfg.RegexDomainFilter.String() != "" {
@@ -366,9 +407,43 @@ func main() {
 	case "noop":
 		r, err = registry.NewNoopRegistry(p)
 	case "txt":
-		r, err = registry.NewTXTRegistry(p, cfg.TXTPrefix, cfg.TXTSuffix, cfg.TXTOwnerID, cfg.TXTCacheInterval, cfg.TXTWildcardReplacement, cfg.ManagedDNSRecordTypes)
+		var labelKeys *endpoint.LabelKeys
+		labelKeys, err = newTXTLabelKeys(ctx, cfg, clientGenerator)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ownerIDMappings := make([]registry.OwnerIDMapping, 0, len(cfg.TXTOwnerIDMappings))
+		for _, m := range cfg.TXTOwnerIDMappings {
+			mapping, err := registry.ParseOwnerIDMapping(m)
+			if err != nil {
+				log.Fatal(err)
+			}
+			ownerIDMappings = append(ownerIDMappings, mapping)
+		}
+		// the orphaned TXT records are not even planned for deletion in dry-run mode
+		gcInterval := cfg.TXTGCInterval
+		if cfg.DryRun {
+			gcInterval = 0
+		}
+		r, err = registry.NewTXTRegistry(p, cfg.TXTPrefix, cfg.TXTSuffix, cfg.TXTOwnerID, cfg.TXTCacheInterval, cfg.TXTWildcardReplacement, cfg.ManagedDNSRecordTypes,
+			registry.WithTXTLabelKeys(labelKeys, cfg.TXTAcceptUnprotected),
+			registry.WithTXTPreviousOwnerIDs(cfg.TXTPreviousOwnerIDs),
+			registry.WithTXTAdoptRegex(cfg.TXTAdoptRegex),
+			registry.WithTXTGarbageCollection(gcInterval, cfg.TXTGCMaxDeletions),
+			registry.WithTXTFormat(cfg.TXTFormat),
+			registry.WithTXTNameMapper(cfg.TXTNameMapper),
+			registry.WithTXTOwnerIDs(cfg.TXTAdditionalOwnerIDs, ownerIDMappings),
+			registry.WithTXTRecordsCacheStore(newRecordsCacheStore(cfg, clientGenerator), recordsCacheFingerprint(cfg)),
+		)
 	case "aws-sd":
 		r, err = registry.NewAWSSDRegistry(p.(*awssd.AWSSDProvider), cfg.TXTOwnerID)
+	case "configmap":
+		var kubeClient kubernetes.Interface
+		kubeClient, err = clientGenerator.KubeClient()
+		if err != nil {
+			log.Fatal(err)
+		}
+		r, err = registry.NewConfigMapRegistry(p, kubeClient, inClusterNamespace(cfg.ConfigMapRegistryNamespace), cfg.ConfigMapRegistryName, cfg.ConfigMapRegistryShards, cfg.TXTOwnerID, cfg.TXTPrefix, cfg.TXTSuffix, cfg.TXTWildcardReplacement, cfg.DryRun)
 	default:
 		log.Fatalf("unknown registry: %s", cfg.Registry)
 	}
@@ -377,19 +452,120 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if cfg.OwnershipReport != "" {
+		records, err := r.Records(ctx)
+		if err != nil {
+			log.Fatalf("failed to read records: %v", err)
+		}
+		if err := registry.WriteOwnershipReport(os.Stdout, registry.NewOwnershipReport(records, registry.OrphanedRecords(r)), cfg.OwnershipReport); err != nil {
+			log.Fatalf("failed to write ownership report: %v", err)
+		}
+
+		os.Exit(0)
+	}
+
 	policy, exists := plan.Policies[cfg.Policy]
 	if !exists {
 		log.Fatalf("unknown policy: %s", cfg.Policy)
 	}
+	if cfg.PolicyConfig != "" {
+		policy, err = newDomainPolicy(cfg.PolicyConfig, policy)
+		if err != nil {
+			log.Fatalf("failed to load policy config: %v", err)
+		}
+	}
+
+	resolver, exists := plan.ConflictResolvers[cfg.ConflictResolution]
+	if !exists {
+		log.Fatalf("unknown conflict resolution: %s", cfg.ConflictResolution)
+	}
+
+	var changeBudget *plan.ChangeBudgetPolicy
+	if cfg.MaxDeletes > 0 || cfg.MaxDeletePercentage > 0 {
+		changeBudget = &plan.ChangeBudgetPolicy{
+			MaxDeletes:          cfg.MaxDeletes,
+			MaxDeletePercentage: cfg.MaxDeletePercentage,
+		}
+		// the noop registry does not track ownership, hence all records are considered as owned
+		if cfg.Registry != "noop" {
+			changeBudget.OwnerIDs = []string{cfg.TXTOwnerID}
+		}
+		// the TXT registry may manage the records of several owner ids
+		if txtRegistry, ok := r.(*registry.TXTRegistry); ok {
+			changeBudget.OwnerIDs = txtRegistry.OwnerIDs()
+		}
+	}
+
+	var auditLog *controller.AuditLog
+	if cfg.AuditLog != "" {
+		auditLog, err = controller.NewAuditLog(cfg.AuditLog)
+		if err != nil {
+			log.Fatalf("failed to open audit log: %v", err)
+		}
+		defer auditLog.Close()
+	}
+
+	var reporter controller.Reporter
+	if cfg.EmitEvents {
+		reporter, err = newKubernetesReporter(ctx, cfg, clientGenerator)
+		if err != nil {
+			log.Fatalf("failed to set up event reporting: %v", err)
+		}
+	}
 
 	ctrl := controller.Controller{
 		Source:               endpointsSource,
 		Registry:             r,
 		Policy:               policy,
+		ConflictResolver:     resolver,
+		ChangeBudget:         changeBudget,
+		AdoptUnowned:         cfg.Registry == "txt",
 		Interval:             cfg.Interval,
 		DomainFilter:         domainFilter,
 		ManagedRecordTypes:   cfg.ManagedDNSRecordTypes,
 		MinEventSyncInterval: cfg.MinEventSyncInterval,
+		PlanOutput:           cfg.PlanOutput,
+		PlanOutputFormat:     cfg.PlanOutputFormat,
+		AuditLog:             auditLog,
+		Reporter:             reporter,
+		QuarantineBackoff:    cfg.QuarantineBackoff,
+		QuarantineMaxBackoff: cfg.QuarantineMaxBackoff,
+	}
+	if cfg.ServeOwnershipReport {
+		http.Handle("/ownership", ctrl.OwnershipReportHandler())
+	}
+
+	if cfg.ApplyPlan != "" {
+		f, err := os.Open(cfg.ApplyPlan)
+		if err != nil {
+			log.Fatal(err)
+		}
+		out, err := plan.ReadOutput(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("failed to read plan %s: %v", cfg.ApplyPlan, err)
+		}
+		if err := ctrl.ApplyPlan(ctx, out); err != nil {
+			log.Fatal(err)
+		}
+
+		os.Exit(0)
+	}
+
+	if cfg.TXTGCOnce {
+		garbage, err := r.(*registry.TXTRegistry).GarbageChanges(ctx)
+		if err != nil {
+			log.Fatalf("failed to find orphaned TXT records: %v", err)
+		}
+		if cfg.DryRun {
+			log.Infof("Found %d orphaned TXT record(s), none are deleted in dry-run mode", len(garbage.Delete))
+			os.Exit(0)
+		}
+		if err := ctrl.ApplyMaintenance(ctx, garbage); err != nil {
+			log.Fatalf("failed to delete orphaned TXT records: %v", err)
+		}
+
+		os.Exit(0)
 	}
 
 	if cfg.Once {
@@ -408,6 +584,17 @@ func main() {
 		ctrl.Source.AddEventHandler(ctx, func() { ctrl.ScheduleRunOnce(time.Now()) })
 	}
 
+	if cfg.LeaderElect {
+		leaderElection, err := newLeaderElectionConfig(cfg, clientGenerator)
+		if err != nil {
+			log.Fatalf("failed to set up leader election: %v", err)
+		}
+		if err := ctrl.RunWithLeaderElection(ctx, leaderElection); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	ctrl.ScheduleRunOnce(time.Now())
 	ctrl.Run(ctx)
 }
@@ -430,3 +617,138 @@ func serveMetrics(address string) {
 
 	log.Fatal(http.ListenAndServe(address, nil))
 }
+
+func newDomainPolicy(path string, defaultPolicy plan.Policy) (plan.Policy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config, err := plan.ReadDomainPolicyConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return plan.NewDomainPolicy(config, defaultPolicy)
+}
+
+func newKubernetesReporter(ctx context.Context, cfg *externaldns.Config, clientGenerator source.ClientGenerator) (controller.Reporter, error) {
+	kubeClient, err := clientGenerator.KubeClient()
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := clientGenerator.DynamicKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+	crdGroupVersion, err := schema.ParseGroupVersion(cfg.CRDSourceAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+	crdResource := crdGroupVersion.WithResource(strings.ToLower(cfg.CRDSourceKind) + "s")
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(kubeClient.Discovery()))
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	recorder := broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: controller.EventSourceComponent})
+	// the resources are watched in the namespace of the sources
+	informers := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, 0, cfg.Namespace, nil)
+	return controller.NewKubernetesReporter(recorder, dynamicClient, informers, ctx.Done(), mapper, cfg.Sources, crdResource), nil
+}
+
+func newLeaderElectionConfig(cfg *externaldns.Config, clientGenerator source.ClientGenerator) (controller.LeaderElectionConfig, error) {
+	kubeClient, err := clientGenerator.KubeClient()
+	if err != nil {
+		return controller.LeaderElectionConfig{}, err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return controller.LeaderElectionConfig{}, err
+	}
+	return controller.LeaderElectionConfig{
+		Client:        kubeClient,
+		Namespace:     inClusterNamespace(cfg.LeaderElectionNamespace),
+		Name:          cfg.LeaderElectionLeaseName,
+		Identity:      hostname + "_" + string(uuid.NewUUID()),
+		LeaseDuration: cfg.LeaderElectionLeaseDuration,
+		RenewDeadline: cfg.LeaderElectionRenewDeadline,
+		RetryPeriod:   cfg.LeaderElectionRetryPeriod,
+	}, nil
+}
+
+// newTXTLabelKeys reads the keys protecting the labels of TXT ownership records from the key files or the keys Secret
+func newTXTLabelKeys(ctx context.Context, cfg *externaldns.Config, clientGenerator source.ClientGenerator) (*endpoint.LabelKeys, error) {
+	var encryptionKey, signingKey string
+	if cfg.TXTKeysSecret != "" {
+		kubeClient, err := clientGenerator.KubeClient()
+		if err != nil {
+			return nil, err
+		}
+		namespacedName := strings.Split(cfg.TXTKeysSecret, "/")
+		secret, err := kubeClient.CoreV1().Secrets(namespacedName[0]).Get(ctx, namespacedName[1], metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TXT keys secret %s: %w", cfg.TXTKeysSecret, err)
+		}
+		encryptionKey, signingKey = string(secret.Data["encryption-key"]), string(secret.Data["signing-key"])
+	}
+	for _, key := range []struct {
+		file  string
+		value *string
+	}{{cfg.TXTEncryptionKeyFile, &encryptionKey}, {cfg.TXTSigningKeyFile, &signingKey}} {
+		if key.file == "" {
+			continue
+		}
+		data, err := os.ReadFile(key.file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TXT key file: %w", err)
+		}
+		*key.value = string(data)
+	}
+
+	decodedEncryptionKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encryptionKey))
+	if err != nil {
+		return nil, fmt.Errorf("TXT encryption key is not base64 encoded: %w", err)
+	}
+	decodedSigningKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signingKey))
+	if err != nil {
+		return nil, fmt.Errorf("TXT signing key is not base64 encoded: %w", err)
+	}
+	return endpoint.NewLabelKeys(decodedEncryptionKey, decodedSigningKey)
+}
+
+// newRecordsCacheStore returns the store persisting the records cache of the TXT registry, nil if it is not persisted
+func newRecordsCacheStore(cfg *externaldns.Config, clientGenerator source.ClientGenerator) registry.RecordsCacheStore {
+	switch {
+	case cfg.TXTCacheFile != "":
+		return registry.NewFileRecordsCacheStore(cfg.TXTCacheFile)
+	case cfg.TXTCacheConfigMap != "":
+		kubeClient, err := clientGenerator.KubeClient()
+		if err != nil {
+			log.Fatal(err)
+		}
+		namespace, name := "", cfg.TXTCacheConfigMap
+		if namespacedName := strings.Split(cfg.TXTCacheConfigMap, "/"); len(namespacedName) == 2 {
+			namespace, name = namespacedName[0], namespacedName[1]
+		}
+		return registry.NewConfigMapRecordsCacheStore(kubeClient, inClusterNamespace(namespace), name)
+	default:
+		return nil
+	}
+}
+
+// recordsCacheFingerprint identifies the configuration, the persisted records cache is discarded once it changes
+func recordsCacheFingerprint(cfg *externaldns.Config) string {
+	sum := sha256.Sum256([]byte(cfg.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// inClusterNamespace returns the given namespace or, if empty, the namespace ExternalDNS runs in
+func inClusterNamespace(namespace string) string {
+	if namespace != "" {
+		return namespace
+	}
+	// the namespace ExternalDNS runs in is only known within a cluster
+	if data, err := os.ReadFile(inClusterNamespaceFile); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	return "default"
+}
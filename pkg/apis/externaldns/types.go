@@ -64,7 +64,12 @@ type Config struct {
 	PublishInternal                   bool
 	PublishHostIP                     bool
 	AlwaysPublishNotReadyAddresses    bool
+	NodeAddressTypes                  []string
+	NodeAddressFamily                 string
+	NodeExcludeUnready                bool
+	NodeAggregateFQDN                 string
 	ConnectorSourceServer             string
+	ConnectorSourcePollInterval       time.Duration
 	Provider                          string
 	GoogleProject                     string
 	GoogleBatchChangeSize             int
@@ -78,6 +83,8 @@ type Config struct {
 	ZoneIDFilter                      []string
 	TargetNetFilter                   []string
 	ExcludeTargetNets                 []string
+	CreatePTR                         bool
+	PTRZones                          []string
 	AlibabaCloudConfigFile            string
 	AlibabaCloudZoneType              string
 	AWSZoneType                       string
@@ -139,6 +146,10 @@ type Config struct {
 	TLSClientCert                     string
 	TLSClientCertKey                  string
 	Policy                            string
+	PolicyConfig                      string
+	ConflictResolution                string
+	MaxDeletes                        int
+	MaxDeletePercentage               float64
 	Registry                          string
 	TXTOwnerID                        string
 	TXTPrefix                         string
@@ -147,12 +158,45 @@ type Config struct {
 	MinEventSyncInterval              time.Duration
 	Once                              bool
 	DryRun                            bool
+	PlanOutput                        string
+	PlanOutputFormat                  string
+	ApplyPlan                         string
+	OwnershipReport                   string
+	ServeOwnershipReport              bool
+	AuditLog                          string
+	EmitEvents                        bool
+	QuarantineBackoff                 time.Duration
+	QuarantineMaxBackoff              time.Duration
+	LeaderElect                       bool
+	LeaderElectionNamespace           string
+	LeaderElectionLeaseName           string
+	LeaderElectionLeaseDuration       time.Duration
+	LeaderElectionRenewDeadline       time.Duration
+	LeaderElectionRetryPeriod         time.Duration
 	UpdateEvents                      bool
 	LogFormat                         string
 	MetricsAddress                    string
 	LogLevel                          string
 	TXTCacheInterval                  time.Duration
+	TXTCacheFile                      string
+	TXTCacheConfigMap                 string
 	TXTWildcardReplacement            string
+	TXTEncryptionKeyFile              string
+	TXTSigningKeyFile                 string
+	TXTKeysSecret                     string
+	TXTAcceptUnprotected              bool
+	TXTPreviousOwnerIDs               []string
+	TXTAdoptRegex                     *regexp.Regexp
+	TXTGCInterval                     time.Duration
+	TXTGCMaxDeletions                 int
+	TXTGCOnce                         bool
+	TXTFormat                         string
+	TXTNameMapper                     string
+	TXTAdditionalOwnerIDs             []string
+	TXTOwnerIDMappings                []string
+	ConfigMapRegistryName             string
+	ConfigMapRegistryNamespace        string
+	ConfigMapRegistryShards           int
 	ExoscaleEndpoint                  string
 	ExoscaleAPIKey                    string `secure:"yes"`
 	ExoscaleAPISecret                 string `secure:"yes"`
@@ -221,7 +265,12 @@ var defaultConfig = &Config{
 	Compatibility:               "",
 	PublishInternal:             false,
 	PublishHostIP:               false,
+	NodeAddressTypes:            []string{},
+	NodeAddressFamily:           "",
+	NodeExcludeUnready:          false,
+	NodeAggregateFQDN:           "",
 	ConnectorSourceServer:       "localhost:8080",
+	ConnectorSourcePollInterval: time.Minute,
 	Provider:                    "",
 	GoogleProject:               "",
 	GoogleBatchChangeSize:       1000,
@@ -234,6 +283,8 @@ var defaultConfig = &Config{
 	RegexDomainExclusion:        regexp.MustCompile(""),
 	TargetNetFilter:             []string{},
 	ExcludeTargetNets:           []string{},
+	CreatePTR:                   false,
+	PTRZones:                    []string{},
 	AlibabaCloudConfigFile:      "/etc/kubernetes/alibaba-cloud.json",
 	AWSZoneType:                 "",
 	AWSZoneTagFilter:            []string{},
@@ -283,16 +334,53 @@ var defaultConfig = &Config{
 	TLSClientCert:               "",
 	TLSClientCertKey:            "",
 	Policy:                      "sync",
+	PolicyConfig:                "",
+	ConflictResolution:          "per-resource",
+	MaxDeletes:                  0,
+	MaxDeletePercentage:         0,
 	Registry:                    "txt",
 	TXTOwnerID:                  "default",
 	TXTPrefix:                   "",
 	TXTSuffix:                   "",
 	TXTCacheInterval:            0,
+	TXTCacheFile:                "",
+	TXTCacheConfigMap:           "",
 	TXTWildcardReplacement:      "",
+	TXTEncryptionKeyFile:        "",
+	TXTSigningKeyFile:           "",
+	TXTKeysSecret:               "",
+	TXTAcceptUnprotected:        false,
+	TXTPreviousOwnerIDs:         []string{},
+	TXTAdoptRegex:               regexp.MustCompile(""),
+	TXTGCInterval:               0,
+	TXTGCMaxDeletions:           10,
+	TXTGCOnce:                   false,
+	TXTFormat:                   "dual",
+	TXTNameMapper:               "affix",
+	TXTAdditionalOwnerIDs:       []string{},
+	TXTOwnerIDMappings:          []string{},
+	ConfigMapRegistryName:       "external-dns-ownership",
+	ConfigMapRegistryNamespace:  "",
+	ConfigMapRegistryShards:     4,
 	MinEventSyncInterval:        5 * time.Second,
 	Interval:                    time.Minute,
 	Once:                        false,
 	DryRun:                      false,
+	PlanOutput:                  "",
+	PlanOutputFormat:            "json",
+	ApplyPlan:                   "",
+	OwnershipReport:             "",
+	ServeOwnershipReport:        false,
+	AuditLog:                    "",
+	EmitEvents:                  false,
+	QuarantineBackoff:           0,
+	QuarantineMaxBackoff:        time.Hour,
+	LeaderElect:                 false,
+	LeaderElectionNamespace:     "",
+	LeaderElectionLeaseName:     "external-dns",
+	LeaderElectionLeaseDuration: 15 * time.Second,
+	LeaderElectionRenewDeadline: 10 * time.Second,
+	LeaderElectionRetryPeriod:   2 * time.Second,
 	UpdateEvents:                false,
 	LogFormat:                   "text",
 	MetricsAddress:              ":7979",
@@ -325,7 +413,7 @@ var defaultConfig = &Config{
 	TransIPAccountName:          "",
 	TransIPPrivateKeyFile:       "",
 	DigitalOceanAPIPageSize:     50,
-	ManagedDNSRecordTypes:       []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	ManagedDNSRecordTypes:       []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
 	GoDaddyAPIKey:               "",
 	GoDaddySecretKey:            "",
 	GoDaddyTTL:                  600,
@@ -418,14 +506,21 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("publish-internal-services", "Allow external-dns to publish DNS records for ClusterIP services (optional)").BoolVar(&cfg.PublishInternal)
 	app.Flag("publish-host-ip", "Allow external-dns to publish host-ip for headless services (optional)").BoolVar(&cfg.PublishHostIP)
 	app.Flag("always-publish-not-ready-addresses", "Always publish also not ready addresses for headless services (optional)").BoolVar(&cfg.AlwaysPublishNotReadyAddresses)
+	app.Flag("node-address-type", "The types of node addresses to publish, valid only when using node source; specify multiple times for multiple types (default: the ExternalIP, or the InternalIP if there is none; options: ExternalIP, InternalIP)").EnumsVar(&cfg.NodeAddressTypes, "ExternalIP", "InternalIP")
+	app.Flag("node-address-family", "Only publish node addresses of this family, valid only when using node source (default: both; options: ipv4, ipv6)").Default(defaultConfig.NodeAddressFamily).EnumVar(&cfg.NodeAddressFamily, "", "ipv4", "ipv6")
+	app.Flag("node-exclude-unready", "Skip nodes which are not ready, cordoned or being drained by the cluster autoscaler, valid only when using node source (default: disabled)").BoolVar(&cfg.NodeExcludeUnready)
+	app.Flag("node-aggregate-fqdn", "A DNS name to publish with the addresses of all nodes, in addition to the name of every node, valid only when using node source (optional)").Default(defaultConfig.NodeAggregateFQDN).StringVar(&cfg.NodeAggregateFQDN)
 	app.Flag("connector-source-server", "The server to connect for connector source, valid only when using connector source").Default(defaultConfig.ConnectorSourceServer).StringVar(&cfg.ConnectorSourceServer)
+	app.Flag("connector-source-poll-interval", "The interval in which the connector source server is polled for changes when --events is enabled, every poll connects to the server; 0s disables polling (default: 1m)").Default(defaultConfig.ConnectorSourcePollInterval.String()).DurationVar(&cfg.ConnectorSourcePollInterval)
 	app.Flag("crd-source-apiversion", "API version of the CRD for crd source, e.g. `externaldns.k8s.io/v1alpha1`, valid only when using crd source").Default(defaultConfig.CRDSourceAPIVersion).StringVar(&cfg.CRDSourceAPIVersion)
 	app.Flag("crd-source-kind", "Kind of the CRD for the crd source in API group and version specified by crd-source-apiversion").Default(defaultConfig.CRDSourceKind).StringVar(&cfg.CRDSourceKind)
 	app.Flag("service-type-filter", "The service types to take care about (default: all, expected: ClusterIP, NodePort, LoadBalancer or ExternalName)").StringsVar(&cfg.ServiceTypeFilter)
-	app.Flag("managed-record-types", "Comma separated list of record types to manage (default: A, CNAME) (supported records: CNAME, A, NS").Default("A", "CNAME").StringsVar(&cfg.ManagedDNSRecordTypes)
+	app.Flag("managed-record-types", "Comma separated list of record types to manage (default: A, AAAA, CNAME) (supported records: CNAME, A, AAAA, NS, MX, SRV, CAA, TXT, PTR)").Default("A", "AAAA", "CNAME").StringsVar(&cfg.ManagedDNSRecordTypes)
 	app.Flag("default-targets", "Set globally default IP address that will apply as a target instead of source addresses. Specify multiple times for multiple targets (optional)").StringsVar(&cfg.DefaultTargets)
 	app.Flag("target-net-filter", "Limit possible targets by a net filter; specify multiple times for multiple possible nets (optional)").StringsVar(&cfg.TargetNetFilter)
 	app.Flag("exclude-target-net", "Exclude target nets (optional)").StringsVar(&cfg.ExcludeTargetNets)
+	app.Flag("create-ptr", "When enabled, create PTR records for the addresses of A and AAAA records in the reverse zones given by --ptr-zone, with any provider (default: disabled)").BoolVar(&cfg.CreatePTR)
+	app.Flag("ptr-zone", "A reverse zone to create PTR records in, e.g. 2.0.192.in-addr.arpa or 8.b.d.0.1.0.0.2.ip6.arpa; specify multiple times for multiple zones (required with --create-ptr)").StringsVar(&cfg.PTRZones)
 
 	// Flags related to providers
 	app.Flag("provider", "The DNS provider where the DNS records will be created (required, options: aws, aws-sd, godaddy, google, azure, azure-dns, azure-private-dns, bluecat, cloudflare, rcodezero, digitalocean, dnsimple, akamai, infoblox, dyn, designate, coredns, skydns, ibmcloud, inmemory, ovh, pdns, oci, exoscale, linode, rfc2136, ns1, transip, vinyldns, rdns, scaleway, vultr, ultradns, gandi, safedns, tencentcloud)").Required().PlaceHolder("provider").EnumVar(&cfg.Provider, "aws", "aws-sd", "google", "azure", "azure-dns", "azure-private-dns", "alibabacloud", "cloudflare", "rcodezero", "digitalocean", "dnsimple", "akamai", "infoblox", "dyn", "designate", "coredns", "skydns", "ibmcloud", "inmemory", "ovh", "pdns", "oci", "exoscale", "linode", "rfc2136", "ns1", "transip", "vinyldns", "rdns", "scaleway", "vultr", "ultradns", "godaddy", "bluecat", "gandi", "safedns", "tencentcloud", "pihole", "plural")
@@ -553,20 +648,57 @@ func (cfg *Config) ParseFlags(args []string) error {
 
 	// Flags related to policies
 	app.Flag("policy", "Modify how DNS records are synchronized between sources and providers (default: sync, options: sync, upsert-only, create-only)").Default(defaultConfig.Policy).EnumVar(&cfg.Policy, "sync", "upsert-only", "create-only")
+	app.Flag("policy-config", "When using per-domain policies, the path to a YAML or JSON file assigning a policy to each domain; records not matching any domain are subject to --policy unless the file sets a default (optional)").Default(defaultConfig.PolicyConfig).StringVar(&cfg.PolicyConfig)
+	app.Flag("conflict-resolution", "Modify how a DNS name claimed by multiple resources is assigned (default: per-resource, options: per-resource, oldest, priority, refuse, merge)").Default(defaultConfig.ConflictResolution).EnumVar(&cfg.ConflictResolution, "per-resource", "oldest", "priority", "refuse", "merge")
+	app.Flag("max-deletes", "Abort a synchronization which would delete more than this number of owned records (default: 0, disabled)").Default(strconv.Itoa(defaultConfig.MaxDeletes)).IntVar(&cfg.MaxDeletes)
+	app.Flag("max-delete-percentage", "Abort a synchronization which would delete more than this percentage of owned records (default: 0, disabled)").Default(strconv.FormatFloat(defaultConfig.MaxDeletePercentage, 'f', -1, 64)).Float64Var(&cfg.MaxDeletePercentage)
 
 	// Flags related to the registry
-	app.Flag("registry", "The registry implementation to use to keep track of DNS record ownership (default: txt, options: txt, noop, aws-sd)").Default(defaultConfig.Registry).EnumVar(&cfg.Registry, "txt", "noop", "aws-sd")
+	app.Flag("registry", "The registry implementation to use to keep track of DNS record ownership (default: txt, options: txt, noop, aws-sd, configmap)").Default(defaultConfig.Registry).EnumVar(&cfg.Registry, "txt", "noop", "aws-sd", "configmap")
 	app.Flag("txt-owner-id", "When using the TXT registry, a name that identifies this instance of ExternalDNS (default: default)").Default(defaultConfig.TXTOwnerID).StringVar(&cfg.TXTOwnerID)
 	app.Flag("txt-prefix", "When using the TXT registry, a custom string that's prefixed to each ownership DNS record (optional). Could contain record type template like '%{record_type}-prefix-'. Mutual exclusive with txt-suffix!").Default(defaultConfig.TXTPrefix).StringVar(&cfg.TXTPrefix)
 	app.Flag("txt-suffix", "When using the TXT registry, a custom string that's suffixed to the host portion of each ownership DNS record (optional). Could contain record type template like '-%{record_type}-suffix'. Mutual exclusive with txt-prefix!").Default(defaultConfig.TXTSuffix).StringVar(&cfg.TXTSuffix)
 	app.Flag("txt-wildcard-replacement", "When using the TXT registry, a custom string that's used instead of an asterisk for TXT records corresponding to wildcard DNS records (optional)").Default(defaultConfig.TXTWildcardReplacement).StringVar(&cfg.TXTWildcardReplacement)
+	app.Flag("txt-encryption-key-file", "When using the TXT registry, the path to a file containing a base64 encoded AES key of 16, 24 or 32 bytes encrypting the labels of the ownership records (optional)").Default(defaultConfig.TXTEncryptionKeyFile).StringVar(&cfg.TXTEncryptionKeyFile)
+	app.Flag("txt-signing-key-file", "When using the TXT registry, the path to a file containing a base64 encoded HMAC key signing the labels of the ownership records (optional)").Default(defaultConfig.TXTSigningKeyFile).StringVar(&cfg.TXTSigningKeyFile)
+	app.Flag("txt-keys-secret", "When using the TXT registry, the Secret containing the base64 encoded keys in encryption-key and signing-key in namespace/name format, as alternative to the key files (optional)").Default(defaultConfig.TXTKeysSecret).StringVar(&cfg.TXTKeysSecret)
+	app.Flag("txt-accept-unprotected", "When using the TXT registry with keys, accepts plain ownership records and protects the ones of this instance, use to migrate existing records (default: disabled)").BoolVar(&cfg.TXTAcceptUnprotected)
+	app.Flag("txt-previous-owner-ids", "When using the TXT registry, the owner IDs whose records are adopted by this instance, i.e. their ownership records are rewritten to --txt-owner-id; specify multiple times or comma separated for multiple owner IDs (optional)").StringsVar(&cfg.TXTPreviousOwnerIDs)
+	app.Flag("txt-adopt-regex", "When using the TXT registry, adopt the existing records without owner whose DNS names match this regex, as the external-dns.alpha.kubernetes.io/adopt annotation does for the records of a resource (optional)").Default(defaultConfig.TXTAdoptRegex.String()).RegexpVar(&cfg.TXTAdoptRegex)
+	app.Flag("txt-gc-interval", "When using the TXT registry, the minimum interval between the deletions of orphaned ownership records of this instance, i.e. whose records do not exist anymore (default: disabled)").Default(defaultConfig.TXTGCInterval.String()).DurationVar(&cfg.TXTGCInterval)
+	app.Flag("txt-gc-max-deletions", "When using the TXT registry, the maximum number of orphaned ownership records deleted at once, and of ownership records in the old format deleted at once by --txt-format=typed, 0 means unlimited (default: 10)").Default(strconv.Itoa(defaultConfig.TXTGCMaxDeletions)).IntVar(&cfg.TXTGCMaxDeletions)
+	app.Flag("txt-gc-once", "When using the TXT registry, deletes the orphaned ownership records of this instance once and exits without synchronizing the records (default: disabled)").BoolVar(&cfg.TXTGCOnce)
+	app.Flag("txt-format", "When using the TXT registry, the format of the ownership records written: the old format (legacy), both formats (dual) or the new format containing the record type (typed) which deletes the ownership records in the old format; records are read in all formats (default: dual)").Default(defaultConfig.TXTFormat).EnumVar(&cfg.TXTFormat, "legacy", "dual", "typed")
+	app.Flag("txt-name-mapper", "When using the TXT registry, how the ownership records are named: after their records with the record type, prefix or suffix added (affix) or with the first label replaced by a hash, valid for wildcards and long names as well (hashed); hashed cannot be combined with --txt-prefix, --txt-suffix or --txt-wildcard-replacement (default: affix)").Default(defaultConfig.TXTNameMapper).EnumVar(&cfg.TXTNameMapper, "affix", "hashed")
+	app.Flag("txt-additional-owner-ids", "When using the TXT registry, further owner IDs this instance manages records on behalf of in addition to --txt-owner-id; specify multiple times or comma separated for multiple owner IDs (optional)").StringsVar(&cfg.TXTAdditionalOwnerIDs)
+	app.Flag("txt-owner-id-mapping", "When using the TXT registry, assigns the records of the matching resources to an owner ID in the format <owner-id>=<kind>/<namespace>/<name> with wildcards, e.g. team-a=*/team-a/*; the first matching mapping applies, the external-dns.alpha.kubernetes.io/owner-id annotation takes precedence; specify multiple times for multiple mappings (optional)").StringsVar(&cfg.TXTOwnerIDMappings)
+	app.Flag("configmap-registry-name", "When using the ConfigMap registry, the name of the ConfigMaps storing the ownership, suffixed with their shard (default: external-dns-ownership)").Default(defaultConfig.ConfigMapRegistryName).StringVar(&cfg.ConfigMapRegistryName)
+	app.Flag("configmap-registry-namespace", "When using the ConfigMap registry, the namespace of the ConfigMaps storing the ownership (default: the namespace ExternalDNS runs in)").Default(defaultConfig.ConfigMapRegistryNamespace).StringVar(&cfg.ConfigMapRegistryNamespace)
+	app.Flag("configmap-registry-shards", "When using the ConfigMap registry, the number of ConfigMaps the ownership is distributed over (default: 4)").Default(strconv.Itoa(defaultConfig.ConfigMapRegistryShards)).IntVar(&cfg.ConfigMapRegistryShards)
 
 	// Flags related to the main control loop
 	app.Flag("txt-cache-interval", "The interval between cache synchronizations in duration format (default: disabled)").Default(defaultConfig.TXTCacheInterval.String()).DurationVar(&cfg.TXTCacheInterval)
+	app.Flag("txt-cache-file", "When using the TXT registry with a cache interval, persists the cache in the given file so that restarts start with a warm cache (default: disabled)").Default(defaultConfig.TXTCacheFile).StringVar(&cfg.TXTCacheFile)
+	app.Flag("txt-cache-configmap", "When using the TXT registry with a cache interval, persists the cache in the given ConfigMap, as [namespace/]name, so that restarts and new leaders start with a warm cache (default: disabled)").Default(defaultConfig.TXTCacheConfigMap).StringVar(&cfg.TXTCacheConfigMap)
 	app.Flag("interval", "The interval between two consecutive synchronizations in duration format (default: 1m)").Default(defaultConfig.Interval.String()).DurationVar(&cfg.Interval)
 	app.Flag("min-event-sync-interval", "The minimum interval between two consecutive synchronizations triggered from kubernetes events in duration format (default: 5s)").Default(defaultConfig.MinEventSyncInterval.String()).DurationVar(&cfg.MinEventSyncInterval)
 	app.Flag("once", "When enabled, exits the synchronization loop after the first iteration (default: disabled)").BoolVar(&cfg.Once)
 	app.Flag("dry-run", "When enabled, prints DNS record changes rather than actually performing them (default: disabled)").BoolVar(&cfg.DryRun)
+	app.Flag("plan-output", "When enabled, writes the calculated changes of each synchronization to the given file, use - for stdout (default: disabled)").Default(defaultConfig.PlanOutput).StringVar(&cfg.PlanOutput)
+	app.Flag("plan-output-format", "The format in which the calculated changes are written (default: json, options: json, yaml)").Default(defaultConfig.PlanOutputFormat).EnumVar(&cfg.PlanOutputFormat, "json", "yaml")
+	app.Flag("apply-plan", "When enabled, applies the changes of a file previously written with --plan-output and exits; refuses to apply if the current records do not match the plan anymore (default: disabled)").Default(defaultConfig.ApplyPlan).StringVar(&cfg.ApplyPlan)
+	app.Flag("ownership-report", "When enabled, prints the owner of every record in the given format and exits without changing any records (default: disabled, options: table, json)").Default(defaultConfig.OwnershipReport).EnumVar(&cfg.OwnershipReport, "", "table", "json")
+	app.Flag("serve-ownership-report", "When enabled, serves the owner of every record read by the last synchronization on /ownership of the metrics address; it discloses all records of the zones, do not expose it publicly (default: disabled)").BoolVar(&cfg.ServeOwnershipReport)
+	app.Flag("audit-log", "When enabled, appends every record change submitted to the provider as a JSON line to the given file, use - for stdout (default: disabled)").Default(defaultConfig.AuditLog).StringVar(&cfg.AuditLog)
+	app.Flag("emit-events", "When enabled, emits Kubernetes Events about created, updated, deleted, conflicted and failed records on the resources they originate from and maintains the status of DNSEndpoints (default: disabled)").BoolVar(&cfg.EmitEvents)
+	app.Flag("quarantine-backoff", "Skips changes of records the provider failed to apply for this duration instead of failing the whole synchronization, the duration doubles with each further failure; only supported by providers reporting failures per record, e.g. 1m (default: disabled)").Default(defaultConfig.QuarantineBackoff.String()).DurationVar(&cfg.QuarantineBackoff)
+	app.Flag("quarantine-max-backoff", "The maximum duration for which changes of a failing record are skipped (default: 1h)").Default(defaultConfig.QuarantineMaxBackoff.String()).DurationVar(&cfg.QuarantineMaxBackoff)
+	app.Flag("leader-elect", "When enabled, only the replica holding a Kubernetes Lease synchronizes while the others stand by to take over (default: disabled)").BoolVar(&cfg.LeaderElect)
+	app.Flag("leader-election-namespace", "The namespace of the leader election Lease (default: the namespace ExternalDNS runs in)").Default(defaultConfig.LeaderElectionNamespace).StringVar(&cfg.LeaderElectionNamespace)
+	app.Flag("leader-election-lease-name", "The name of the leader election Lease (default: external-dns)").Default(defaultConfig.LeaderElectionLeaseName).StringVar(&cfg.LeaderElectionLeaseName)
+	app.Flag("leader-election-lease-duration", "The duration standby replicas wait before they take over a Lease which is not renewed (default: 15s)").Default(defaultConfig.LeaderElectionLeaseDuration.String()).DurationVar(&cfg.LeaderElectionLeaseDuration)
+	app.Flag("leader-election-renew-deadline", "The duration the leader retries to renew the Lease before it stops synchronizing (default: 10s)").Default(defaultConfig.LeaderElectionRenewDeadline.String()).DurationVar(&cfg.LeaderElectionRenewDeadline)
+	app.Flag("leader-election-retry-period", "The duration between two attempts to acquire or renew the Lease (default: 2s)").Default(defaultConfig.LeaderElectionRetryPeriod.String()).DurationVar(&cfg.LeaderElectionRetryPeriod)
 	app.Flag("events", "When enabled, in addition to running every interval, the reconciliation loop will get triggered when supported sources change (default: disabled)").BoolVar(&cfg.UpdateEvents)
 
 	// Miscellaneous flags
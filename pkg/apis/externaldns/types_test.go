@@ -100,19 +100,34 @@ var (
 		PDNSServer:                  "http://localhost:8081",
 		PDNSAPIKey:                  "",
 		Policy:                      "sync",
+		ConflictResolution:          "per-resource",
 		Registry:                    "txt",
 		TXTOwnerID:                  "default",
 		TXTPrefix:                   "",
 		TXTCacheInterval:            0,
+		TXTAdoptRegex:               regexp.MustCompile(""),
+		TXTGCMaxDeletions:           10,
+		TXTFormat:                   "dual",
+		TXTNameMapper:               "affix",
+		ConfigMapRegistryName:       "external-dns-ownership",
+		ConfigMapRegistryShards:     4,
 		Interval:                    time.Minute,
 		MinEventSyncInterval:        5 * time.Second,
 		Once:                        false,
 		DryRun:                      false,
+		PlanOutputFormat:            "json",
+		QuarantineBackoff:           0,
+		QuarantineMaxBackoff:        time.Hour,
+		LeaderElectionLeaseName:     "external-dns",
+		LeaderElectionLeaseDuration: 15 * time.Second,
+		LeaderElectionRenewDeadline: 10 * time.Second,
+		LeaderElectionRetryPeriod:   2 * time.Second,
 		UpdateEvents:                false,
 		LogFormat:                   "text",
 		MetricsAddress:              ":7979",
 		LogLevel:                    logrus.InfoLevel.String(),
 		ConnectorSourceServer:       "localhost:8080",
+		ConnectorSourcePollInterval: time.Minute,
 		ExoscaleEndpoint:            "https://api.exoscale.ch/dns",
 		ExoscaleAPIKey:              "",
 		ExoscaleAPISecret:           "",
@@ -122,7 +137,7 @@ var (
 		TransIPAccountName:          "",
 		TransIPPrivateKeyFile:       "",
 		DigitalOceanAPIPageSize:     50,
-		ManagedDNSRecordTypes:       []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+		ManagedDNSRecordTypes:       []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
 		RFC2136BatchChangeSize:      50,
 		OCPRouterName:               "default",
 		IBMCloudProxied:             false,
@@ -158,6 +173,12 @@ var (
 		ZoneIDFilter:                []string{"/hostedzone/ZTST1", "/hostedzone/ZTST2"},
 		TargetNetFilter:             []string{"10.0.0.0/9", "10.1.0.0/9"},
 		ExcludeTargetNets:           []string{"1.0.0.0/9", "1.1.0.0/9"},
+		NodeAddressTypes:            []string{"InternalIP", "ExternalIP"},
+		NodeAddressFamily:           "ipv6",
+		NodeExcludeUnready:          true,
+		NodeAggregateFQDN:           "nodes.example.org",
+		CreatePTR:                   true,
+		PTRZones:                    []string{"2.0.192.in-addr.arpa", "8.b.d.0.1.0.0.2.ip6.arpa"},
 		AlibabaCloudConfigFile:      "/etc/kubernetes/alibaba-cloud.json",
 		AWSZoneType:                 "private",
 		AWSZoneTagFilter:            []string{"tag=foo"},
@@ -209,19 +230,57 @@ var (
 		TLSClientCert:               "/path/to/cert.pem",
 		TLSClientCertKey:            "/path/to/key.pem",
 		Policy:                      "upsert-only",
+		PolicyConfig:                "/etc/external-dns/policies.yaml",
+		ConflictResolution:          "oldest",
+		MaxDeletes:                  10,
+		MaxDeletePercentage:         12.5,
 		Registry:                    "noop",
 		TXTOwnerID:                  "owner-1",
 		TXTPrefix:                   "associated-txt-record",
 		TXTCacheInterval:            12 * time.Hour,
+		TXTCacheFile:                "/var/cache/external-dns/records",
+		TXTCacheConfigMap:           "external-dns/records-cache",
+		ConfigMapRegistryName:       "dns-ownership",
+		ConfigMapRegistryNamespace:  "external-dns",
+		ConfigMapRegistryShards:     8,
+		TXTEncryptionKeyFile:        "/etc/external-dns/encryption-key",
+		TXTSigningKeyFile:           "/etc/external-dns/signing-key",
+		TXTKeysSecret:               "external-dns/txt-keys",
+		TXTAcceptUnprotected:        true,
+		TXTPreviousOwnerIDs:         []string{"owner-0", "owner-00"},
+		TXTAdoptRegex:               regexp.MustCompile(`^legacy-.*\.example\.org$`),
+		TXTGCInterval:               time.Hour,
+		TXTGCMaxDeletions:           50,
+		TXTGCOnce:                   true,
+		TXTFormat:                   "typed",
+		TXTNameMapper:               "hashed",
+		TXTAdditionalOwnerIDs:       []string{"owner-2"},
+		TXTOwnerIDMappings:          []string{"owner-2=*/team-2/*", "owner-1=service/*/*"},
 		Interval:                    10 * time.Minute,
 		MinEventSyncInterval:        50 * time.Second,
 		Once:                        true,
 		DryRun:                      true,
+		PlanOutput:                  "/tmp/plan.yaml",
+		PlanOutputFormat:            "yaml",
+		ApplyPlan:                   "/tmp/approved.yaml",
+		OwnershipReport:             "json",
+		ServeOwnershipReport:        true,
+		AuditLog:                    "/var/log/external-dns/audit.log",
+		EmitEvents:                  true,
+		QuarantineBackoff:           30 * time.Second,
+		QuarantineMaxBackoff:        15 * time.Minute,
+		LeaderElect:                 true,
+		LeaderElectionNamespace:     "kube-system",
+		LeaderElectionLeaseName:     "external-dns-public",
+		LeaderElectionLeaseDuration: 30 * time.Second,
+		LeaderElectionRenewDeadline: 20 * time.Second,
+		LeaderElectionRetryPeriod:   5 * time.Second,
 		UpdateEvents:                true,
 		LogFormat:                   "json",
 		MetricsAddress:              "127.0.0.1:9099",
 		LogLevel:                    logrus.DebugLevel.String(),
 		ConnectorSourceServer:       "localhost:8081",
+		ConnectorSourcePollInterval: 30 * time.Second,
 		ExoscaleEndpoint:            "https://api.foo.ch/dns",
 		ExoscaleAPIKey:              "1",
 		ExoscaleAPISecret:           "2",
@@ -335,6 +394,14 @@ func TestParseFlags(t *testing.T) {
 				"--target-net-filter=10.1.0.0/9",
 				"--exclude-target-net=1.0.0.0/9",
 				"--exclude-target-net=1.1.0.0/9",
+				"--create-ptr",
+				"--ptr-zone=2.0.192.in-addr.arpa",
+				"--ptr-zone=8.b.d.0.1.0.0.2.ip6.arpa",
+				"--node-address-type=InternalIP",
+				"--node-address-type=ExternalIP",
+				"--node-address-family=ipv6",
+				"--node-exclude-unready",
+				"--node-aggregate-fqdn=nodes.example.org",
 				"--aws-zone-type=private",
 				"--aws-zone-tags=tag=foo",
 				"--aws-assume-role=some-other-role",
@@ -347,19 +414,59 @@ func TestParseFlags(t *testing.T) {
 				"--aws-sd-service-cleanup",
 				"--no-aws-evaluate-target-health",
 				"--policy=upsert-only",
+				"--policy-config=/etc/external-dns/policies.yaml",
+				"--conflict-resolution=oldest",
+				"--max-deletes=10",
+				"--max-delete-percentage=12.5",
 				"--registry=noop",
 				"--txt-owner-id=owner-1",
 				"--txt-prefix=associated-txt-record",
 				"--txt-cache-interval=12h",
+				"--txt-cache-file=/var/cache/external-dns/records",
+				"--txt-cache-configmap=external-dns/records-cache",
+				"--configmap-registry-name=dns-ownership",
+				"--configmap-registry-namespace=external-dns",
+				"--configmap-registry-shards=8",
+				"--txt-encryption-key-file=/etc/external-dns/encryption-key",
+				"--txt-signing-key-file=/etc/external-dns/signing-key",
+				"--txt-keys-secret=external-dns/txt-keys",
+				"--txt-accept-unprotected",
+				"--txt-previous-owner-ids=owner-0",
+				"--txt-previous-owner-ids=owner-00",
+				"--txt-adopt-regex=^legacy-.*\\.example\\.org$",
+				"--txt-gc-interval=1h",
+				"--txt-gc-max-deletions=50",
+				"--txt-gc-once",
+				"--txt-format=typed",
+				"--txt-name-mapper=hashed",
+				"--txt-additional-owner-ids=owner-2",
+				"--txt-owner-id-mapping=owner-2=*/team-2/*",
+				"--txt-owner-id-mapping=owner-1=service/*/*",
 				"--interval=10m",
 				"--min-event-sync-interval=50s",
 				"--once",
 				"--dry-run",
+				"--plan-output=/tmp/plan.yaml",
+				"--plan-output-format=yaml",
+				"--apply-plan=/tmp/approved.yaml",
+				"--ownership-report=json",
+				"--serve-ownership-report",
+				"--audit-log=/var/log/external-dns/audit.log",
+				"--emit-events",
+				"--quarantine-backoff=30s",
+				"--quarantine-max-backoff=15m",
+				"--leader-elect",
+				"--leader-election-namespace=kube-system",
+				"--leader-election-lease-name=external-dns-public",
+				"--leader-election-lease-duration=30s",
+				"--leader-election-renew-deadline=20s",
+				"--leader-election-retry-period=5s",
 				"--events",
 				"--log-format=json",
 				"--metrics-address=127.0.0.1:9099",
 				"--log-level=debug",
 				"--connector-source-server=localhost:8081",
+				"--connector-source-poll-interval=30s",
 				"--exoscale-endpoint=https://api.foo.ch/dns",
 				"--exoscale-apikey=1",
 				"--exoscale-apisecret=2",
@@ -443,6 +550,12 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_REGEX_DOMAIN_EXCLUSION":          "xapi\\.(example\\.org|company\\.com)$",
 				"EXTERNAL_DNS_TARGET_NET_FILTER":               "10.0.0.0/9\n10.1.0.0/9",
 				"EXTERNAL_DNS_EXCLUDE_TARGET_NET":              "1.0.0.0/9\n1.1.0.0/9",
+				"EXTERNAL_DNS_CREATE_PTR":                      "1",
+				"EXTERNAL_DNS_PTR_ZONE":                        "2.0.192.in-addr.arpa\n8.b.d.0.1.0.0.2.ip6.arpa",
+				"EXTERNAL_DNS_NODE_ADDRESS_TYPE":               "InternalIP\nExternalIP",
+				"EXTERNAL_DNS_NODE_ADDRESS_FAMILY":             "ipv6",
+				"EXTERNAL_DNS_NODE_EXCLUDE_UNREADY":            "1",
+				"EXTERNAL_DNS_NODE_AGGREGATE_FQDN":             "nodes.example.org",
 				"EXTERNAL_DNS_PDNS_SERVER":                     "http://ns.example.com:8081",
 				"EXTERNAL_DNS_PDNS_API_KEY":                    "some-secret-key",
 				"EXTERNAL_DNS_PDNS_TLS_ENABLED":                "1",
@@ -464,19 +577,57 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_AWS_ZONES_CACHE_DURATION":        "10s",
 				"EXTERNAL_DNS_AWS_SD_SERVICE_CLEANUP":          "true",
 				"EXTERNAL_DNS_POLICY":                          "upsert-only",
+				"EXTERNAL_DNS_POLICY_CONFIG":                   "/etc/external-dns/policies.yaml",
+				"EXTERNAL_DNS_CONFLICT_RESOLUTION":             "oldest",
+				"EXTERNAL_DNS_MAX_DELETES":                     "10",
+				"EXTERNAL_DNS_MAX_DELETE_PERCENTAGE":           "12.5",
 				"EXTERNAL_DNS_REGISTRY":                        "noop",
 				"EXTERNAL_DNS_TXT_OWNER_ID":                    "owner-1",
 				"EXTERNAL_DNS_TXT_PREFIX":                      "associated-txt-record",
 				"EXTERNAL_DNS_TXT_CACHE_INTERVAL":              "12h",
+				"EXTERNAL_DNS_TXT_CACHE_FILE":                  "/var/cache/external-dns/records",
+				"EXTERNAL_DNS_TXT_CACHE_CONFIGMAP":             "external-dns/records-cache",
+				"EXTERNAL_DNS_CONFIGMAP_REGISTRY_NAME":         "dns-ownership",
+				"EXTERNAL_DNS_CONFIGMAP_REGISTRY_NAMESPACE":    "external-dns",
+				"EXTERNAL_DNS_CONFIGMAP_REGISTRY_SHARDS":       "8",
+				"EXTERNAL_DNS_TXT_ENCRYPTION_KEY_FILE":         "/etc/external-dns/encryption-key",
+				"EXTERNAL_DNS_TXT_SIGNING_KEY_FILE":            "/etc/external-dns/signing-key",
+				"EXTERNAL_DNS_TXT_KEYS_SECRET":                 "external-dns/txt-keys",
+				"EXTERNAL_DNS_TXT_ACCEPT_UNPROTECTED":          "1",
+				"EXTERNAL_DNS_TXT_PREVIOUS_OWNER_IDS":          "owner-0\nowner-00",
+				"EXTERNAL_DNS_TXT_ADOPT_REGEX":                 "^legacy-.*\\.example\\.org$",
+				"EXTERNAL_DNS_TXT_GC_INTERVAL":                 "1h",
+				"EXTERNAL_DNS_TXT_GC_MAX_DELETIONS":            "50",
+				"EXTERNAL_DNS_TXT_GC_ONCE":                     "1",
+				"EXTERNAL_DNS_TXT_FORMAT":                      "typed",
+				"EXTERNAL_DNS_TXT_NAME_MAPPER":                 "hashed",
+				"EXTERNAL_DNS_TXT_ADDITIONAL_OWNER_IDS":        "owner-2",
+				"EXTERNAL_DNS_TXT_OWNER_ID_MAPPING":            "owner-2=*/team-2/*\nowner-1=service/*/*",
 				"EXTERNAL_DNS_INTERVAL":                        "10m",
 				"EXTERNAL_DNS_MIN_EVENT_SYNC_INTERVAL":         "50s",
 				"EXTERNAL_DNS_ONCE":                            "1",
 				"EXTERNAL_DNS_DRY_RUN":                         "1",
+				"EXTERNAL_DNS_PLAN_OUTPUT":                     "/tmp/plan.yaml",
+				"EXTERNAL_DNS_PLAN_OUTPUT_FORMAT":              "yaml",
+				"EXTERNAL_DNS_APPLY_PLAN":                      "/tmp/approved.yaml",
+				"EXTERNAL_DNS_OWNERSHIP_REPORT":                "json",
+				"EXTERNAL_DNS_SERVE_OWNERSHIP_REPORT":          "1",
+				"EXTERNAL_DNS_AUDIT_LOG":                       "/var/log/external-dns/audit.log",
+				"EXTERNAL_DNS_EMIT_EVENTS":                     "1",
+				"EXTERNAL_DNS_QUARANTINE_BACKOFF":              "30s",
+				"EXTERNAL_DNS_QUARANTINE_MAX_BACKOFF":          "15m",
+				"EXTERNAL_DNS_LEADER_ELECT":                    "1",
+				"EXTERNAL_DNS_LEADER_ELECTION_NAMESPACE":       "kube-system",
+				"EXTERNAL_DNS_LEADER_ELECTION_LEASE_NAME":      "external-dns-public",
+				"EXTERNAL_DNS_LEADER_ELECTION_LEASE_DURATION":  "30s",
+				"EXTERNAL_DNS_LEADER_ELECTION_RENEW_DEADLINE":  "20s",
+				"EXTERNAL_DNS_LEADER_ELECTION_RETRY_PERIOD":    "5s",
 				"EXTERNAL_DNS_EVENTS":                          "1",
 				"EXTERNAL_DNS_LOG_FORMAT":                      "json",
 				"EXTERNAL_DNS_METRICS_ADDRESS":                 "127.0.0.1:9099",
 				"EXTERNAL_DNS_LOG_LEVEL":                       "debug",
 				"EXTERNAL_DNS_CONNECTOR_SOURCE_SERVER":         "localhost:8081",
+				"EXTERNAL_DNS_CONNECTOR_SOURCE_POLL_INTERVAL":  "30s",
 				"EXTERNAL_DNS_EXOSCALE_ENDPOINT":               "https://api.foo.ch/dns",
 				"EXTERNAL_DNS_EXOSCALE_APIKEY":                 "1",
 				"EXTERNAL_DNS_EXOSCALE_APISECRET":              "2",
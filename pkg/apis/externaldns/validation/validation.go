@@ -19,6 +19,7 @@ package validation
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/labels"
 
@@ -112,6 +113,73 @@ func ValidateConfig(cfg *externaldns.Config) error {
 		return errors.New("txt-prefix and txt-suffix are mutual exclusive")
 	}
 
+	if cfg.MaxDeletes < 0 {
+		return errors.New("--max-deletes cannot be negative")
+	}
+
+	if cfg.MaxDeletePercentage < 0 || cfg.MaxDeletePercentage > 100 {
+		return errors.New("--max-delete-percentage must be between 0 and 100")
+	}
+
+	if cfg.TXTNameMapper == "hashed" && (cfg.TXTPrefix != "" || cfg.TXTSuffix != "" || cfg.TXTWildcardReplacement != "") {
+		return errors.New("--txt-name-mapper=hashed cannot be combined with --txt-prefix, --txt-suffix or --txt-wildcard-replacement")
+	}
+
+	if cfg.TXTCacheFile != "" && cfg.TXTCacheConfigMap != "" {
+		return errors.New("--txt-cache-file and --txt-cache-configmap are mutually exclusive")
+	}
+
+	if (cfg.TXTCacheFile != "" || cfg.TXTCacheConfigMap != "") && cfg.TXTCacheInterval <= 0 {
+		return errors.New("--txt-cache-file and --txt-cache-configmap require --txt-cache-interval")
+	}
+
+	if len(strings.Split(cfg.TXTCacheConfigMap, "/")) > 2 {
+		return errors.New("--txt-cache-configmap must be in [namespace/]name format")
+	}
+
+	if cfg.TXTGCMaxDeletions < 0 {
+		return errors.New("--txt-gc-max-deletions must not be negative")
+	}
+
+	if cfg.TXTGCOnce && cfg.Registry != "txt" {
+		return errors.New("--txt-gc-once requires the TXT registry")
+	}
+
+	if cfg.OwnershipReport != "" && (cfg.ApplyPlan != "" || cfg.TXTGCOnce) {
+		return errors.New("--ownership-report cannot be combined with --apply-plan or --txt-gc-once")
+	}
+
+	if cfg.TXTKeysSecret != "" && len(strings.Split(cfg.TXTKeysSecret, "/")) != 2 {
+		return errors.New("--txt-keys-secret must be in namespace/name format")
+	}
+
+	if cfg.TXTKeysSecret != "" && (cfg.TXTEncryptionKeyFile != "" || cfg.TXTSigningKeyFile != "") {
+		return errors.New("--txt-keys-secret cannot be combined with --txt-encryption-key-file or --txt-signing-key-file")
+	}
+
+	if cfg.CreatePTR && len(cfg.PTRZones) == 0 {
+		return errors.New("--create-ptr requires at least one --ptr-zone")
+	}
+
+	for _, zone := range cfg.PTRZones {
+		zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+		if !strings.HasSuffix(zone, ".in-addr.arpa") && !strings.HasSuffix(zone, ".ip6.arpa") {
+			return fmt.Errorf("--ptr-zone %s is not a reverse zone in in-addr.arpa or ip6.arpa", zone)
+		}
+	}
+
+	if cfg.CreatePTR && cfg.InfobloxCreatePTR {
+		return errors.New("--create-ptr cannot be combined with --infoblox-create-ptr")
+	}
+
+	if cfg.LeaderElect && cfg.LeaderElectionLeaseDuration <= cfg.LeaderElectionRenewDeadline {
+		return errors.New("--leader-election-lease-duration must be greater than --leader-election-renew-deadline")
+	}
+
+	if cfg.LeaderElect && cfg.LeaderElectionRenewDeadline <= cfg.LeaderElectionRetryPeriod {
+		return errors.New("--leader-election-renew-deadline must be greater than --leader-election-retry-period")
+	}
+
 	_, err := labels.Parse(cfg.LabelFilter)
 	if err != nil {
 		return errors.New("--label-filter does not specify a valid label selector")
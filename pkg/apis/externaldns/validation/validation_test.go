@@ -18,6 +18,7 @@ package validation
 
 import (
 	"testing"
+	"time"
 
 	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
 
@@ -50,6 +51,89 @@ func TestValidateFlags(t *testing.T) {
 	cfg = newValidConfig(t)
 	cfg.Provider = ""
 	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.MaxDeletes = -1
+	assert.Error(t, ValidateConfig(cfg))
+
+	for _, percentage := range []float64{-1, 100.5} {
+		cfg = newValidConfig(t)
+		cfg.MaxDeletePercentage = percentage
+		assert.Error(t, ValidateConfig(cfg))
+	}
+
+	cfg = newValidConfig(t)
+	cfg.TXTNameMapper = "hashed"
+	cfg.TXTWildcardReplacement = "wildcard"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.TXTCacheFile = "/var/cache/external-dns/records"
+	assert.Error(t, ValidateConfig(cfg), "a persistent cache requires a cache interval")
+
+	cfg = newValidConfig(t)
+	cfg.TXTCacheInterval = time.Hour
+	cfg.TXTCacheFile = "/var/cache/external-dns/records"
+	cfg.TXTCacheConfigMap = "records-cache"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.TXTCacheInterval = time.Hour
+	cfg.TXTCacheConfigMap = "external-dns/records/cache"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.TXTGCMaxDeletions = -1
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.TXTGCOnce = true
+	cfg.Registry = "noop"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.OwnershipReport = "table"
+	cfg.ApplyPlan = "/tmp/approved.yaml"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.TXTKeysSecret = "txt-keys"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.TXTKeysSecret = "external-dns/txt-keys"
+	cfg.TXTSigningKeyFile = "/etc/external-dns/signing-key"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.CreatePTR = true
+	cfg.PTRZones = []string{"2.0.192.in-addr.arpa", "8.b.d.0.1.0.0.2.ip6.arpa."}
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.CreatePTR = true
+	assert.Error(t, ValidateConfig(cfg), "--create-ptr requires a reverse zone")
+
+	cfg = newValidConfig(t)
+	cfg.CreatePTR = true
+	cfg.PTRZones = []string{"example.org"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.CreatePTR = true
+	cfg.PTRZones = []string{"2.0.192.in-addr.arpa"}
+	cfg.InfobloxCreatePTR = true
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.LeaderElect = true
+	cfg.LeaderElectionRenewDeadline = cfg.LeaderElectionLeaseDuration
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.LeaderElect = true
+	cfg.LeaderElectionRetryPeriod = cfg.LeaderElectionRenewDeadline
+	assert.Error(t, ValidateConfig(cfg))
 }
 
 func newValidConfig(t *testing.T) *externaldns.Config {
@@ -18,17 +18,31 @@ package plan
 
 import (
 	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/external-dns/endpoint"
 )
 
 // ConflictResolver is used to make a decision in case of two or more different kubernetes resources
-// are trying to acquire same DNS name
+// are trying to acquire same DNS name. Returning nil leaves the DNS name untouched
 type ConflictResolver interface {
 	ResolveCreate(candidates []*endpoint.Endpoint) *endpoint.Endpoint
 	ResolveUpdate(current *endpoint.Endpoint, candidates []*endpoint.Endpoint) *endpoint.Endpoint
 }
 
+// ConflictResolvers is a registry of available conflict resolvers.
+var ConflictResolvers = map[string]ConflictResolver{
+	"per-resource": PerResource{},
+	"oldest":       OldestResource{},
+	"priority":     PriorityResource{},
+	"refuse":       RefuseConflict{},
+	"merge":        MergeTargets{},
+}
+
 // PerResource allows only one resource to own a given dns name
 type PerResource struct{}
 
@@ -67,4 +81,221 @@ func (s PerResource) less(x, y *endpoint.Endpoint) bool {
 	return x.Targets.IsLess(y.Targets)
 }
 
-// TODO: with cross-resource/cross-cluster setup alternative variations of ConflictResolver can be used
+// OldestResource allows only one resource to own a given dns name, giving precedence to the resource
+// which was created first
+type OldestResource struct{}
+
+// ResolveCreate picks the endpoint of the oldest resource. Endpoints without a creation timestamp are
+// considered to be the newest ones, ties are broken in the same way as PerResource does
+func (s OldestResource) ResolveCreate(candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	var min *endpoint.Endpoint
+	for _, ep := range candidates {
+		if min == nil || s.less(ep, min) {
+			min = ep
+		}
+	}
+	return min
+}
+
+// ResolveUpdate picks the endpoint of the oldest resource. Being the oldest one, the resource which has
+// already acquired the DNS name keeps it for as long as it exists
+func (s OldestResource) ResolveUpdate(current *endpoint.Endpoint, candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	return s.ResolveCreate(candidates)
+}
+
+// less returns true if endpoint x belongs to an older resource than y
+func (s OldestResource) less(x, y *endpoint.Endpoint) bool {
+	tx, okx := creationTimestamp(x)
+	ty, oky := creationTimestamp(y)
+	switch {
+	case okx && oky && !tx.Equal(ty):
+		return tx.Before(ty)
+	case okx && !oky:
+		return true
+	case !okx && oky:
+		return false
+	}
+	return PerResource{}.less(x, y)
+}
+
+// PriorityResource allows only one resource to own a given dns name, giving precedence to the resource
+// with the highest priority as set by the conflict priority annotation
+type PriorityResource struct{}
+
+// ResolveCreate picks the endpoint with the highest priority. Endpoints without a priority have priority 0,
+// ties are broken in the same way as PerResource does
+func (s PriorityResource) ResolveCreate(candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	var max *endpoint.Endpoint
+	for _, ep := range candidates {
+		if max == nil || priority(ep) > priority(max) || (priority(ep) == priority(max) && PerResource{}.less(ep, max)) {
+			max = ep
+		}
+	}
+	return max
+}
+
+// ResolveUpdate keeps the resource which has already acquired the DNS name unless a resource
+// with a higher priority claims it
+func (s PriorityResource) ResolveUpdate(current *endpoint.Endpoint, candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	max := s.ResolveCreate(candidates)
+	if max == nil {
+		return nil
+	}
+	highest := priority(max)
+	var eligible []*endpoint.Endpoint
+	for _, ep := range candidates {
+		if priority(ep) == highest {
+			eligible = append(eligible, ep)
+		}
+	}
+	return PerResource{}.ResolveUpdate(current, eligible)
+}
+
+// RefuseConflict allows a resource to acquire a dns name only if no other resource claims it.
+// Conflicting names are reported and left untouched
+type RefuseConflict struct{}
+
+// ResolveCreate returns nil if the candidates belong to more than one resource
+func (s RefuseConflict) ResolveCreate(candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	if resources := candidateResources(candidates); len(resources) > 1 {
+		log.Errorf("Refusing to create %s, the name is claimed by multiple resources: %v", candidates[0].DNSName, resources)
+		return nil
+	}
+	return PerResource{}.ResolveCreate(candidates)
+}
+
+// ResolveUpdate keeps the resource which has already acquired the DNS name. If that resource is gone
+// and the candidates belong to more than one resource, nil is returned
+func (s RefuseConflict) ResolveUpdate(current *endpoint.Endpoint, candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	currentResource := current.Labels[endpoint.ResourceLabelKey]
+	resources := candidateResources(candidates)
+	if len(resources) > 1 {
+		for _, r := range resources {
+			if r == currentResource {
+				log.Warnf("DNS name %s is owned by %s but also claimed by: %v", current.DNSName, currentResource, resources)
+				return PerResource{}.ResolveUpdate(current, candidates)
+			}
+		}
+		log.Errorf("Refusing to update %s, the name is claimed by multiple resources: %v", current.DNSName, resources)
+		return nil
+	}
+	return PerResource{}.ResolveUpdate(current, candidates)
+}
+
+// MergeTargets allows several resources to share a given dns name, the targets of all
+// resources are published in a single record set
+type MergeTargets struct{}
+
+// ResolveCreate merges the targets of all candidates having the record type of the "minimal" candidate
+func (s MergeTargets) ResolveCreate(candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	min := PerResource{}.ResolveCreate(candidates)
+	if min == nil {
+		return nil
+	}
+	return s.merge(min, candidates)
+}
+
+// ResolveUpdate merges the targets of all candidates having the record type of the "current" record.
+// Targets of resources which are gone are dropped from the record set. If no candidate has the
+// record type of the "current" record, it falls back to ResolveCreate
+func (s MergeTargets) ResolveUpdate(current *endpoint.Endpoint, candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	var sameType []*endpoint.Endpoint
+	for _, ep := range candidates {
+		if ep.RecordType == current.RecordType {
+			sameType = append(sameType, ep)
+		}
+	}
+	if len(sameType) == 0 {
+		return s.ResolveCreate(candidates)
+	}
+	return s.ResolveCreate(sameType)
+}
+
+// merge returns a copy of base which targets are the union of the targets of all candidates with
+// the same record type. The resource label lists every merged resource, the lowest configured TTL is used
+func (s MergeTargets) merge(base *endpoint.Endpoint, candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	merged := &endpoint.Endpoint{
+		DNSName:          base.DNSName,
+		RecordType:       base.RecordType,
+		SetIdentifier:    base.SetIdentifier,
+		RecordTTL:        base.RecordTTL,
+		Labels:           endpoint.NewLabels(),
+		ProviderSpecific: base.ProviderSpecific,
+	}
+	for k, v := range base.Labels {
+		merged.Labels[k] = v
+	}
+
+	seen := map[string]struct{}{}
+	var group []*endpoint.Endpoint
+	for _, ep := range candidates {
+		if ep.RecordType != base.RecordType {
+			continue
+		}
+		group = append(group, ep)
+		for _, t := range ep.Targets {
+			if _, ok := seen[strings.ToLower(t)]; ok {
+				continue
+			}
+			seen[strings.ToLower(t)] = struct{}{}
+			merged.Targets = append(merged.Targets, t)
+		}
+		if ep.RecordTTL.IsConfigured() && (!merged.RecordTTL.IsConfigured() || ep.RecordTTL < merged.RecordTTL) {
+			merged.RecordTTL = ep.RecordTTL
+		}
+	}
+	sort.Sort(merged.Targets)
+
+	var resources []string
+	for _, r := range candidateResources(group) {
+		if r != "" {
+			resources = append(resources, r)
+		}
+	}
+	if len(resources) > 1 {
+		merged.Labels[endpoint.ResourceLabelKey] = strings.Join(resources, endpoint.ResourceLabelSeparator)
+	}
+	return merged
+}
+
+// candidateResources returns the sorted list of distinct resources the candidates belong to
+func candidateResources(candidates []*endpoint.Endpoint) []string {
+	seen := map[string]struct{}{}
+	resources := []string{}
+	for _, ep := range candidates {
+		r := ep.Labels[endpoint.ResourceLabelKey]
+		if _, ok := seen[r]; ok {
+			continue
+		}
+		seen[r] = struct{}{}
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+func creationTimestamp(ep *endpoint.Endpoint) (time.Time, bool) {
+	v, ok := ep.Labels[endpoint.ResourceCreationTimestampLabelKey]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		log.Debugf("Couldn't parse creation timestamp %q of %s: %v", v, ep.DNSName, err)
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func priority(ep *endpoint.Endpoint) int {
+	v, ok := ep.Labels[endpoint.ResourcePriorityLabelKey]
+	if !ok {
+		return 0
+	}
+	p, err := strconv.Atoi(v)
+	if err != nil {
+		log.Debugf("Couldn't parse priority %q of %s: %v", v, ep.DNSName, err)
+		return 0
+	}
+	return p
+}
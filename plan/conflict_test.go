@@ -24,7 +24,13 @@ import (
 	"sigs.k8s.io/external-dns/endpoint"
 )
 
-var _ ConflictResolver = PerResource{}
+var (
+	_ ConflictResolver = PerResource{}
+	_ ConflictResolver = OldestResource{}
+	_ ConflictResolver = PriorityResource{}
+	_ ConflictResolver = RefuseConflict{}
+	_ ConflictResolver = MergeTargets{}
+)
 
 type ResolverSuite struct {
 	// resolvers
@@ -133,6 +139,88 @@ func (suite *ResolverSuite) TestStrictResolver() {
 	suite.Equal(suite.bar127A, suite.perResource.ResolveUpdate(suite.legacyBar192A, []*endpoint.Endpoint{suite.bar127A, suite.bar192A}), " legacy record's resource value will not match, should pick minimum")
 }
 
+func (suite *ResolverSuite) TestOldestResolver() {
+	oldest := OldestResource{}
+	older := &endpoint.Endpoint{
+		DNSName:    "bar",
+		Targets:    endpoint.Targets{"192.168.0.1"},
+		RecordType: "A",
+		Labels: map[string]string{
+			endpoint.ResourceLabelKey:                  "ingress/default/bar-192",
+			endpoint.ResourceCreationTimestampLabelKey: "2022-01-01T00:00:00Z",
+		},
+	}
+	newer := &endpoint.Endpoint{
+		DNSName:    "bar",
+		Targets:    endpoint.Targets{"127.0.0.1"},
+		RecordType: "A",
+		Labels: map[string]string{
+			endpoint.ResourceLabelKey:                  "ingress/default/bar-127",
+			endpoint.ResourceCreationTimestampLabelKey: "2022-06-01T00:00:00Z",
+		},
+	}
+
+	suite.Equal(older, oldest.ResolveCreate([]*endpoint.Endpoint{newer, older}), "should pick the oldest resource")
+	suite.Equal(older, oldest.ResolveCreate([]*endpoint.Endpoint{suite.bar127A, older}), "should prefer resources with a known creation timestamp")
+	suite.Equal(suite.bar127A, oldest.ResolveCreate([]*endpoint.Endpoint{suite.bar192A, suite.bar127A}), "should pick min one without creation timestamps")
+	suite.Equal(older, oldest.ResolveUpdate(newer, []*endpoint.Endpoint{newer, older}), "should pick the oldest resource even if it does not own the record")
+}
+
+func (suite *ResolverSuite) TestPriorityResolver() {
+	priority := PriorityResource{}
+	high := &endpoint.Endpoint{
+		DNSName:    "bar",
+		Targets:    endpoint.Targets{"192.168.0.1"},
+		RecordType: "A",
+		Labels: map[string]string{
+			endpoint.ResourceLabelKey:         "ingress/default/bar-192",
+			endpoint.ResourcePriorityLabelKey: "10",
+		},
+	}
+	invalid := &endpoint.Endpoint{
+		DNSName:    "bar",
+		Targets:    endpoint.Targets{"10.0.0.1"},
+		RecordType: "A",
+		Labels: map[string]string{
+			endpoint.ResourceLabelKey:         "ingress/default/bar-10",
+			endpoint.ResourcePriorityLabelKey: "high",
+		},
+	}
+
+	suite.Equal(high, priority.ResolveCreate([]*endpoint.Endpoint{suite.bar127A, high}), "should pick the highest priority")
+	suite.Equal(invalid, priority.ResolveCreate([]*endpoint.Endpoint{suite.bar127A, invalid}), "should treat invalid priority as 0 and pick min one")
+	suite.Equal(high, priority.ResolveUpdate(suite.bar127A, []*endpoint.Endpoint{suite.bar127A, high}), "should hand over the record to the highest priority")
+	suite.Equal(suite.bar192A, priority.ResolveUpdate(suite.bar192A, []*endpoint.Endpoint{suite.bar127A, suite.bar192A}), "should keep existing resource on equal priority")
+}
+
+func (suite *ResolverSuite) TestRefuseResolver() {
+	refuse := RefuseConflict{}
+
+	suite.Nil(refuse.ResolveCreate([]*endpoint.Endpoint{suite.bar127A, suite.bar192A}), "should refuse to pick one of multiple resources")
+	suite.Equal(suite.bar127AAnother, refuse.ResolveCreate([]*endpoint.Endpoint{suite.bar127A, suite.bar127AAnother}), "should pick min one of the same resource")
+	suite.Equal(suite.bar192A, refuse.ResolveCreate([]*endpoint.Endpoint{suite.bar192A}), "should pick the only candidate")
+	suite.Equal(suite.bar192A, refuse.ResolveUpdate(suite.bar192A, []*endpoint.Endpoint{suite.bar127A, suite.bar192A}), "should keep existing resource")
+	suite.Nil(refuse.ResolveUpdate(suite.legacyBar192A, []*endpoint.Endpoint{suite.bar127A, suite.bar192A}), "should refuse to hand over to one of multiple resources")
+	suite.Equal(suite.bar127A, refuse.ResolveUpdate(suite.bar192A, []*endpoint.Endpoint{suite.bar127A}), "should hand over to the only remaining resource")
+}
+
+func (suite *ResolverSuite) TestMergeResolver() {
+	merge := MergeTargets{}
+
+	merged := merge.ResolveCreate([]*endpoint.Endpoint{suite.bar192A, suite.bar127A, suite.bar127AAnother})
+	suite.Equal(endpoint.Targets{"127.0.0.1", "192.168.0.1", "8.8.8.8"}, merged.Targets, "should merge targets of all candidates")
+	suite.Equal("ingress/default/bar-127;ingress/default/bar-192", merged.Labels[endpoint.ResourceLabelKey], "should keep all resources")
+	suite.Equal("ingress/default/bar-127", suite.bar127A.Labels[endpoint.ResourceLabelKey], "should not modify the candidates")
+
+	single := merge.ResolveCreate([]*endpoint.Endpoint{suite.bar127A, suite.bar127AAnother})
+	suite.Equal(endpoint.Targets{"127.0.0.1", "8.8.8.8"}, single.Targets, "should merge targets of the same resource")
+	suite.Equal("ingress/default/bar-127", single.Labels[endpoint.ResourceLabelKey], "should keep the resource")
+
+	suite.Equal(endpoint.Targets{"5.5.5.5"}, merge.ResolveCreate([]*endpoint.Endpoint{suite.fooV1Cname, suite.fooA5}).Targets, "should not merge different record types")
+	suite.Equal(endpoint.Targets{"v1", "v2"}, merge.ResolveUpdate(suite.fooV1Cname, []*endpoint.Endpoint{suite.fooV2Cname, suite.fooA5, suite.fooV1Cname}).Targets, "should keep the record type of the current record")
+	suite.Equal(endpoint.Targets{"v2"}, merge.ResolveUpdate(suite.fooV2CnameDuplicate, []*endpoint.Endpoint{suite.fooV2Cname, suite.fooV2CnameDuplicate}).Targets, "should not duplicate targets")
+}
+
 func TestConflictResolver(t *testing.T) {
 	suite.Run(t, new(ResolverSuite))
 }
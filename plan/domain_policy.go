@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// DomainPolicyName is the name of the DomainPolicy
+const DomainPolicyName = "per-domain"
+
+// DomainPolicyConfig is the configuration of a DomainPolicy as read from a file, e.g.
+//
+//	default: upsert-only
+//	domains:
+//	- domain: dev.example.com
+//	  policy: sync
+type DomainPolicyConfig struct {
+	// Default is the name of the policy applied to records not matching any of the domains
+	Default string `json:"default,omitempty"`
+	// Domains lists the policies applied to the records of a domain and its subdomains
+	Domains []DomainPolicyRule `json:"domains"`
+}
+
+// DomainPolicyRule assigns a policy to a domain and its subdomains
+type DomainPolicyRule struct {
+	Domain string `json:"domain"`
+	Policy string `json:"policy"`
+}
+
+// ReadDomainPolicyConfig reads a DomainPolicyConfig in either YAML or JSON format
+func ReadDomainPolicyConfig(r io.Reader) (*DomainPolicyConfig, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	config := &DomainPolicyConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+type domainPolicyRule struct {
+	domain string
+	policy Policy
+}
+
+// DomainPolicy applies different policies to the records of different domains.
+// Records are assigned to the most specific matching domain, all other records are subject to the default policy.
+type DomainPolicy struct {
+	defaultPolicy Policy
+	rules         []domainPolicyRule
+}
+
+// NewDomainPolicy creates a DomainPolicy from the given configuration.
+// The defaultPolicy is used for records not matching any domain unless the configuration names a default itself.
+func NewDomainPolicy(config *DomainPolicyConfig, defaultPolicy Policy) (*DomainPolicy, error) {
+	p := &DomainPolicy{defaultPolicy: defaultPolicy}
+	if config.Default != "" {
+		policy, exists := Policies[config.Default]
+		if !exists {
+			return nil, fmt.Errorf("unknown default policy: %s", config.Default)
+		}
+		p.defaultPolicy = policy
+	}
+
+	seen := map[string]bool{}
+	for _, rule := range config.Domains {
+		domain := normalizeDomain(rule.Domain)
+		if domain == "" {
+			return nil, fmt.Errorf("empty domain for policy %q", rule.Policy)
+		}
+		if seen[domain] {
+			return nil, fmt.Errorf("duplicate policy for domain %s", domain)
+		}
+		seen[domain] = true
+
+		policy, exists := Policies[rule.Policy]
+		if !exists {
+			return nil, fmt.Errorf("unknown policy for domain %s: %s", domain, rule.Policy)
+		}
+		p.rules = append(p.rules, domainPolicyRule{domain: domain, policy: policy})
+	}
+
+	// most specific domains first so that the first matching rule wins
+	sort.SliceStable(p.rules, func(i, j int) bool {
+		return len(p.rules[i].domain) > len(p.rules[j].domain)
+	})
+	return p, nil
+}
+
+// Name returns the name of the policy
+func (p *DomainPolicy) Name() string {
+	return DomainPolicyName
+}
+
+// Apply splits the changes by domain and applies the policy of each domain to its share of the changes.
+func (p *DomainPolicy) Apply(changes *Changes) *Changes {
+	// index len(p.rules) holds the changes subject to the default policy
+	split := make([]Changes, len(p.rules)+1)
+
+	for _, ep := range changes.Create {
+		i := p.match(ep)
+		split[i].Create = append(split[i].Create, ep)
+	}
+	// UpdateOld and UpdateNew are kept aligned, both refer to the same DNS name
+	for j := range changes.UpdateNew {
+		i := p.match(changes.UpdateNew[j])
+		split[i].UpdateOld = append(split[i].UpdateOld, changes.UpdateOld[j])
+		split[i].UpdateNew = append(split[i].UpdateNew, changes.UpdateNew[j])
+	}
+	for _, ep := range changes.Delete {
+		i := p.match(ep)
+		split[i].Delete = append(split[i].Delete, ep)
+	}
+
+	result := &Changes{}
+	for i := range split {
+		policy := p.defaultPolicy
+		if i < len(p.rules) {
+			policy = p.rules[i].policy
+		}
+		applied := policy.Apply(&split[i])
+		result.Create = append(result.Create, applied.Create...)
+		result.UpdateOld = append(result.UpdateOld, applied.UpdateOld...)
+		result.UpdateNew = append(result.UpdateNew, applied.UpdateNew...)
+		result.Delete = append(result.Delete, applied.Delete...)
+	}
+	return result
+}
+
+// match returns the index of the rule the endpoint is subject to, len(p.rules) for the default policy
+func (p *DomainPolicy) match(ep *endpoint.Endpoint) int {
+	name := normalizeDomain(ep.DNSName)
+	for i, rule := range p.rules {
+		if name == rule.domain || strings.HasSuffix(name, "."+rule.domain) {
+			return i
+		}
+	}
+	return len(p.rules)
+}
+
+func normalizeDomain(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+}
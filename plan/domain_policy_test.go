@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestReadDomainPolicyConfig(t *testing.T) {
+	config, err := ReadDomainPolicyConfig(strings.NewReader(`
+default: upsert-only
+domains:
+- domain: dev.example.com
+  policy: sync
+`))
+	require.NoError(t, err)
+	assert.Equal(t, &DomainPolicyConfig{
+		Default: "upsert-only",
+		Domains: []DomainPolicyRule{{Domain: "dev.example.com", Policy: "sync"}},
+	}, config)
+
+	_, err = ReadDomainPolicyConfig(strings.NewReader(`{"domains": [{"domain": "example.com", "polciy": "sync"}]}`))
+	assert.Error(t, err, "unknown fields should be rejected")
+}
+
+func TestNewDomainPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		title  string
+		config *DomainPolicyConfig
+		err    bool
+	}{
+		{"valid", &DomainPolicyConfig{Default: "sync", Domains: []DomainPolicyRule{{Domain: "example.com", Policy: "upsert-only"}}}, false},
+		{"unknown default", &DomainPolicyConfig{Default: "nope"}, true},
+		{"unknown policy", &DomainPolicyConfig{Domains: []DomainPolicyRule{{Domain: "example.com", Policy: "nope"}}}, true},
+		{"empty domain", &DomainPolicyConfig{Domains: []DomainPolicyRule{{Domain: "", Policy: "sync"}}}, true},
+		{"duplicate domain", &DomainPolicyConfig{Domains: []DomainPolicyRule{{Domain: "example.com", Policy: "sync"}, {Domain: "Example.com.", Policy: "create-only"}}}, true},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			_, err := NewDomainPolicy(tc.config, &SyncPolicy{})
+			assert.Equal(t, tc.err, err != nil, "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestDomainPolicy(t *testing.T) {
+	record := func(name, target string) *endpoint.Endpoint {
+		return &endpoint.Endpoint{DNSName: name, Targets: endpoint.Targets{target}}
+	}
+	prod := record("www.example.com", "v1")
+	prodV2 := record("www.example.com", "v2")
+	prodGone := record("old.example.com", "v1")
+	dev := record("app.dev.example.com", "v1")
+	devV2 := record("app.dev.example.com", "v2")
+	devGone := record("old.dev.example.com.", "v1")
+	apex := record("dev.example.com", "v1")
+	other := record("example.org", "v1")
+	otherGone := record("old.example.org", "v1")
+
+	policy, err := NewDomainPolicy(&DomainPolicyConfig{
+		Domains: []DomainPolicyRule{
+			{Domain: "example.com", Policy: "upsert-only"},
+			{Domain: "dev.example.com", Policy: "sync"},
+		},
+	}, &CreateOnlyPolicy{})
+	require.NoError(t, err)
+	assert.Equal(t, DomainPolicyName, PolicyName(policy))
+
+	changes := policy.Apply(&Changes{
+		Create:    []*endpoint.Endpoint{prod, apex, other},
+		UpdateOld: []*endpoint.Endpoint{prod, dev, other},
+		UpdateNew: []*endpoint.Endpoint{prodV2, devV2, other},
+		Delete:    []*endpoint.Endpoint{prodGone, devGone, otherGone},
+	})
+
+	assert.ElementsMatch(t, []*endpoint.Endpoint{prod, apex, other}, changes.Create)
+	// updates of example.org are stripped by the default create-only policy
+	assert.Equal(t, []*endpoint.Endpoint{dev, prod}, changes.UpdateOld)
+	assert.Equal(t, []*endpoint.Endpoint{devV2, prodV2}, changes.UpdateNew)
+	// only the sync policy of dev.example.com allows deletions
+	assert.Equal(t, []*endpoint.Endpoint{devGone}, changes.Delete)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// OutputFormatJSON writes the plan as indented JSON
+	OutputFormatJSON = "json"
+	// OutputFormatYAML writes the plan as YAML
+	OutputFormatYAML = "yaml"
+)
+
+// Output is the machine-readable representation of a calculated Plan
+type Output struct {
+	// Names of the policies the changes were calculated under
+	Policies []string `json:"policies"`
+	// Changes which are going to be applied
+	Changes *Changes `json:"changes"`
+	// Changes dropped by each of the policies, keyed by policy name
+	Filtered map[string]*Changes `json:"filtered,omitempty"`
+	// Maintenance of the ownership records by the registry, applied before the changes
+	Maintenance *Output `json:"maintenance,omitempty"`
+}
+
+// Output returns the machine-readable representation of the plan.
+// It should be called on the Plan returned by Calculate().
+func (p *Plan) Output() *Output {
+	out := &Output{
+		Policies: []string{},
+		Changes:  p.Changes,
+		Filtered: p.Filtered,
+	}
+	for _, pol := range p.Policies {
+		out.Policies = append(out.Policies, PolicyName(pol))
+	}
+	if out.Changes == nil {
+		out.Changes = &Changes{}
+	}
+	return out
+}
+
+// ReadOutput deserializes an output previously written in any of the supported formats
+func ReadOutput(r io.Reader) (*Output, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	out := &Output{}
+	// JSON is a subset of YAML, so both formats are handled alike
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+	if out.Changes == nil {
+		out.Changes = &Changes{}
+	}
+	return out, nil
+}
+
+// Write serializes the output in the given format to w
+func (o *Output) Write(w io.Writer, format string) error {
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return err
+	}
+	switch format {
+	case OutputFormatJSON:
+		data = append(data, '\n')
+	case OutputFormatYAML:
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown plan output format: %s", format)
+	}
+	_, err = w.Write(data)
+	return err
+}
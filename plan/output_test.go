@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestOutput(t *testing.T) {
+	current := []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "owner"}},
+	}
+	desired := []*endpoint.Endpoint{
+		{DNSName: "bar.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"5.6.7.8"}, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "service/default/bar"}},
+	}
+
+	p := &Plan{
+		Policies:       []Policy{&UpsertOnlyPolicy{}},
+		Current:        current,
+		Desired:        desired,
+		ManagedRecords: []string{endpoint.RecordTypeA},
+	}
+	out := p.Calculate().Output()
+
+	assert.Equal(t, []string{"upsert-only"}, out.Policies)
+	assert.Equal(t, desired, out.Changes.Create)
+	assert.Empty(t, out.Changes.Delete)
+	require.Contains(t, out.Filtered, "upsert-only")
+	assert.Equal(t, current, out.Filtered["upsert-only"].Delete)
+
+	for _, tc := range []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: OutputFormatJSON,
+			expected: `{
+  "policies": [
+    "upsert-only"
+  ],
+  "changes": {
+    "create": [
+      {
+        "dnsName": "bar.example.org",
+        "targets": [
+          "5.6.7.8"
+        ],
+        "recordType": "A",
+        "labels": {
+          "resource": "service/default/bar"
+        }
+      }
+    ]
+  },
+  "filtered": {
+    "upsert-only": {
+      "delete": [
+        {
+          "dnsName": "foo.example.org",
+          "targets": [
+            "1.2.3.4"
+          ],
+          "recordType": "A",
+          "labels": {
+            "owner": "owner"
+          }
+        }
+      ]
+    }
+  }
+}
+`,
+		},
+		{
+			format: OutputFormatYAML,
+			expected: `changes:
+  create:
+  - dnsName: bar.example.org
+    labels:
+      resource: service/default/bar
+    recordType: A
+    targets:
+    - 5.6.7.8
+filtered:
+  upsert-only:
+    delete:
+    - dnsName: foo.example.org
+      labels:
+        owner: owner
+      recordType: A
+      targets:
+      - 1.2.3.4
+policies:
+- upsert-only
+`,
+		},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, out.Write(&buf, tc.format))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	assert.Error(t, out.Write(&bytes.Buffer{}, "xml"))
+}
+
+func TestReadOutput(t *testing.T) {
+	out := &Output{
+		Policies: []string{"sync"},
+		Changes: &Changes{
+			UpdateOld: []*endpoint.Endpoint{{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "owner"}}},
+			UpdateNew: []*endpoint.Endpoint{{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"5.6.7.8"}, RecordTTL: 300, Labels: endpoint.Labels{endpoint.OwnerLabelKey: "owner"}}},
+		},
+	}
+	for _, format := range []string{OutputFormatJSON, OutputFormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, out.Write(&buf, format))
+			read, err := ReadOutput(&buf)
+			require.NoError(t, err)
+			assert.Equal(t, out, read)
+		})
+	}
+
+	_, err := ReadOutput(bytes.NewBufferString("changes: ["))
+	assert.Error(t, err)
+}
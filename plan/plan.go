@@ -39,6 +39,9 @@ type Plan struct {
 	Desired []*endpoint.Endpoint
 	// List of missing records to be created, use for the migrations (e.g. old-new TXT format)
 	Missing []*endpoint.Endpoint
+	// Changes of the registry to its own ownership records, e.g. adoptions or deletions of orphaned TXT records,
+	// they are subject to the Policies like the calculated changes
+	Maintenance *Changes
 	// Policies under which the desired changes are calculated
 	Policies []Policy
 	// List of changes necessary to move towards desired state
@@ -50,18 +53,29 @@ type Plan struct {
 	PropertyComparator PropertyComparator
 	// DNS record types that will be considered for management
 	ManagedRecords []string
+	// Resolver decides which of the desired records acquires a contested DNS name (default: PerResource)
+	Resolver ConflictResolver
+	// AdoptUnowned updates current records without owner which are desired by a record labeled for adoption,
+	// even if they are up to date, so that the registry takes their ownership
+	AdoptUnowned bool
+	// Changes dropped by each of the Policies, keyed by policy name
+	// Populated after calling Calculate()
+	Filtered map[string]*Changes
+	// Desired records which did not acquire their DNS name because it is claimed by another resource
+	// Populated after calling Calculate()
+	Conflicts []*endpoint.Endpoint
 }
 
 // Changes holds lists of actions to be executed by dns providers
 type Changes struct {
 	// Records that need to be created
-	Create []*endpoint.Endpoint
+	Create []*endpoint.Endpoint `json:"create,omitempty"`
 	// Records that need to be updated (current data)
-	UpdateOld []*endpoint.Endpoint
+	UpdateOld []*endpoint.Endpoint `json:"updateOld,omitempty"`
 	// Records that need to be updated (desired data)
-	UpdateNew []*endpoint.Endpoint
+	UpdateNew []*endpoint.Endpoint `json:"updateNew,omitempty"`
 	// Records that need to be deleted
-	Delete []*endpoint.Endpoint
+	Delete []*endpoint.Endpoint `json:"delete,omitempty"`
 }
 
 // planTable is a supplementary struct for Plan
@@ -82,8 +96,11 @@ type planTable struct {
 	resolver ConflictResolver
 }
 
-func newPlanTable() planTable { // TODO: make resolver configurable
-	return planTable{map[string]map[string]*planTableRow{}, PerResource{}}
+func newPlanTable(resolver ConflictResolver) planTable {
+	if resolver == nil {
+		resolver = PerResource{}
+	}
+	return planTable{map[string]map[string]*planTableRow{}, resolver}
 }
 
 // planTableRow
@@ -131,7 +148,7 @@ func (c *Changes) HasChanges() bool {
 // state. It then passes those changes to the current policy for further
 // processing. It returns a copy of Plan with the changes populated.
 func (p *Plan) Calculate() *Plan {
-	t := newPlanTable()
+	t := newPlanTable(p.Resolver)
 
 	if p.DomainFilter == nil {
 		p.DomainFilter = endpoint.MatchAllDomainFilters(nil)
@@ -145,11 +162,16 @@ func (p *Plan) Calculate() *Plan {
 	}
 
 	changes := &Changes{}
+	var conflicts []*endpoint.Endpoint
 
 	for _, topRow := range t.rows {
 		for _, row := range topRow {
 			if row.current == nil { // dns name not taken
-				changes.Create = append(changes.Create, t.resolver.ResolveCreate(row.candidates))
+				create := t.resolver.ResolveCreate(row.candidates)
+				if create != nil {
+					changes.Create = append(changes.Create, create)
+				}
+				conflicts = append(conflicts, losingCandidates(create, row.candidates)...)
 			}
 			if row.current != nil && len(row.candidates) == 0 {
 				changes.Delete = append(changes.Delete, row.current)
@@ -158,8 +180,12 @@ func (p *Plan) Calculate() *Plan {
 			// TODO: allows record type change, which might not be supported by all dns providers
 			if row.current != nil && len(row.candidates) > 0 { // dns name is taken
 				update := t.resolver.ResolveUpdate(row.current, row.candidates)
+				conflicts = append(conflicts, losingCandidates(update, row.candidates)...)
+				if update == nil { // resolver refused to pick a candidate, leave the record as is
+					continue
+				}
 				// compare "update" to "current" to figure out if actual update is required
-				if shouldUpdateTTL(update, row.current) || targetChanged(update, row.current) || p.shouldUpdateProviderSpecific(update, row.current) {
+				if shouldUpdateTTL(update, row.current) || targetChanged(update, row.current) || p.shouldUpdateProviderSpecific(update, row.current) || p.shouldAdopt(update, row.current) {
 					inheritOwner(row.current, update)
 					changes.UpdateNew = append(changes.UpdateNew, update)
 					changes.UpdateOld = append(changes.UpdateOld, row.current)
@@ -168,8 +194,20 @@ func (p *Plan) Calculate() *Plan {
 			}
 		}
 	}
+	if p.Maintenance != nil {
+		changes.Create = append(changes.Create, p.Maintenance.Create...)
+		changes.UpdateOld = append(changes.UpdateOld, p.Maintenance.UpdateOld...)
+		changes.UpdateNew = append(changes.UpdateNew, p.Maintenance.UpdateNew...)
+		changes.Delete = append(changes.Delete, p.Maintenance.Delete...)
+	}
+
+	filtered := map[string]*Changes{}
 	for _, pol := range p.Policies {
-		changes = pol.Apply(changes)
+		applied := pol.Apply(changes)
+		if dropped := droppedChanges(changes, applied); dropped.HasChanges() {
+			filtered[PolicyName(pol)] = dropped
+		}
+		changes = applied
 	}
 
 	// Handle the migration of the TXT records created before the new format (introduced in v0.12.0)
@@ -180,13 +218,63 @@ func (p *Plan) Calculate() *Plan {
 	plan := &Plan{
 		Current:        p.Current,
 		Desired:        p.Desired,
+		Policies:       p.Policies,
 		Changes:        changes,
 		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+		Filtered:       filtered,
+		Conflicts:      conflicts,
 	}
 
 	return plan
 }
 
+// losingCandidates returns the candidates of resources other than the ones of the chosen record.
+// All candidates lose if the resolver refused to choose one of several resources.
+func losingCandidates(chosen *endpoint.Endpoint, candidates []*endpoint.Endpoint) []*endpoint.Endpoint {
+	if len(candidates) < 2 {
+		return nil
+	}
+	if chosen == nil {
+		return candidates
+	}
+	winners := map[string]bool{}
+	// merged records list all resources contributing to them
+	for _, resource := range strings.Split(chosen.Labels[endpoint.ResourceLabelKey], endpoint.ResourceLabelSeparator) {
+		winners[resource] = true
+	}
+	var losers []*endpoint.Endpoint
+	for _, ep := range candidates {
+		if !winners[ep.Labels[endpoint.ResourceLabelKey]] {
+			losers = append(losers, ep)
+		}
+	}
+	return losers
+}
+
+// droppedChanges returns the changes of before which are not part of after anymore
+func droppedChanges(before, after *Changes) *Changes {
+	return &Changes{
+		Create:    endpointsNotIn(before.Create, after.Create),
+		UpdateOld: endpointsNotIn(before.UpdateOld, after.UpdateOld),
+		UpdateNew: endpointsNotIn(before.UpdateNew, after.UpdateNew),
+		Delete:    endpointsNotIn(before.Delete, after.Delete),
+	}
+}
+
+func endpointsNotIn(endpoints, others []*endpoint.Endpoint) []*endpoint.Endpoint {
+	kept := make(map[*endpoint.Endpoint]struct{}, len(others))
+	for _, ep := range others {
+		kept[ep] = struct{}{}
+	}
+	var missing []*endpoint.Endpoint
+	for _, ep := range endpoints {
+		if _, ok := kept[ep]; !ok {
+			missing = append(missing, ep)
+		}
+	}
+	return missing
+}
+
 func inheritOwner(from, to *endpoint.Endpoint) {
 	if to.Labels == nil {
 		to.Labels = map[string]string{}
@@ -197,6 +285,10 @@ func inheritOwner(from, to *endpoint.Endpoint) {
 	to.Labels[endpoint.OwnerLabelKey] = from.Labels[endpoint.OwnerLabelKey]
 }
 
+func (p *Plan) shouldAdopt(desired, current *endpoint.Endpoint) bool {
+	return p.AdoptUnowned && current.Labels[endpoint.OwnerLabelKey] == "" && desired.Labels[endpoint.AdoptLabelKey] == "true"
+}
+
 func targetChanged(desired, current *endpoint.Endpoint) bool {
 	return !desired.Targets.Same(current.Targets)
 }
@@ -590,6 +590,81 @@ func (suite *PlanTestSuite) TestDuplicatedEndpointsForSameResourceRetain() {
 	validateEntries(suite.T(), changes.Delete, expectedDelete)
 }
 
+func (suite *PlanTestSuite) TestRefusedConflict() {
+	current := []*endpoint.Endpoint{suite.fooV2CnameNoLabel}
+	desired := []*endpoint.Endpoint{suite.fooV1Cname, suite.fooV2Cname, suite.bar127A, suite.bar192A}
+	expectedCreate := []*endpoint.Endpoint{}
+	expectedUpdateOld := []*endpoint.Endpoint{}
+	expectedUpdateNew := []*endpoint.Endpoint{}
+	expectedDelete := []*endpoint.Endpoint{}
+
+	p := &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        current,
+		Desired:        desired,
+		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+		Resolver:       RefuseConflict{},
+	}
+
+	changes := p.Calculate().Changes
+	validateEntries(suite.T(), changes.Create, expectedCreate)
+	validateEntries(suite.T(), changes.UpdateNew, expectedUpdateNew)
+	validateEntries(suite.T(), changes.UpdateOld, expectedUpdateOld)
+	validateEntries(suite.T(), changes.Delete, expectedDelete)
+}
+
+func (suite *PlanTestSuite) TestConflicts() {
+	desired := []*endpoint.Endpoint{suite.bar127A, suite.bar192A}
+
+	for _, tc := range []struct {
+		resolver ConflictResolver
+		expected []*endpoint.Endpoint
+	}{
+		{PerResource{}, []*endpoint.Endpoint{suite.bar192A}},
+		{RefuseConflict{}, []*endpoint.Endpoint{suite.bar127A, suite.bar192A}},
+		{MergeTargets{}, []*endpoint.Endpoint{}},
+	} {
+		p := &Plan{
+			Policies:       []Policy{&SyncPolicy{}},
+			Desired:        desired,
+			ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+			Resolver:       tc.resolver,
+		}
+		validateEntries(suite.T(), p.Calculate().Conflicts, tc.expected)
+	}
+}
+
+func (suite *PlanTestSuite) TestMergeTargets() {
+	merged := &endpoint.Endpoint{
+		DNSName:    "bar",
+		Targets:    endpoint.Targets{"127.0.0.1", "192.168.0.1"},
+		RecordType: "A",
+		Labels: map[string]string{
+			endpoint.ResourceLabelKey: "ingress/default/bar-127;ingress/default/bar-192",
+		},
+	}
+	current := []*endpoint.Endpoint{merged}
+	desired := []*endpoint.Endpoint{suite.bar192A}
+	expectedCreate := []*endpoint.Endpoint{}
+	expectedUpdateOld := []*endpoint.Endpoint{merged}
+	expectedUpdateNew := []*endpoint.Endpoint{suite.bar192A}
+	expectedDelete := []*endpoint.Endpoint{}
+
+	p := &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        current,
+		Desired:        desired,
+		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+		Resolver:       MergeTargets{},
+	}
+
+	changes := p.Calculate().Changes
+	validateEntries(suite.T(), changes.Create, expectedCreate)
+	validateEntries(suite.T(), changes.UpdateNew, expectedUpdateNew)
+	validateEntries(suite.T(), changes.UpdateOld, expectedUpdateOld)
+	validateEntries(suite.T(), changes.Delete, expectedDelete)
+}
+
 func (suite *PlanTestSuite) TestMultipleRecordsSameNameDifferentSetIdentifier() {
 	current := []*endpoint.Endpoint{suite.multiple1}
 	desired := []*endpoint.Endpoint{suite.multiple2, suite.multiple3}
@@ -695,6 +770,63 @@ func (suite *PlanTestSuite) TestMissing() {
 	validateEntries(suite.T(), changes.Create, expectedCreate)
 }
 
+func (suite *PlanTestSuite) TestMaintenance() {
+	maintenance := &Changes{
+		UpdateOld: []*endpoint.Endpoint{suite.domainFilterFilteredTXT1},
+		UpdateNew: []*endpoint.Endpoint{suite.domainFilterFilteredTXT2},
+		Delete:    []*endpoint.Endpoint{suite.domainFilterExcludedTXT},
+	}
+
+	p := &Plan{
+		Policies:       []Policy{&UpsertOnlyPolicy{}},
+		Maintenance:    maintenance,
+		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	}
+
+	calculated := p.Calculate()
+	validateEntries(suite.T(), calculated.Changes.UpdateOld, maintenance.UpdateOld)
+	validateEntries(suite.T(), calculated.Changes.UpdateNew, maintenance.UpdateNew)
+	validateEntries(suite.T(), calculated.Changes.Delete, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), calculated.Filtered["upsert-only"].Delete, maintenance.Delete)
+}
+
+func (suite *PlanTestSuite) TestAdoptUnowned() {
+	unowned := &endpoint.Endpoint{
+		DNSName:    "foo",
+		Targets:    endpoint.Targets{"v1"},
+		RecordType: "CNAME",
+		Labels:     map[string]string{},
+	}
+	adopting := &endpoint.Endpoint{
+		DNSName:    "foo",
+		Targets:    endpoint.Targets{"v1"},
+		RecordType: "CNAME",
+		Labels: map[string]string{
+			endpoint.ResourceLabelKey: "ingress/default/foo-v1",
+			endpoint.AdoptLabelKey:    "true",
+		},
+	}
+
+	p := &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        []*endpoint.Endpoint{unowned},
+		Desired:        []*endpoint.Endpoint{adopting},
+		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	}
+	changes := p.Calculate().Changes
+	suite.False(changes.HasChanges(), "up to date records are only adopted if enabled")
+
+	p.AdoptUnowned = true
+	changes = p.Calculate().Changes
+	validateEntries(suite.T(), changes.UpdateOld, []*endpoint.Endpoint{unowned})
+	validateEntries(suite.T(), changes.UpdateNew, []*endpoint.Endpoint{adopting})
+
+	// owned records are left alone
+	p.Current = []*endpoint.Endpoint{suite.fooV1Cname}
+	changes = p.Calculate().Changes
+	suite.False(changes.HasChanges())
+}
+
 func TestPlan(t *testing.T) {
 	suite.Run(t, new(PlanTestSuite))
 }
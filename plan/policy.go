@@ -16,6 +16,15 @@ limitations under the License.
 
 package plan
 
+import (
+	"fmt"
+	"reflect"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
 // Policy allows to apply different rules to a set of changes.
 type Policy interface {
 	Apply(changes *Changes) *Changes
@@ -28,6 +37,20 @@ var Policies = map[string]Policy{
 	"create-only": &CreateOnlyPolicy{},
 }
 
+// PolicyName returns the name the policy is registered with in Policies.
+// Policies which are not registered are named after their type unless they provide a name themselves.
+func PolicyName(policy Policy) string {
+	if named, ok := policy.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	for name, p := range Policies {
+		if reflect.TypeOf(p) == reflect.TypeOf(policy) {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", policy)
+}
+
 // SyncPolicy allows for full synchronization of DNS records.
 type SyncPolicy struct{}
 
@@ -57,3 +80,81 @@ func (p *CreateOnlyPolicy) Apply(changes *Changes) *Changes {
 		Create: changes.Create,
 	}
 }
+
+// ChangeBudgetPolicyName is the name of the ChangeBudgetPolicy
+const ChangeBudgetPolicyName = "change-budget"
+
+// ChangeBudgetPolicy aborts a synchronization which deletes more records than allowed.
+// It protects against mass deletions, e.g. when a broken source returns no endpoints at all.
+// The budget refers to the current records, it is only checked by the copies returned by WithCurrent.
+type ChangeBudgetPolicy struct {
+	// MaxDeletes is the maximum number of owned records deleted at once (0: unlimited)
+	MaxDeletes int
+	// MaxDeletePercentage is the maximum percentage of owned records deleted at once (0: unlimited)
+	MaxDeletePercentage float64
+	// OwnerIDs identify the owned records, if empty all records are considered as owned
+	OwnerIDs []string
+
+	owned int
+	// current is set by WithCurrent, the budget refers to no records otherwise
+	current bool
+}
+
+// WithCurrent returns a copy of the policy which budget refers to the given current records
+func (p *ChangeBudgetPolicy) WithCurrent(current []*endpoint.Endpoint) *ChangeBudgetPolicy {
+	budget := *p
+	budget.owned = len(budget.ownedRecords(current))
+	budget.current = true
+	return &budget
+}
+
+// Name returns the name of the policy
+func (p *ChangeBudgetPolicy) Name() string {
+	return ChangeBudgetPolicyName
+}
+
+// Apply applies the change budget policy which strips out all changes if the budget is exceeded.
+func (p *ChangeBudgetPolicy) Apply(changes *Changes) *Changes {
+	if err := p.Check(changes); err != nil {
+		log.Errorf("Dropping all changes: %v", err)
+		return &Changes{}
+	}
+	return changes
+}
+
+// Check returns an error if the changes exceed the budget or the budget refers to no current records.
+func (p *ChangeBudgetPolicy) Check(changes *Changes) error {
+	if !p.current {
+		return fmt.Errorf("change budget refers to no current records, it must be created by WithCurrent")
+	}
+	deletes := len(p.ownedRecords(changes.Delete))
+	if p.MaxDeletes > 0 && deletes > p.MaxDeletes {
+		return fmt.Errorf("%d records would be deleted, the maximum is %d", deletes, p.MaxDeletes)
+	}
+	if p.MaxDeletePercentage > 0 && deletes > 0 {
+		percentage := 100.0
+		if p.owned > 0 {
+			percentage = float64(deletes) / float64(p.owned) * 100
+		}
+		if percentage > p.MaxDeletePercentage {
+			return fmt.Errorf("%d of %d owned records (%.1f%%) would be deleted, the maximum is %.1f%%", deletes, p.owned, percentage, p.MaxDeletePercentage)
+		}
+	}
+	return nil
+}
+
+func (p *ChangeBudgetPolicy) ownedRecords(records []*endpoint.Endpoint) []*endpoint.Endpoint {
+	if len(p.OwnerIDs) == 0 {
+		return records
+	}
+	owned := []*endpoint.Endpoint{}
+	for _, r := range records {
+		for _, ownerID := range p.OwnerIDs {
+			if r.Labels[endpoint.OwnerLabelKey] == ownerID {
+				owned = append(owned, r)
+				break
+			}
+		}
+	}
+	return owned
+}
@@ -86,3 +86,76 @@ func validatePolicy(t *testing.T, policy, expected Policy) {
 		t.Errorf("expected %q to match %q", policyType, expectedType)
 	}
 }
+
+// TestPolicyName tests that policies are named after their registration.
+func TestPolicyName(t *testing.T) {
+	if name := PolicyName(&UpsertOnlyPolicy{}); name != "upsert-only" {
+		t.Errorf("expected %q to match %q", name, "upsert-only")
+	}
+	if name := PolicyName(&unregisteredPolicy{}); name != "*plan.unregisteredPolicy" {
+		t.Errorf("expected %q to match %q", name, "*plan.unregisteredPolicy")
+	}
+}
+
+type unregisteredPolicy struct{}
+
+func (p *unregisteredPolicy) Apply(changes *Changes) *Changes {
+	return changes
+}
+
+// TestChangeBudgetPolicy tests that the change budget policy aborts mass deletions.
+func TestChangeBudgetPolicy(t *testing.T) {
+	owned := func(name, owner string) *endpoint.Endpoint {
+		return &endpoint.Endpoint{DNSName: name, Targets: endpoint.Targets{"v1"}, Labels: endpoint.Labels{endpoint.OwnerLabelKey: owner}}
+	}
+	current := []*endpoint.Endpoint{owned("foo", "me"), owned("bar", "me"), owned("baz", "me"), owned("qux", "other")}
+
+	for _, tc := range []struct {
+		title   string
+		policy  *ChangeBudgetPolicy
+		deletes []*endpoint.Endpoint
+		err     bool
+	}{
+		{"unlimited", &ChangeBudgetPolicy{}, current, false},
+		{"within absolute budget", &ChangeBudgetPolicy{MaxDeletes: 2}, current[:2], false},
+		{"exceeding absolute budget", &ChangeBudgetPolicy{MaxDeletes: 2}, current[:3], true},
+		{"foreign records don't count", &ChangeBudgetPolicy{MaxDeletes: 2, OwnerIDs: []string{"me"}}, []*endpoint.Endpoint{current[0], current[1], current[3]}, false},
+		{"within percentage budget", &ChangeBudgetPolicy{MaxDeletePercentage: 50, OwnerIDs: []string{"me"}}, current[:1], false},
+		{"exceeding percentage budget", &ChangeBudgetPolicy{MaxDeletePercentage: 50, OwnerIDs: []string{"me"}}, current[:2], true},
+		{"records of all owner ids count", &ChangeBudgetPolicy{MaxDeletes: 2, OwnerIDs: []string{"me", "other"}}, []*endpoint.Endpoint{current[0], current[1], current[3]}, true},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			policy := tc.policy.WithCurrent(current)
+			changes := &Changes{Create: []*endpoint.Endpoint{owned("new", "me")}, Delete: tc.deletes}
+
+			err := policy.Check(changes)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %t, got %v", tc.err, err)
+			}
+
+			applied := policy.Apply(changes)
+			if tc.err {
+				validateEntries(t, applied.Create, []*endpoint.Endpoint{})
+				validateEntries(t, applied.Delete, []*endpoint.Endpoint{})
+			} else {
+				validateEntries(t, applied.Create, changes.Create)
+				validateEntries(t, applied.Delete, changes.Delete)
+			}
+		})
+	}
+}
+
+// TestChangeBudgetPolicyWithoutCurrent tests that the change budget policy refuses changes unless it refers to the current records.
+func TestChangeBudgetPolicyWithoutCurrent(t *testing.T) {
+	changes := &Changes{Create: []*endpoint.Endpoint{{DNSName: "new", Targets: endpoint.Targets{"v1"}}}}
+	policy := &ChangeBudgetPolicy{MaxDeletePercentage: 50}
+
+	if err := policy.Check(changes); err == nil {
+		t.Fatal("expected error for the change budget without current records")
+	}
+	validateEntries(t, policy.Apply(changes).Create, []*endpoint.Endpoint{})
+
+	if err := policy.WithCurrent(nil).Check(changes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -313,13 +314,29 @@ func (p *AWSProvider) Records(ctx context.Context) (endpoints []*endpoint.Endpoi
 	return p.records(ctx, zones)
 }
 
+// supportedRecordType returns true for the record types of provider.SupportedRecordType and
+// the AAAA, MX and CAA records Route53 supports in addition
+func supportedRecordType(recordType string) bool {
+	switch recordType {
+	case endpoint.RecordTypeAAAA, endpoint.RecordTypeMX, endpoint.RecordTypeCAA:
+		return true
+	default:
+		return provider.SupportedRecordType(recordType)
+	}
+}
+
 func (p *AWSProvider) records(ctx context.Context, zones map[string]*route53.HostedZone) ([]*endpoint.Endpoint, error) {
 	endpoints := make([]*endpoint.Endpoint, 0)
 	f := func(resp *route53.ListResourceRecordSetsOutput, lastPage bool) (shouldContinue bool) {
 		for _, r := range resp.ResourceRecordSets {
 			newEndpoints := make([]*endpoint.Endpoint, 0)
 
-			if !provider.SupportedRecordType(aws.StringValue(r.Type)) {
+			if !supportedRecordType(aws.StringValue(r.Type)) {
+				continue
+			}
+
+			// AAAA alias records are the dualstack counterpart of the A alias records, both are represented by the same endpoint
+			if r.AliasTarget != nil && aws.StringValue(r.Type) == route53.RRTypeAaaa {
 				continue
 			}
 
@@ -506,6 +523,9 @@ func (p *AWSProvider) submitChanges(ctx context.Context, changes []*route53.Chan
 	}
 
 	var failedZones []string
+	var changeErrors provider.ChangeErrors
+	// unattributed is set if any failure is not caused by the changes, e.g. throttling, so that all are retried
+	var unattributed bool
 	for z, cs := range changesByZone {
 		var failedUpdate bool
 
@@ -526,8 +546,25 @@ func (p *AWSProvider) submitChanges(ctx context.Context, changes []*route53.Chan
 
 				if _, err := p.client.ChangeResourceRecordSetsWithContext(ctx, params); err != nil {
 					log.Errorf("Failure in zone %s [Id: %s]", aws.StringValue(zones[z].Name), z)
-					log.Error(err) // TODO(ideahitme): consider changing the interface in cases when this error might be a concern for other components
-					failedUpdate = true
+					log.Error(err)
+					if isInvalidChangeBatch(err) {
+						// a single invalid change fails the whole batch, hence retry the changes of each name
+						// on their own so that only the records of the failing names are reported
+						batchErrors, retryErr := p.submitChangesByName(ctx, z, b, err)
+						if retryErr != nil {
+							failedUpdate = true
+							unattributed = true
+						}
+						if len(batchErrors) > 0 {
+							failedUpdate = true
+							changeErrors = append(changeErrors, batchErrors...)
+						}
+					} else {
+						// any other error, e.g. throttling, is not caused by the changes, retrying them
+						// one by one would only add to the load and none of the records is to blame
+						failedUpdate = true
+						unattributed = true
+					}
 				} else {
 					// z is the R53 Hosted Zone ID already as aws.StringValue
 					log.Infof("%d record(s) in zone %s [Id: %s] were successfully updated", len(b), aws.StringValue(zones[z].Name), z)
@@ -544,13 +581,23 @@ func (p *AWSProvider) submitChanges(ctx context.Context, changes []*route53.Chan
 		}
 	}
 
-	if len(failedZones) > 0 {
+	if len(failedZones) > 0 && unattributed {
 		return errors.Errorf("failed to submit all changes for the following zones: %v", failedZones)
 	}
+	if len(failedZones) > 0 {
+		log.Errorf("Failed to submit all changes for the following zones: %v", failedZones)
+		return changeErrors
+	}
 
 	return nil
 }
 
+// isInvalidChangeBatch returns true if Route53 rejected a batch because of one of its changes
+func isInvalidChangeBatch(err error) bool {
+	var awsErr awserr.Error
+	return errors.As(err, &awsErr) && awsErr.Code() == route53.ErrCodeInvalidChangeBatch
+}
+
 // newChanges returns a collection of Changes based on the given records and action.
 func (p *AWSProvider) newChanges(action string, endpoints []*endpoint.Endpoint) []*route53.Change {
 	changes := make([]*route53.Change, 0, len(endpoints))
@@ -705,6 +752,70 @@ func (p *AWSProvider) tagsForZone(ctx context.Context, zoneID string) (map[strin
 	return tagMap, nil
 }
 
+// submitChangesByName submits the changes of each name of a failed batch separately, pausing batchChangeInterval
+// between the calls, and returns the errors of the changes which were rejected as invalid again. batchErr is reported
+// for all changes if the batch contains a single name only. Any other failure of a retry is returned as error, it is
+// not caused by the changes.
+func (p *AWSProvider) submitChangesByName(ctx context.Context, zoneID string, batch []*route53.Change, batchErr error) (provider.ChangeErrors, error) {
+	var names []string
+	changesByName := make(map[string][]*route53.Change)
+	for _, c := range batch {
+		name := aws.StringValue(c.ResourceRecordSet.Name)
+		if _, ok := changesByName[name]; !ok {
+			names = append(names, name)
+		}
+		changesByName[name] = append(changesByName[name], c)
+	}
+
+	if len(names) == 1 {
+		return changeErrorsFor(batch, batchErr), nil
+	}
+
+	var changeErrors provider.ChangeErrors
+	var retryErr error
+	for i, name := range names {
+		if i > 0 {
+			time.Sleep(p.batchChangeInterval)
+		}
+		params := &route53.ChangeResourceRecordSetsInput{
+			HostedZoneId: aws.String(zoneID),
+			ChangeBatch: &route53.ChangeBatch{
+				Changes: changesByName[name],
+			},
+		}
+		if _, err := p.client.ChangeResourceRecordSetsWithContext(ctx, params); err != nil {
+			log.Errorf("Failed to submit changes of %s [Id: %s]: %v", name, zoneID, err)
+			if !isInvalidChangeBatch(err) {
+				retryErr = err
+				continue
+			}
+			changeErrors = append(changeErrors, changeErrorsFor(changesByName[name], err)...)
+		}
+	}
+	return changeErrors, retryErr
+}
+
+// changeErrorsFor returns err as the error of each of the changes. The errors refer to the endpoints the changes
+// were created from, alias records are created from CNAME endpoints as in Records.
+func changeErrorsFor(changes []*route53.Change, err error) provider.ChangeErrors {
+	changeErrors := make(provider.ChangeErrors, 0, len(changes))
+	for _, c := range changes {
+		recordType := aws.StringValue(c.ResourceRecordSet.Type)
+		if c.ResourceRecordSet.AliasTarget != nil {
+			recordType = endpoint.RecordTypeCNAME
+		}
+		changeErrors = append(changeErrors, &provider.RecordError{
+			Endpoint: &endpoint.Endpoint{
+				DNSName:       wildcardUnescape(aws.StringValue(c.ResourceRecordSet.Name)),
+				RecordType:    recordType,
+				SetIdentifier: aws.StringValue(c.ResourceRecordSet.SetIdentifier),
+			},
+			Err: err,
+		})
+	}
+	return changeErrors
+}
+
 func batchChangeSet(cs []*route53.Change, batchSize int) [][]*route53.Change {
 	if len(cs) <= batchSize {
 		res := sortChangesByActionNameType(cs)
@@ -18,6 +18,7 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"sort"
@@ -26,6 +27,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/stretchr/testify/assert"
@@ -178,7 +180,7 @@ func (r *Route53APIStub) ChangeResourceRecordSetsWithContext(ctx context.Context
 		if aws.StringValue(change.ResourceRecordSet.Type) == route53.RRTypeA {
 			for _, rrs := range change.ResourceRecordSet.ResourceRecords {
 				if net.ParseIP(aws.StringValue(rrs.Value)) == nil {
-					return nil, fmt.Errorf("A records must point to IPs")
+					return nil, awserr.New(route53.ErrCodeInvalidChangeBatch, "A records must point to IPs", nil)
 				}
 			}
 		}
@@ -197,12 +199,12 @@ func (r *Route53APIStub) ChangeResourceRecordSetsWithContext(ctx context.Context
 		switch aws.StringValue(change.Action) {
 		case route53.ChangeActionCreate:
 			if _, found := recordSets[key]; found {
-				return nil, fmt.Errorf("Attempt to create duplicate rrset %s", key) // TODO: Return AWS errors with codes etc
+				return nil, awserr.New(route53.ErrCodeInvalidChangeBatch, fmt.Sprintf("Attempt to create duplicate rrset %s", key), nil)
 			}
 			recordSets[key] = append(recordSets[key], change.ResourceRecordSet)
 		case route53.ChangeActionDelete:
 			if _, found := recordSets[key]; !found {
-				return nil, fmt.Errorf("Attempt to delete non-existent rrset %s", key) // TODO: Check other fields too
+				return nil, awserr.New(route53.ErrCodeInvalidChangeBatch, fmt.Sprintf("Attempt to delete non-existent rrset %s", key), nil) // TODO: Check other fields too
 			}
 			delete(recordSets, key)
 		case route53.ChangeActionUpsert:
@@ -340,6 +342,10 @@ func TestAWSRecords(t *testing.T) {
 	provider, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), false, false, []*endpoint.Endpoint{
 		endpoint.NewEndpointWithTTL("list-test.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.2.3.4"),
 		endpoint.NewEndpointWithTTL("list-test.zone-2.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "8.8.8.8"),
+		endpoint.NewEndpointWithTTL("list-test-ipv6.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeAAAA, endpoint.TTL(recordTTL), "2001:db8::1"),
+		endpoint.NewEndpointWithTTL("list-test-mx.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeMX, endpoint.TTL(recordTTL), "10 mail.example.com", "20 backup.example.com"),
+		endpoint.NewEndpointWithTTL("list-test-caa.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCAA, endpoint.TTL(recordTTL), `0 issue "letsencrypt.org"`),
+		endpoint.NewEndpointWithTTL("_sip._tcp.list-test-srv.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeSRV, endpoint.TTL(recordTTL), "10 5 5060 sip.example.com"),
 		endpoint.NewEndpointWithTTL("*.wildcard-test.zone-2.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "8.8.8.8"),
 		endpoint.NewEndpoint("list-test-alias.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "foo.eu-central-1.elb.amazonaws.com").WithProviderSpecific(providerSpecificEvaluateTargetHealth, "false"),
 		endpoint.NewEndpoint("*.wildcard-test-alias.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "foo.eu-central-1.elb.amazonaws.com").WithProviderSpecific(providerSpecificEvaluateTargetHealth, "false"),
@@ -364,6 +370,10 @@ func TestAWSRecords(t *testing.T) {
 	validateEndpoints(t, records, []*endpoint.Endpoint{
 		endpoint.NewEndpointWithTTL("list-test.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.2.3.4"),
 		endpoint.NewEndpointWithTTL("list-test.zone-2.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "8.8.8.8"),
+		endpoint.NewEndpointWithTTL("list-test-ipv6.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeAAAA, endpoint.TTL(recordTTL), "2001:db8::1"),
+		endpoint.NewEndpointWithTTL("list-test-mx.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeMX, endpoint.TTL(recordTTL), "10 mail.example.com", "20 backup.example.com"),
+		endpoint.NewEndpointWithTTL("list-test-caa.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCAA, endpoint.TTL(recordTTL), `0 issue "letsencrypt.org"`),
+		endpoint.NewEndpointWithTTL("_sip._tcp.list-test-srv.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeSRV, endpoint.TTL(recordTTL), "10 5 5060 sip.example.com"),
 		endpoint.NewEndpointWithTTL("*.wildcard-test.zone-2.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "8.8.8.8"),
 		endpoint.NewEndpointWithTTL("list-test-alias.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, endpoint.TTL(recordTTL), "foo.eu-central-1.elb.amazonaws.com").WithProviderSpecific(providerSpecificEvaluateTargetHealth, "false").WithProviderSpecific(providerSpecificAlias, "true"),
 		endpoint.NewEndpointWithTTL("*.wildcard-test-alias.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, endpoint.TTL(recordTTL), "foo.eu-central-1.elb.amazonaws.com").WithProviderSpecific(providerSpecificEvaluateTargetHealth, "false").WithProviderSpecific(providerSpecificAlias, "true"),
@@ -794,17 +804,117 @@ func TestAWSsubmitChanges(t *testing.T) {
 }
 
 func TestAWSsubmitChangesError(t *testing.T) {
-	provider, clientStub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, []*endpoint.Endpoint{})
+	p, clientStub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, []*endpoint.Endpoint{})
+	clientStub.MockMethod("ChangeResourceRecordSets", mock.Anything).Return(nil, awserr.New(route53.ErrCodeInvalidChangeBatch, "Mock route53 failure", nil))
+
+	ctx := context.Background()
+	zones, err := p.Zones(ctx)
+	require.NoError(t, err)
+
+	ep := endpoint.NewEndpointWithTTL("fail.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.0.0.1")
+	cs := p.newChanges(route53.ChangeActionCreate, []*endpoint.Endpoint{ep})
+
+	err = p.submitChanges(ctx, cs, zones)
+	require.Error(t, err)
+	assert.Error(t, provider.RecordErrorFor(err, ep), "the record should have failed")
+	assert.NoError(t, provider.RecordErrorFor(err, endpoint.NewEndpoint("other.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.0.0.2")), "other records should not have failed")
+}
+
+func TestAWSsubmitChangesUnattributedError(t *testing.T) {
+	p, clientStub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, []*endpoint.Endpoint{})
 	clientStub.MockMethod("ChangeResourceRecordSets", mock.Anything).Return(nil, fmt.Errorf("Mock route53 failure"))
 
 	ctx := context.Background()
-	zones, err := provider.Zones(ctx)
+	zones, err := p.Zones(ctx)
 	require.NoError(t, err)
 
 	ep := endpoint.NewEndpointWithTTL("fail.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.0.0.1")
-	cs := provider.newChanges(route53.ChangeActionCreate, []*endpoint.Endpoint{ep})
+	cs := p.newChanges(route53.ChangeActionCreate, []*endpoint.Endpoint{ep})
+
+	err = p.submitChanges(ctx, cs, zones)
+	require.Error(t, err)
+	var changeErrors provider.ChangeErrors
+	assert.False(t, errors.As(err, &changeErrors), "failures not caused by the changes must not be attributed to records")
+}
+
+func TestAWSsubmitChangesPartialFailure(t *testing.T) {
+	p, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, []*endpoint.Endpoint{})
+	p.batchChangeInterval = 0
+
+	ctx := context.Background()
+	zones, err := p.Zones(ctx)
+	require.NoError(t, err)
+
+	bad := endpoint.NewEndpointWithTTL("bad.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "not-an-ip")
+	good := endpoint.NewEndpointWithTTL("good.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.0.0.1")
+	cs := p.newChanges(route53.ChangeActionCreate, []*endpoint.Endpoint{bad, good})
+
+	err = p.submitChanges(ctx, cs, zones)
+	require.Error(t, err)
+	assert.Error(t, provider.RecordErrorFor(err, bad), "the invalid record should have failed")
+	assert.NoError(t, provider.RecordErrorFor(err, good), "the valid record should have been applied")
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	validateEndpoints(t, records, []*endpoint.Endpoint{good})
+}
+
+func TestAWSsubmitChangesRetrySucceeds(t *testing.T) {
+	p, clientStub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, []*endpoint.Endpoint{})
+	p.batchChangeInterval = 0
+	clientStub.MockMethod("ChangeResourceRecordSets", mock.Anything).Return(nil, awserr.New(route53.ErrCodeInvalidChangeBatch, "Mock route53 failure", nil)).Once()
+
+	ctx := context.Background()
+	zones, err := p.Zones(ctx)
+	require.NoError(t, err)
+
+	first := endpoint.NewEndpointWithTTL("first.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.0.0.1")
+	second := endpoint.NewEndpointWithTTL("second.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.0.0.2")
+	cs := p.newChanges(route53.ChangeActionCreate, []*endpoint.Endpoint{first, second})
+
+	assert.NoError(t, p.submitChanges(ctx, cs, zones), "all changes were applied on retry")
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	validateEndpoints(t, records, []*endpoint.Endpoint{first, second})
+}
+
+func TestAWSsubmitChangesThrottled(t *testing.T) {
+	p, clientStub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, []*endpoint.Endpoint{})
+	clientStub.MockMethod("ChangeResourceRecordSets", mock.Anything).Return(nil, awserr.New("Throttling", "Rate exceeded", nil))
+
+	ctx := context.Background()
+	zones, err := p.Zones(ctx)
+	require.NoError(t, err)
+
+	first := endpoint.NewEndpointWithTTL("first.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.0.0.1")
+	second := endpoint.NewEndpointWithTTL("second.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.0.0.2")
+	cs := p.newChanges(route53.ChangeActionCreate, []*endpoint.Endpoint{first, second})
+
+	err = p.submitChanges(ctx, cs, zones)
+	require.Error(t, err)
+	var changeErrors provider.ChangeErrors
+	assert.False(t, errors.As(err, &changeErrors), "throttling must fail the whole synchronization")
+	clientStub.m.AssertNumberOfCalls(t, "ChangeResourceRecordSets", 1)
+}
+
+func TestAWSsubmitChangesAliasError(t *testing.T) {
+	p, clientStub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, []*endpoint.Endpoint{})
+	clientStub.MockMethod("ChangeResourceRecordSets", mock.Anything).Return(nil, awserr.New(route53.ErrCodeInvalidChangeBatch, "Mock route53 failure", nil))
+
+	ctx := context.Background()
+	zones, err := p.Zones(ctx)
+	require.NoError(t, err)
+
+	alias := endpoint.NewEndpoint("alias.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "foo.eu-central-1.elb.amazonaws.com").
+		WithProviderSpecific(providerSpecificAlias, "true").
+		WithProviderSpecific(providerSpecificEvaluateTargetHealth, "true")
+	alias.Labels[endpoint.DualstackLabelKey] = "true"
+	cs := p.newChanges(route53.ChangeActionCreate, []*endpoint.Endpoint{alias})
 
-	require.Error(t, provider.submitChanges(ctx, cs, zones))
+	err = p.submitChanges(ctx, cs, zones)
+	require.Error(t, err)
+	assert.Error(t, provider.RecordErrorFor(err, alias), "the error of the alias record should refer to its CNAME endpoint")
 }
 
 func TestAWSBatchChangeSet(t *testing.T) {
@@ -509,7 +509,7 @@ func (p *AWSSDProvider) DeleteService(service *sd.Service) error {
 		// convert ownerID string to service description format
 		label := endpoint.NewLabels()
 		label[endpoint.OwnerLabelKey] = p.ownerID
-		label[endpoint.AWSSDDescriptionLabel] = label.Serialize(false)
+		label[endpoint.AWSSDDescriptionLabel] = label.Serialize(false, nil)
 
 		if aws.StringValue(service.Description) == label[endpoint.AWSSDDescriptionLabel] {
 			log.Infof("Deleting service \"%s\"", *service.Name)
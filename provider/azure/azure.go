@@ -92,6 +92,17 @@ func NewAzureProvider(configFile string, domainFilter endpoint.DomainFilter, zon
 	}, nil
 }
 
+// supportedRecordType returns true for the record types of provider.SupportedRecordType and
+// the AAAA, MX, CAA and PTR records Azure DNS supports in addition
+func supportedRecordType(recordType string) bool {
+	switch recordType {
+	case endpoint.RecordTypeAAAA, endpoint.RecordTypeMX, endpoint.RecordTypeCAA, endpoint.RecordTypePTR:
+		return true
+	default:
+		return provider.SupportedRecordType(recordType)
+	}
+}
+
 // Records gets the current records.
 //
 // Returns the current records or an error if the operation failed.
@@ -108,7 +119,7 @@ func (p *AzureProvider) Records(ctx context.Context) (endpoints []*endpoint.Endp
 				return true
 			}
 			recordType := strings.TrimPrefix(*recordSet.Type, "Microsoft.Network/dnszones/")
-			if !provider.SupportedRecordType(recordType) {
+			if !supportedRecordType(recordType) {
 				return true
 			}
 			name := formatAzureDNSName(*recordSet.Name, *zone.Name)
@@ -367,6 +378,19 @@ func (p *AzureProvider) newRecordSet(endpoint *endpoint.Endpoint) (dns.RecordSet
 				ARecords: &aRecords,
 			},
 		}, nil
+	case dns.AAAA:
+		aaaaRecords := make([]dns.AaaaRecord, len(endpoint.Targets))
+		for i, target := range endpoint.Targets {
+			aaaaRecords[i] = dns.AaaaRecord{
+				Ipv6Address: to.StringPtr(target),
+			}
+		}
+		return dns.RecordSet{
+			RecordSetProperties: &dns.RecordSetProperties{
+				TTL:         to.Int64Ptr(ttl),
+				AaaaRecords: &aaaaRecords,
+			},
+		}, nil
 	case dns.CNAME:
 		return dns.RecordSet{
 			RecordSetProperties: &dns.RecordSetProperties{
@@ -376,17 +400,61 @@ func (p *AzureProvider) newRecordSet(endpoint *endpoint.Endpoint) (dns.RecordSet
 				},
 			},
 		}, nil
+	case dns.MX:
+		mxRecords, err := newAzureMXRecords(endpoint.Targets)
+		if err != nil {
+			return dns.RecordSet{}, err
+		}
+		return dns.RecordSet{
+			RecordSetProperties: &dns.RecordSetProperties{
+				TTL:       to.Int64Ptr(ttl),
+				MxRecords: &mxRecords,
+			},
+		}, nil
+	case dns.SRV:
+		srvRecords, err := newAzureSRVRecords(endpoint.Targets)
+		if err != nil {
+			return dns.RecordSet{}, err
+		}
+		return dns.RecordSet{
+			RecordSetProperties: &dns.RecordSetProperties{
+				TTL:        to.Int64Ptr(ttl),
+				SrvRecords: &srvRecords,
+			},
+		}, nil
+	case dns.CAA:
+		caaRecords, err := newAzureCAARecords(endpoint.Targets)
+		if err != nil {
+			return dns.RecordSet{}, err
+		}
+		return dns.RecordSet{
+			RecordSetProperties: &dns.RecordSetProperties{
+				TTL:        to.Int64Ptr(ttl),
+				CaaRecords: &caaRecords,
+			},
+		}, nil
+	case dns.PTR:
+		ptrRecords := make([]dns.PtrRecord, len(endpoint.Targets))
+		for i, target := range endpoint.Targets {
+			ptrRecords[i] = dns.PtrRecord{
+				Ptrdname: to.StringPtr(target),
+			}
+		}
+		return dns.RecordSet{
+			RecordSetProperties: &dns.RecordSetProperties{
+				TTL:        to.Int64Ptr(ttl),
+				PtrRecords: &ptrRecords,
+			},
+		}, nil
 	case dns.TXT:
+		txtRecords, err := newAzureTXTRecords(endpoint.Targets)
+		if err != nil {
+			return dns.RecordSet{}, err
+		}
 		return dns.RecordSet{
 			RecordSetProperties: &dns.RecordSetProperties{
-				TTL: to.Int64Ptr(ttl),
-				TxtRecords: &[]dns.TxtRecord{
-					{
-						Value: &[]string{
-							endpoint.Targets[0],
-						},
-					},
-				},
+				TTL:        to.Int64Ptr(ttl),
+				TxtRecords: &txtRecords,
 			},
 		}, nil
 	}
@@ -418,19 +486,155 @@ func extractAzureTargets(recordSet *dns.RecordSet) []string {
 		return targets
 	}
 
+	// Check for AAAA records
+	aaaaRecords := properties.AaaaRecords
+	if aaaaRecords != nil && len(*aaaaRecords) > 0 && (*aaaaRecords)[0].Ipv6Address != nil {
+		targets := make([]string, len(*aaaaRecords))
+		for i, aaaaRecord := range *aaaaRecords {
+			targets[i] = *aaaaRecord.Ipv6Address
+		}
+		return targets
+	}
+
 	// Check for CNAME records
 	cnameRecord := properties.CnameRecord
 	if cnameRecord != nil && cnameRecord.Cname != nil {
 		return []string{*cnameRecord.Cname}
 	}
 
+	// Check for PTR records
+	ptrRecords := properties.PtrRecords
+	if ptrRecords != nil && len(*ptrRecords) > 0 && (*ptrRecords)[0].Ptrdname != nil {
+		targets := make([]string, len(*ptrRecords))
+		for i, ptrRecord := range *ptrRecords {
+			targets[i] = *ptrRecord.Ptrdname
+		}
+		return targets
+	}
+
+	// Check for MX records
+	mxRecords := properties.MxRecords
+	if mxRecords != nil && len(*mxRecords) > 0 {
+		targets := make([]string, len(*mxRecords))
+		for i, mxRecord := range *mxRecords {
+			targets[i] = (&endpoint.MXTarget{Preference: uint16(to.Int32(mxRecord.Preference)), Exchange: to.String(mxRecord.Exchange)}).String()
+		}
+		return targets
+	}
+
+	// Check for SRV records
+	srvRecords := properties.SrvRecords
+	if srvRecords != nil && len(*srvRecords) > 0 {
+		targets := make([]string, len(*srvRecords))
+		for i, srvRecord := range *srvRecords {
+			targets[i] = (&endpoint.SRVTarget{Priority: uint16(to.Int32(srvRecord.Priority)), Weight: uint16(to.Int32(srvRecord.Weight)), Port: uint16(to.Int32(srvRecord.Port)), Target: to.String(srvRecord.Target)}).String()
+		}
+		return targets
+	}
+
+	// Check for CAA records
+	caaRecords := properties.CaaRecords
+	if caaRecords != nil && len(*caaRecords) > 0 {
+		targets := make([]string, len(*caaRecords))
+		for i, caaRecord := range *caaRecords {
+			targets[i] = (&endpoint.CAATarget{Flags: uint8(to.Int32(caaRecord.Flags)), Tag: to.String(caaRecord.Tag), Value: to.String(caaRecord.Value)}).String()
+		}
+		return targets
+	}
+
 	// Check for TXT records
 	txtRecords := properties.TxtRecords
-	if txtRecords != nil && len(*txtRecords) > 0 && (*txtRecords)[0].Value != nil {
-		values := (*txtRecords)[0].Value
-		if values != nil && len(*values) > 0 {
-			return []string{(*values)[0]}
+	if txtRecords != nil && len(*txtRecords) > 0 {
+		targets := []string{}
+		for _, txtRecord := range *txtRecords {
+			if txtRecord.Value != nil && len(*txtRecord.Value) > 0 {
+				targets = append(targets, azureTXTTarget(*txtRecord.Value))
+			}
 		}
+		return targets
 	}
 	return []string{}
 }
+
+func newAzureMXRecords(targets []string) ([]dns.MxRecord, error) {
+	mxRecords := make([]dns.MxRecord, len(targets))
+	for i, target := range targets {
+		mx, err := endpoint.ParseMXTarget(target)
+		if err != nil {
+			return nil, err
+		}
+		mxRecords[i] = dns.MxRecord{
+			Preference: to.Int32Ptr(int32(mx.Preference)),
+			Exchange:   to.StringPtr(mx.Exchange),
+		}
+	}
+	return mxRecords, nil
+}
+
+func newAzureSRVRecords(targets []string) ([]dns.SrvRecord, error) {
+	srvRecords := make([]dns.SrvRecord, len(targets))
+	for i, target := range targets {
+		srv, err := endpoint.ParseSRVTarget(target)
+		if err != nil {
+			return nil, err
+		}
+		srvRecords[i] = dns.SrvRecord{
+			Priority: to.Int32Ptr(int32(srv.Priority)),
+			Weight:   to.Int32Ptr(int32(srv.Weight)),
+			Port:     to.Int32Ptr(int32(srv.Port)),
+			Target:   to.StringPtr(srv.Target),
+		}
+	}
+	return srvRecords, nil
+}
+
+func newAzureCAARecords(targets []string) ([]dns.CaaRecord, error) {
+	caaRecords := make([]dns.CaaRecord, len(targets))
+	for i, target := range targets {
+		caa, err := endpoint.ParseCAATarget(target)
+		if err != nil {
+			return nil, err
+		}
+		caaRecords[i] = dns.CaaRecord{
+			Flags: to.Int32Ptr(int32(caa.Flags)),
+			Tag:   to.StringPtr(caa.Tag),
+			Value: to.StringPtr(caa.Value),
+		}
+	}
+	return caaRecords, nil
+}
+
+func newAzureTXTRecords(targets []string) ([]dns.TxtRecord, error) {
+	txtRecords := make([]dns.TxtRecord, len(targets))
+	for i, target := range targets {
+		values, err := azureTXTValues(target)
+		if err != nil {
+			return nil, err
+		}
+		txtRecords[i] = dns.TxtRecord{
+			Value: &values,
+		}
+	}
+	return txtRecords, nil
+}
+
+// azureTXTValues returns the character strings of a TXT target. Targets fitting into a single
+// character string are stored as they are, like before long TXT records were supported.
+func azureTXTValues(target string) ([]string, error) {
+	txt, err := endpoint.ParseTXTTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	if len(txt) == 1 {
+		return []string{target}, nil
+	}
+	return txt, nil
+}
+
+// azureTXTTarget returns the TXT target of the character strings of a TXT record
+func azureTXTTarget(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return endpoint.TXTTarget(values).String()
+}
@@ -357,6 +357,19 @@ func (p *AzurePrivateDNSProvider) newRecordSet(endpoint *endpoint.Endpoint) (pri
 				ARecords: &aRecords,
 			},
 		}, nil
+	case privatedns.AAAA:
+		aaaaRecords := make([]privatedns.AaaaRecord, len(endpoint.Targets))
+		for i, target := range endpoint.Targets {
+			aaaaRecords[i] = privatedns.AaaaRecord{
+				Ipv6Address: to.StringPtr(target),
+			}
+		}
+		return privatedns.RecordSet{
+			RecordSetProperties: &privatedns.RecordSetProperties{
+				TTL:         to.Int64Ptr(ttl),
+				AaaaRecords: &aaaaRecords,
+			},
+		}, nil
 	case privatedns.CNAME:
 		return privatedns.RecordSet{
 			RecordSetProperties: &privatedns.RecordSetProperties{
@@ -366,17 +379,50 @@ func (p *AzurePrivateDNSProvider) newRecordSet(endpoint *endpoint.Endpoint) (pri
 				},
 			},
 		}, nil
+	case privatedns.MX:
+		mxRecords, err := newAzurePrivateDNSMXRecords(endpoint.Targets)
+		if err != nil {
+			return privatedns.RecordSet{}, err
+		}
+		return privatedns.RecordSet{
+			RecordSetProperties: &privatedns.RecordSetProperties{
+				TTL:       to.Int64Ptr(ttl),
+				MxRecords: &mxRecords,
+			},
+		}, nil
+	case privatedns.SRV:
+		srvRecords, err := newAzurePrivateDNSSRVRecords(endpoint.Targets)
+		if err != nil {
+			return privatedns.RecordSet{}, err
+		}
+		return privatedns.RecordSet{
+			RecordSetProperties: &privatedns.RecordSetProperties{
+				TTL:        to.Int64Ptr(ttl),
+				SrvRecords: &srvRecords,
+			},
+		}, nil
+	case privatedns.PTR:
+		ptrRecords := make([]privatedns.PtrRecord, len(endpoint.Targets))
+		for i, target := range endpoint.Targets {
+			ptrRecords[i] = privatedns.PtrRecord{
+				Ptrdname: to.StringPtr(target),
+			}
+		}
+		return privatedns.RecordSet{
+			RecordSetProperties: &privatedns.RecordSetProperties{
+				TTL:        to.Int64Ptr(ttl),
+				PtrRecords: &ptrRecords,
+			},
+		}, nil
 	case privatedns.TXT:
+		txtRecords, err := newAzurePrivateDNSTXTRecords(endpoint.Targets)
+		if err != nil {
+			return privatedns.RecordSet{}, err
+		}
 		return privatedns.RecordSet{
 			RecordSetProperties: &privatedns.RecordSetProperties{
-				TTL: to.Int64Ptr(ttl),
-				TxtRecords: &[]privatedns.TxtRecord{
-					{
-						Value: &[]string{
-							endpoint.Targets[0],
-						},
-					},
-				},
+				TTL:        to.Int64Ptr(ttl),
+				TxtRecords: &txtRecords,
 			},
 		}, nil
 	}
@@ -400,19 +446,108 @@ func extractAzurePrivateDNSTargets(recordSet *privatedns.RecordSet) []string {
 		return targets
 	}
 
+	// Check for AAAA records
+	aaaaRecords := properties.AaaaRecords
+	if aaaaRecords != nil && len(*aaaaRecords) > 0 && (*aaaaRecords)[0].Ipv6Address != nil {
+		targets := make([]string, len(*aaaaRecords))
+		for i, aaaaRecord := range *aaaaRecords {
+			targets[i] = *aaaaRecord.Ipv6Address
+		}
+		return targets
+	}
+
 	// Check for CNAME records
 	cnameRecord := properties.CnameRecord
 	if cnameRecord != nil && cnameRecord.Cname != nil {
 		return []string{*cnameRecord.Cname}
 	}
 
+	// Check for PTR records
+	ptrRecords := properties.PtrRecords
+	if ptrRecords != nil && len(*ptrRecords) > 0 && (*ptrRecords)[0].Ptrdname != nil {
+		targets := make([]string, len(*ptrRecords))
+		for i, ptrRecord := range *ptrRecords {
+			targets[i] = *ptrRecord.Ptrdname
+		}
+		return targets
+	}
+
+	// Check for MX records
+	mxRecords := properties.MxRecords
+	if mxRecords != nil && len(*mxRecords) > 0 {
+		targets := make([]string, len(*mxRecords))
+		for i, mxRecord := range *mxRecords {
+			targets[i] = (&endpoint.MXTarget{Preference: uint16(to.Int32(mxRecord.Preference)), Exchange: to.String(mxRecord.Exchange)}).String()
+		}
+		return targets
+	}
+
+	// Check for SRV records
+	srvRecords := properties.SrvRecords
+	if srvRecords != nil && len(*srvRecords) > 0 {
+		targets := make([]string, len(*srvRecords))
+		for i, srvRecord := range *srvRecords {
+			targets[i] = (&endpoint.SRVTarget{Priority: uint16(to.Int32(srvRecord.Priority)), Weight: uint16(to.Int32(srvRecord.Weight)), Port: uint16(to.Int32(srvRecord.Port)), Target: to.String(srvRecord.Target)}).String()
+		}
+		return targets
+	}
+
 	// Check for TXT records
 	txtRecords := properties.TxtRecords
-	if txtRecords != nil && len(*txtRecords) > 0 && (*txtRecords)[0].Value != nil {
-		values := (*txtRecords)[0].Value
-		if values != nil && len(*values) > 0 {
-			return []string{(*values)[0]}
+	if txtRecords != nil && len(*txtRecords) > 0 {
+		targets := []string{}
+		for _, txtRecord := range *txtRecords {
+			if txtRecord.Value != nil && len(*txtRecord.Value) > 0 {
+				targets = append(targets, azureTXTTarget(*txtRecord.Value))
+			}
 		}
+		return targets
 	}
 	return []string{}
 }
+
+func newAzurePrivateDNSMXRecords(targets []string) ([]privatedns.MxRecord, error) {
+	mxRecords := make([]privatedns.MxRecord, len(targets))
+	for i, target := range targets {
+		mx, err := endpoint.ParseMXTarget(target)
+		if err != nil {
+			return nil, err
+		}
+		mxRecords[i] = privatedns.MxRecord{
+			Preference: to.Int32Ptr(int32(mx.Preference)),
+			Exchange:   to.StringPtr(mx.Exchange),
+		}
+	}
+	return mxRecords, nil
+}
+
+func newAzurePrivateDNSSRVRecords(targets []string) ([]privatedns.SrvRecord, error) {
+	srvRecords := make([]privatedns.SrvRecord, len(targets))
+	for i, target := range targets {
+		srv, err := endpoint.ParseSRVTarget(target)
+		if err != nil {
+			return nil, err
+		}
+		srvRecords[i] = privatedns.SrvRecord{
+			Priority: to.Int32Ptr(int32(srv.Priority)),
+			Weight:   to.Int32Ptr(int32(srv.Weight)),
+			Port:     to.Int32Ptr(int32(srv.Port)),
+			Target:   to.StringPtr(srv.Target),
+		}
+	}
+	return srvRecords, nil
+}
+
+func newAzurePrivateDNSTXTRecords(targets []string) ([]privatedns.TxtRecord, error) {
+	txtRecords := make([]privatedns.TxtRecord, len(targets))
+	for i, target := range targets {
+		values, err := azureTXTValues(target)
+		if err != nil {
+			return nil, err
+		}
+		txtRecords[i] = privatedns.TxtRecord{
+			Value: &values,
+		}
+	}
+	return txtRecords, nil
+}
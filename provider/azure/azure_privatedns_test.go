@@ -18,6 +18,7 @@ package azure
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/services/privatedns/mgmt/2018-09-01/privatedns"
@@ -319,6 +320,31 @@ func TestAzurePrivateDNSMultiRecord(t *testing.T) {
 	validateAzureEndpoints(t, actual, expected)
 }
 
+func TestAzurePrivateDNSRecordData(t *testing.T) {
+	azureProvider := &AzurePrivateDNSProvider{}
+	longTXT := strings.Repeat("a", 300)
+
+	for _, ep := range []*endpoint.Endpoint{
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeMX, "10 mail.example.com", "20 backup.example.com"),
+		endpoint.NewEndpoint("_sip._tcp.example.com", endpoint.RecordTypeSRV, "10 5 5060 sip.example.com"),
+		endpoint.NewEndpoint("1.2.0.192.in-addr.arpa", endpoint.RecordTypePTR, "bar.example.com", "foo.example.com"),
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeTXT, "v=spf1 -all", `"heritage=external-dns,external-dns/owner=default"`),
+		endpoint.NewEndpoint("long.example.com", endpoint.RecordTypeTXT, `"`+longTXT[:255]+`" "`+longTXT[255:]+`"`),
+	} {
+		recordSet, err := azureProvider.newRecordSet(ep)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if targets := extractAzurePrivateDNSTargets(&recordSet); strings.Join(targets, ",") != strings.Join(ep.Targets, ",") {
+			t.Errorf("expected targets %v, got %v", ep.Targets, targets)
+		}
+	}
+
+	if _, err := azureProvider.newRecordSet(endpoint.NewEndpoint("example.com", endpoint.RecordTypeCAA, `0 issue "letsencrypt.org"`)); err == nil {
+		t.Error("expected an error for CAA records, which Azure Private DNS does not support")
+	}
+}
+
 func TestAzurePrivateDNSApplyChanges(t *testing.T) {
 	recordsClient := mockPrivateRecordSetsClient{}
 
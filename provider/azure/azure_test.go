@@ -18,6 +18,7 @@ package azure
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/services/dns/mgmt/2018-05-01/dns"
@@ -113,6 +114,19 @@ func aRecordSetPropertiesGetter(values []string, ttl int64) *dns.RecordSetProper
 	}
 }
 
+func aaaaRecordSetPropertiesGetter(values []string, ttl int64) *dns.RecordSetProperties {
+	aaaaRecords := make([]dns.AaaaRecord, len(values))
+	for i, value := range values {
+		aaaaRecords[i] = dns.AaaaRecord{
+			Ipv6Address: to.StringPtr(value),
+		}
+	}
+	return &dns.RecordSetProperties{
+		TTL:         to.Int64Ptr(ttl),
+		AaaaRecords: &aaaaRecords,
+	}
+}
+
 func cNameRecordSetPropertiesGetter(values []string, ttl int64) *dns.RecordSetProperties {
 	return &dns.RecordSetProperties{
 		TTL: to.Int64Ptr(ttl),
@@ -153,6 +167,8 @@ func createMockRecordSetMultiWithTTL(name, recordType string, ttl int64, values
 	switch recordType {
 	case endpoint.RecordTypeA:
 		getterFunc = aRecordSetPropertiesGetter
+	case endpoint.RecordTypeAAAA:
+		getterFunc = aaaaRecordSetPropertiesGetter
 	case endpoint.RecordTypeCNAME:
 		getterFunc = cNameRecordSetPropertiesGetter
 	case endpoint.RecordTypeTXT:
@@ -270,6 +286,7 @@ func TestAzureRecord(t *testing.T) {
 			createMockRecordSet("@", endpoint.RecordTypeTXT, "heritage=external-dns,external-dns/owner=default"),
 			createMockRecordSetWithTTL("nginx", endpoint.RecordTypeA, "123.123.123.123", 3600),
 			createMockRecordSetWithTTL("nginx", endpoint.RecordTypeTXT, "heritage=external-dns,external-dns/owner=default", recordTTL),
+			createMockRecordSetWithTTL("nginx", endpoint.RecordTypeAAAA, "2001:db8::1", 3600),
 			createMockRecordSetWithTTL("hack", endpoint.RecordTypeCNAME, "hack.azurewebsites.net", 10),
 		})
 	if err != nil {
@@ -286,6 +303,7 @@ func TestAzureRecord(t *testing.T) {
 		endpoint.NewEndpoint("example.com", endpoint.RecordTypeTXT, "heritage=external-dns,external-dns/owner=default"),
 		endpoint.NewEndpointWithTTL("nginx.example.com", endpoint.RecordTypeA, 3600, "123.123.123.123"),
 		endpoint.NewEndpointWithTTL("nginx.example.com", endpoint.RecordTypeTXT, recordTTL, "heritage=external-dns,external-dns/owner=default"),
+		endpoint.NewEndpointWithTTL("nginx.example.com", endpoint.RecordTypeAAAA, 3600, "2001:db8::1"),
 		endpoint.NewEndpointWithTTL("hack.example.com", endpoint.RecordTypeCNAME, 10, "hack.azurewebsites.net"),
 	}
 
@@ -326,6 +344,31 @@ func TestAzureMultiRecord(t *testing.T) {
 	validateAzureEndpoints(t, actual, expected)
 }
 
+func TestAzureRecordData(t *testing.T) {
+	azureProvider := &AzureProvider{}
+	longTXT := strings.Repeat("a", 300)
+
+	for _, ep := range []*endpoint.Endpoint{
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeMX, "10 mail.example.com", "20 backup.example.com"),
+		endpoint.NewEndpoint("_sip._tcp.example.com", endpoint.RecordTypeSRV, "10 5 5060 sip.example.com"),
+		endpoint.NewEndpoint("1.2.0.192.in-addr.arpa", endpoint.RecordTypePTR, "bar.example.com", "foo.example.com"),
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeCAA, `0 issue "letsencrypt.org"`, `0 iodef "mailto:security@example.com"`),
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeTXT, "v=spf1 -all", `"heritage=external-dns,external-dns/owner=default"`),
+		endpoint.NewEndpoint("long.example.com", endpoint.RecordTypeTXT, `"`+longTXT[:255]+`" "`+longTXT[255:]+`"`),
+	} {
+		recordSet, err := azureProvider.newRecordSet(ep)
+		assert.NoError(t, err, ep.String())
+		assert.Equal(t, []string(ep.Targets), extractAzureTargets(&recordSet), ep.String())
+	}
+
+	recordSet, err := azureProvider.newRecordSet(endpoint.NewEndpoint("long.example.com", endpoint.RecordTypeTXT, `"`+longTXT[:255]+`" "`+longTXT[255:]+`"`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{longTXT[:255], longTXT[255:]}, *(*recordSet.TxtRecords)[0].Value)
+
+	_, err = azureProvider.newRecordSet(endpoint.NewEndpoint("example.com", endpoint.RecordTypeMX, "mail.example.com"))
+	assert.Error(t, err)
+}
+
 func TestAzureApplyChanges(t *testing.T) {
 	recordsClient := mockRecordSetsClient{}
 
@@ -342,6 +385,7 @@ func TestAzureApplyChanges(t *testing.T) {
 		endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.2.3.4"),
 		endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeTXT, endpoint.TTL(recordTTL), "tag"),
 		endpoint.NewEndpointWithTTL("foo.example.com", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.2.3.4", "1.2.3.5"),
+		endpoint.NewEndpointWithTTL("foo.example.com", endpoint.RecordTypeAAAA, endpoint.TTL(recordTTL), "2001:db8::1"),
 		endpoint.NewEndpointWithTTL("foo.example.com", endpoint.RecordTypeTXT, endpoint.TTL(recordTTL), "tag"),
 		endpoint.NewEndpointWithTTL("bar.example.com", endpoint.RecordTypeCNAME, endpoint.TTL(recordTTL), "other.com"),
 		endpoint.NewEndpointWithTTL("bar.example.com", endpoint.RecordTypeTXT, endpoint.TTL(recordTTL), "tag"),
@@ -403,6 +447,7 @@ func testAzureApplyChangesInternal(t *testing.T, dryRun bool, client RecordSetsC
 		endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "1.2.3.4"),
 		endpoint.NewEndpoint("example.com", endpoint.RecordTypeTXT, "tag"),
 		endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "1.2.3.5", "1.2.3.4"),
+		endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeAAAA, "2001:db8::1"),
 		endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeTXT, "tag"),
 		endpoint.NewEndpoint("bar.example.com", endpoint.RecordTypeCNAME, "other.com"),
 		endpoint.NewEndpoint("bar.example.com", endpoint.RecordTypeTXT, "tag"),
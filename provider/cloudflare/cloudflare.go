@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	cloudflare "github.com/cloudflare/cloudflare-go"
 	log "github.com/sirupsen/logrus"
@@ -228,13 +229,18 @@ func (p *CloudFlareProvider) Records(ctx context.Context) ([]*endpoint.Endpoint,
 }
 
 // ApplyChanges applies a given set of changes in a given zone.
+// Records whose targets cannot be converted to CloudFlare records are not submitted and returned as ChangeErrors.
 func (p *CloudFlareProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
 	cloudflareChanges := []*cloudFlareChange{}
+	changeErrors := provider.ChangeErrors{}
 
 	for _, endpoint := range changes.Create {
-		for _, target := range endpoint.Targets {
-			cloudflareChanges = append(cloudflareChanges, p.newCloudFlareChange(cloudFlareCreate, endpoint, target))
+		creates, err := p.newCloudFlareChanges(cloudFlareCreate, endpoint, endpoint.Targets)
+		if err != nil {
+			changeErrors = append(changeErrors, &provider.RecordError{Endpoint: endpoint, Err: err})
+			continue
 		}
+		cloudflareChanges = append(cloudflareChanges, creates...)
 	}
 
 	for i, desired := range changes.UpdateNew {
@@ -242,26 +248,42 @@ func (p *CloudFlareProvider) ApplyChanges(ctx context.Context, changes *plan.Cha
 
 		add, remove, leave := provider.Difference(current.Targets, desired.Targets)
 
-		for _, a := range remove {
-			cloudflareChanges = append(cloudflareChanges, p.newCloudFlareChange(cloudFlareDelete, current, a))
+		deletes, err := p.newCloudFlareChanges(cloudFlareDelete, current, remove)
+		if err != nil {
+			changeErrors = append(changeErrors, &provider.RecordError{Endpoint: desired, Err: err})
+			continue
 		}
-
-		for _, a := range add {
-			cloudflareChanges = append(cloudflareChanges, p.newCloudFlareChange(cloudFlareCreate, desired, a))
+		creates, err := p.newCloudFlareChanges(cloudFlareCreate, desired, add)
+		if err != nil {
+			changeErrors = append(changeErrors, &provider.RecordError{Endpoint: desired, Err: err})
+			continue
 		}
-
-		for _, a := range leave {
-			cloudflareChanges = append(cloudflareChanges, p.newCloudFlareChange(cloudFlareUpdate, desired, a))
+		updates, err := p.newCloudFlareChanges(cloudFlareUpdate, desired, leave)
+		if err != nil {
+			changeErrors = append(changeErrors, &provider.RecordError{Endpoint: desired, Err: err})
+			continue
 		}
+		cloudflareChanges = append(cloudflareChanges, deletes...)
+		cloudflareChanges = append(cloudflareChanges, creates...)
+		cloudflareChanges = append(cloudflareChanges, updates...)
 	}
 
 	for _, endpoint := range changes.Delete {
-		for _, target := range endpoint.Targets {
-			cloudflareChanges = append(cloudflareChanges, p.newCloudFlareChange(cloudFlareDelete, endpoint, target))
+		deletes, err := p.newCloudFlareChanges(cloudFlareDelete, endpoint, endpoint.Targets)
+		if err != nil {
+			changeErrors = append(changeErrors, &provider.RecordError{Endpoint: endpoint, Err: err})
+			continue
 		}
+		cloudflareChanges = append(cloudflareChanges, deletes...)
 	}
 
-	return p.submitChanges(ctx, cloudflareChanges)
+	if err := p.submitChanges(ctx, cloudflareChanges); err != nil {
+		return err
+	}
+	if len(changeErrors) > 0 {
+		return changeErrors
+	}
+	return nil
 }
 
 func (p *CloudFlareProvider) PropertyValuesEqual(name string, previous string, current string) bool {
@@ -373,14 +395,27 @@ func (p *CloudFlareProvider) changesByZone(zones []cloudflare.Zone, changeSet []
 
 func (p *CloudFlareProvider) getRecordID(records []cloudflare.DNSRecord, record cloudflare.DNSRecord) string {
 	for _, zoneRecord := range records {
-		if zoneRecord.Name == record.Name && zoneRecord.Type == record.Type && zoneRecord.Content == record.Content {
+		if zoneRecord.Name == record.Name && zoneRecord.Type == record.Type && cloudFlareTarget(zoneRecord) == cloudFlareTarget(record) {
 			return zoneRecord.ID
 		}
 	}
 	return ""
 }
 
-func (p *CloudFlareProvider) newCloudFlareChange(action string, endpoint *endpoint.Endpoint, target string) *cloudFlareChange {
+// newCloudFlareChanges returns the changes of the given targets of the endpoint, one per target
+func (p *CloudFlareProvider) newCloudFlareChanges(action string, endpoint *endpoint.Endpoint, targets []string) ([]*cloudFlareChange, error) {
+	changes := make([]*cloudFlareChange, 0, len(targets))
+	for _, target := range targets {
+		change, err := p.newCloudFlareChange(action, endpoint, target)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func (p *CloudFlareProvider) newCloudFlareChange(action string, endpoint *endpoint.Endpoint, target string) (*cloudFlareChange, error) {
 	ttl := defaultCloudFlareRecordTTL
 	proxied := shouldBeProxied(endpoint, p.proxiedByDefault)
 
@@ -388,7 +423,7 @@ func (p *CloudFlareProvider) newCloudFlareChange(action string, endpoint *endpoi
 		ttl = int(endpoint.RecordTTL)
 	}
 
-	return &cloudFlareChange{
+	change := &cloudFlareChange{
 		Action: action,
 		ResourceRecord: cloudflare.DNSRecord{
 			Name:    endpoint.DNSName,
@@ -398,6 +433,77 @@ func (p *CloudFlareProvider) newCloudFlareChange(action string, endpoint *endpoi
 			Content: target,
 		},
 	}
+	if err := setCloudFlareRecordData(&change.ResourceRecord, target); err != nil {
+		return nil, fmt.Errorf("invalid target %q: %v", target, err)
+	}
+	return change, nil
+}
+
+// setCloudFlareRecordData sets the priority and data of MX, SRV and CAA records, which CloudFlare
+// expects in separate fields instead of the content
+func setCloudFlareRecordData(record *cloudflare.DNSRecord, target string) error {
+	switch record.Type {
+	case endpoint.RecordTypeMX:
+		mx, err := endpoint.ParseMXTarget(target)
+		if err != nil {
+			return err
+		}
+		record.Content = mx.Exchange
+		record.Priority = &mx.Preference
+	case endpoint.RecordTypeSRV:
+		srv, err := endpoint.ParseSRVTarget(target)
+		if err != nil {
+			return err
+		}
+		// the name of SRV records is "_service._proto.name"
+		labels := strings.SplitN(record.Name, ".", 3)
+		if len(labels) != 3 {
+			return fmt.Errorf("the name of a SRV record must be \"_service._proto.name\"")
+		}
+		record.Content = fmt.Sprintf("%d %d %s", srv.Weight, srv.Port, srv.Target)
+		record.Priority = &srv.Priority
+		record.Data = map[string]interface{}{
+			"service":  labels[0],
+			"proto":    labels[1],
+			"name":     labels[2],
+			"priority": srv.Priority,
+			"weight":   srv.Weight,
+			"port":     srv.Port,
+			"target":   srv.Target,
+		}
+	case endpoint.RecordTypeCAA:
+		caa, err := endpoint.ParseCAATarget(target)
+		if err != nil {
+			return err
+		}
+		record.Content = caa.String()
+		record.Data = map[string]interface{}{
+			"flags": caa.Flags,
+			"tag":   caa.Tag,
+			"value": caa.Value,
+		}
+	}
+	return nil
+}
+
+// cloudFlareTarget returns the target of a CloudFlare record, which includes the priority of MX and SRV records
+func cloudFlareTarget(record cloudflare.DNSRecord) string {
+	switch record.Type {
+	case endpoint.RecordTypeMX:
+		if record.Priority != nil {
+			return (&endpoint.MXTarget{Preference: *record.Priority, Exchange: record.Content}).String()
+		}
+	case endpoint.RecordTypeSRV:
+		// the content of SRV records is "<weight> <port> <target>"
+		if record.Priority != nil && len(strings.Fields(record.Content)) == 3 {
+			return fmt.Sprintf("%d %s", *record.Priority, record.Content)
+		}
+	case endpoint.RecordTypeCAA:
+		if caa, err := endpoint.ParseCAATarget(record.Content); err == nil {
+			return caa.String()
+		}
+	}
+	return record.Content
 }
 
 func shouldBeProxied(endpoint *endpoint.Endpoint, proxiedByDefault bool) bool {
@@ -421,6 +527,17 @@ func shouldBeProxied(endpoint *endpoint.Endpoint, proxiedByDefault bool) bool {
 	return proxied
 }
 
+// supportedRecordType returns true for the record types of provider.SupportedRecordType and
+// the AAAA, MX and CAA records Cloudflare supports in addition
+func supportedRecordType(recordType string) bool {
+	switch recordType {
+	case endpoint.RecordTypeAAAA, endpoint.RecordTypeMX, endpoint.RecordTypeCAA:
+		return true
+	default:
+		return provider.SupportedRecordType(recordType)
+	}
+}
+
 func groupByNameAndType(records []cloudflare.DNSRecord) []*endpoint.Endpoint {
 	endpoints := []*endpoint.Endpoint{}
 
@@ -428,7 +545,7 @@ func groupByNameAndType(records []cloudflare.DNSRecord) []*endpoint.Endpoint {
 	groups := map[string][]cloudflare.DNSRecord{}
 
 	for _, r := range records {
-		if !provider.SupportedRecordType(r.Type) {
+		if !supportedRecordType(r.Type) {
 			continue
 		}
 
@@ -444,7 +561,7 @@ func groupByNameAndType(records []cloudflare.DNSRecord) []*endpoint.Endpoint {
 	for _, records := range groups {
 		targets := make([]string, len(records))
 		for i, record := range records {
-			targets[i] = record.Content
+			targets[i] = cloudFlareTarget(record)
 		}
 		endpoints = append(endpoints,
 			endpoint.NewEndpointWithTTL(
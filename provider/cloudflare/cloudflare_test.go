@@ -24,6 +24,7 @@ import (
 
 	cloudflare "github.com/cloudflare/cloudflare-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/maxatome/go-testdeep/td"
 	"sigs.k8s.io/external-dns/endpoint"
@@ -347,6 +348,159 @@ func TestCloudflareCname(t *testing.T) {
 	)
 }
 
+func TestCloudflareMX(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		{
+			RecordType: "MX",
+			DNSName:    "bar.com",
+			Targets:    endpoint.Targets{"10 mail.bar.com"},
+		},
+	}
+
+	AssertActions(t, &CloudFlareProvider{}, endpoints, []MockAction{
+		{
+			Name:   "Create",
+			ZoneId: "001",
+			RecordData: cloudflare.DNSRecord{
+				Type:     "MX",
+				Name:     "bar.com",
+				Content:  "mail.bar.com",
+				Priority: uint16Ptr(10),
+				TTL:      1,
+				Proxied:  proxyDisabled,
+			},
+		},
+	},
+		[]string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	)
+}
+
+func TestCloudflareSRV(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		{
+			RecordType: "SRV",
+			DNSName:    "_sip._tcp.bar.com",
+			Targets:    endpoint.Targets{"10 5 5060 sip.bar.com"},
+		},
+	}
+
+	AssertActions(t, &CloudFlareProvider{}, endpoints, []MockAction{
+		{
+			Name:   "Create",
+			ZoneId: "001",
+			RecordData: cloudflare.DNSRecord{
+				Type:     "SRV",
+				Name:     "_sip._tcp.bar.com",
+				Content:  "5 5060 sip.bar.com",
+				Priority: uint16Ptr(10),
+				Data: map[string]interface{}{
+					"service":  "_sip",
+					"proto":    "_tcp",
+					"name":     "bar.com",
+					"priority": uint16(10),
+					"weight":   uint16(5),
+					"port":     uint16(5060),
+					"target":   "sip.bar.com",
+				},
+				TTL:     1,
+				Proxied: proxyDisabled,
+			},
+		},
+	},
+		[]string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	)
+}
+
+func TestCloudflareCAA(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		{
+			RecordType: "CAA",
+			DNSName:    "bar.com",
+			Targets:    endpoint.Targets{`0 issue "letsencrypt.org"`},
+		},
+	}
+
+	AssertActions(t, &CloudFlareProvider{}, endpoints, []MockAction{
+		{
+			Name:   "Create",
+			ZoneId: "001",
+			RecordData: cloudflare.DNSRecord{
+				Type:    "CAA",
+				Name:    "bar.com",
+				Content: `0 issue "letsencrypt.org"`,
+				Data: map[string]interface{}{
+					"flags": uint8(0),
+					"tag":   "issue",
+					"value": "letsencrypt.org",
+				},
+				TTL:     1,
+				Proxied: proxyDisabled,
+			},
+		},
+	},
+		[]string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	)
+}
+
+func TestCloudflareRecordDataTarget(t *testing.T) {
+	for _, tc := range []struct {
+		record   cloudflare.DNSRecord
+		expected string
+	}{
+		{cloudflare.DNSRecord{Type: "A", Content: "1.2.3.4"}, "1.2.3.4"},
+		{cloudflare.DNSRecord{Type: "MX", Content: "mail.bar.com", Priority: uint16Ptr(10)}, "10 mail.bar.com"},
+		{cloudflare.DNSRecord{Type: "SRV", Content: "5 5060 sip.bar.com", Priority: uint16Ptr(10)}, "10 5 5060 sip.bar.com"},
+		{cloudflare.DNSRecord{Type: "CAA", Content: "0 issue letsencrypt.org"}, `0 issue "letsencrypt.org"`},
+	} {
+		assert.Equal(t, tc.expected, cloudFlareTarget(tc.record))
+	}
+
+	p := &CloudFlareProvider{}
+	records := []cloudflare.DNSRecord{
+		{Name: "bar.com", Type: "MX", Content: "mail.bar.com", Priority: uint16Ptr(10), ID: "1"},
+		{Name: "bar.com", Type: "MX", Content: "mail.bar.com", Priority: uint16Ptr(20), ID: "2"},
+	}
+	change, err := p.newCloudFlareChange(cloudFlareDelete, endpoint.NewEndpoint("bar.com", "MX", "20 mail.bar.com"), "20 mail.bar.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", p.getRecordID(records, change.ResourceRecord))
+}
+
+func TestCloudflareApplyChangesInvalidTarget(t *testing.T) {
+	client := NewMockCloudFlareClient()
+	p := &CloudFlareProvider{
+		Client: client,
+	}
+	invalid := endpoint.NewEndpoint("mail.bar.com", endpoint.RecordTypeMX, "mail.bar.com")
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			invalid,
+			endpoint.NewEndpoint("bar.com", endpoint.RecordTypeA, "127.0.0.1"),
+		},
+	})
+
+	var changeErrors provider.ChangeErrors
+	require.ErrorAs(t, err, &changeErrors)
+	require.Len(t, changeErrors, 1)
+	assert.True(t, changeErrors[0].Matches(invalid))
+	td.Cmp(t, client.Actions, []MockAction{
+		{
+			Name:   "Create",
+			ZoneId: "001",
+			RecordData: cloudflare.DNSRecord{
+				Type:    endpoint.RecordTypeA,
+				Name:    "bar.com",
+				Content: "127.0.0.1",
+				TTL:     1,
+				Proxied: proxyDisabled,
+			},
+		},
+	})
+}
+
+func uint16Ptr(v uint16) *uint16 {
+	return &v
+}
+
 func TestCloudflareCustomTTL(t *testing.T) {
 	endpoints := []*endpoint.Endpoint{
 		{
@@ -990,6 +1144,33 @@ func TestCloudflareGroupByNameAndType(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "AAAA record",
+			Records: []cloudflare.DNSRecord{
+				{
+					Name:    "foo.com",
+					Type:    endpoint.RecordTypeAAAA,
+					Content: "2001:db8::1",
+					TTL:     defaultCloudFlareRecordTTL,
+					Proxied: proxyDisabled,
+				},
+			},
+			ExpectedEndpoints: []*endpoint.Endpoint{
+				{
+					DNSName:    "foo.com",
+					Targets:    endpoint.Targets{"2001:db8::1"},
+					RecordType: endpoint.RecordTypeAAAA,
+					RecordTTL:  endpoint.TTL(defaultCloudFlareRecordTTL),
+					Labels:     endpoint.Labels{},
+					ProviderSpecific: endpoint.ProviderSpecific{
+						{
+							Name:  "external-dns.alpha.kubernetes.io/cloudflare-proxied",
+							Value: "false",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// RecordError is the error of a single record change a provider failed to apply
+type RecordError struct {
+	// Endpoint is the record which failed, providers may only fill in its name, type and set identifier
+	Endpoint *endpoint.Endpoint
+	Err      error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("%s record %s: %v", e.Endpoint.RecordType, e.Endpoint.DNSName, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Matches tells whether the error refers to the given record
+func (e *RecordError) Matches(ep *endpoint.Endpoint) bool {
+	return strings.EqualFold(strings.TrimSuffix(e.Endpoint.DNSName, "."), strings.TrimSuffix(ep.DNSName, ".")) &&
+		e.Endpoint.RecordType == ep.RecordType && e.Endpoint.SetIdentifier == ep.SetIdentifier
+}
+
+// ChangeErrors is returned by ApplyChanges when some of the record changes failed while all others were applied
+type ChangeErrors []*RecordError
+
+func (e ChangeErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("failed to apply %d record change(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// RecordErrorFor returns the error of the given record if err is a ChangeErrors.
+// For any other error all records are considered to have failed, hence err is returned as is.
+func RecordErrorFor(err error, ep *endpoint.Endpoint) error {
+	var changeErrors ChangeErrors
+	if !errors.As(err, &changeErrors) {
+		return err
+	}
+	for _, recordErr := range changeErrors {
+		if recordErr.Matches(ep) {
+			return recordErr.Err
+		}
+	}
+	return nil
+}
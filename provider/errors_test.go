@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestRecordErrorFor(t *testing.T) {
+	throttled := errors.New("throttled")
+	changeErrors := ChangeErrors{
+		{Endpoint: &endpoint.Endpoint{DNSName: "failed.example.org.", RecordType: endpoint.RecordTypeA, SetIdentifier: "eu"}, Err: throttled},
+	}
+
+	for _, tc := range []struct {
+		title    string
+		err      error
+		ep       *endpoint.Endpoint
+		expected error
+	}{
+		{
+			title: "no error",
+			ep:    &endpoint.Endpoint{DNSName: "failed.example.org", RecordType: endpoint.RecordTypeA, SetIdentifier: "eu"},
+		},
+		{
+			title:    "plain error applies to all records",
+			err:      throttled,
+			ep:       &endpoint.Endpoint{DNSName: "other.example.org", RecordType: endpoint.RecordTypeA},
+			expected: throttled,
+		},
+		{
+			title:    "failed record",
+			err:      changeErrors,
+			ep:       &endpoint.Endpoint{DNSName: "Failed.example.org", RecordType: endpoint.RecordTypeA, SetIdentifier: "eu"},
+			expected: throttled,
+		},
+		{
+			title:    "wrapped change errors",
+			err:      fmt.Errorf("apply: %w", changeErrors),
+			ep:       &endpoint.Endpoint{DNSName: "failed.example.org", RecordType: endpoint.RecordTypeA, SetIdentifier: "eu"},
+			expected: throttled,
+		},
+		{
+			title: "other set identifier",
+			err:   changeErrors,
+			ep:    &endpoint.Endpoint{DNSName: "failed.example.org", RecordType: endpoint.RecordTypeA, SetIdentifier: "us"},
+		},
+		{
+			title: "other record type",
+			err:   changeErrors,
+			ep:    &endpoint.Endpoint{DNSName: "failed.example.org", RecordType: endpoint.RecordTypeTXT, SetIdentifier: "eu"},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.expected, RecordErrorFor(tc.err, tc.ep))
+		})
+	}
+}
+
+func TestChangeErrorsError(t *testing.T) {
+	err := ChangeErrors{
+		{Endpoint: &endpoint.Endpoint{DNSName: "a.example.org", RecordType: endpoint.RecordTypeA}, Err: errors.New("throttled")},
+		{Endpoint: &endpoint.Endpoint{DNSName: "b.example.org", RecordType: endpoint.RecordTypeCNAME}, Err: errors.New("invalid")},
+	}
+	assert.EqualError(t, err, "failed to apply 2 record change(s): A record a.example.org: throttled; CNAME record b.example.org: invalid")
+}
@@ -204,6 +204,17 @@ func (p *GoogleProvider) Zones(ctx context.Context) (map[string]*dns.ManagedZone
 	return zones, nil
 }
 
+// supportedRecordType returns true for the record types of provider.SupportedRecordType and
+// the AAAA, MX, CAA and PTR records Google Cloud DNS supports in addition
+func supportedRecordType(recordType string) bool {
+	switch recordType {
+	case endpoint.RecordTypeAAAA, endpoint.RecordTypeMX, endpoint.RecordTypeCAA, endpoint.RecordTypePTR:
+		return true
+	default:
+		return provider.SupportedRecordType(recordType)
+	}
+}
+
 // Records returns the list of records in all relevant zones.
 func (p *GoogleProvider) Records(ctx context.Context) (endpoints []*endpoint.Endpoint, _ error) {
 	zones, err := p.Zones(ctx)
@@ -213,7 +224,7 @@ func (p *GoogleProvider) Records(ctx context.Context) (endpoints []*endpoint.End
 
 	f := func(resp *dns.ResourceRecordSetsListResponse) error {
 		for _, r := range resp.Rrsets {
-			if !provider.SupportedRecordType(r.Type) {
+			if !supportedRecordType(r.Type) {
 				continue
 			}
 			endpoints = append(endpoints, endpoint.NewEndpointWithTTL(r.Name, r.Type, endpoint.TTL(r.Ttl), r.Rrdatas...))
@@ -443,8 +454,14 @@ func newRecord(ep *endpoint.Endpoint) *dns.ResourceRecordSet {
 	// way we can use it has is here and trim it off if it exists when necessary.
 	targets := make([]string, len(ep.Targets))
 	copy(targets, []string(ep.Targets))
-	if ep.RecordType == endpoint.RecordTypeCNAME {
+	switch ep.RecordType {
+	case endpoint.RecordTypeCNAME:
 		targets[0] = provider.EnsureTrailingDot(targets[0])
+	case endpoint.RecordTypeMX, endpoint.RecordTypeSRV, endpoint.RecordTypePTR:
+		// the hostname is the last field of MX, SRV and PTR targets, it must be fully qualified
+		for i, target := range targets {
+			targets[i] = provider.EnsureTrailingDot(target)
+		}
 	}
 
 	// no annotation results in a Ttl of 0, default to 300 for backwards-compatibility
@@ -170,18 +170,19 @@ func isValidRecordSet(recordSet *dns.ResourceRecordSet) bool {
 	}
 
 	switch recordSet.Type {
-	case endpoint.RecordTypeCNAME:
+	case endpoint.RecordTypeCNAME, endpoint.RecordTypeMX, endpoint.RecordTypeSRV, endpoint.RecordTypePTR:
 		for _, rrd := range recordSet.Rrdatas {
 			if !hasTrailingDot(rrd) {
 				return false
 			}
 		}
-	case endpoint.RecordTypeA, endpoint.RecordTypeTXT:
+	case endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeTXT:
 		for _, rrd := range recordSet.Rrdatas {
 			if hasTrailingDot(rrd) {
 				return false
 			}
 		}
+	case endpoint.RecordTypeCAA:
 	default:
 		panic("unhandled record type")
 	}
@@ -255,6 +256,11 @@ func TestGoogleRecords(t *testing.T) {
 		endpoint.NewEndpointWithTTL("list-test.zone-1.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypeA, endpoint.TTL(1), "1.2.3.4"),
 		endpoint.NewEndpointWithTTL("list-test.zone-2.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypeA, endpoint.TTL(2), "8.8.8.8"),
 		endpoint.NewEndpointWithTTL("list-test-alias.zone-1.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypeCNAME, endpoint.TTL(3), "foo.elb.amazonaws.com"),
+		endpoint.NewEndpointWithTTL("list-test-ipv6.zone-1.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypeAAAA, endpoint.TTL(8), "2001:db8::1"),
+		endpoint.NewEndpointWithTTL("list-test-mx.zone-1.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypeMX, endpoint.TTL(4), "10 mail.example.com"),
+		endpoint.NewEndpointWithTTL("_sip._tcp.list-test-srv.zone-1.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypeSRV, endpoint.TTL(5), "10 5 5060 sip.example.com"),
+		endpoint.NewEndpointWithTTL("list-test-caa.zone-1.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypeCAA, endpoint.TTL(6), `0 issue "letsencrypt.org"`),
+		endpoint.NewEndpointWithTTL("4.3.2.1.in-addr.arpa.zone-1.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypePTR, endpoint.TTL(7), "list-test.zone-1.ext-dns-test-2.gcp.zalan.do"),
 	}
 
 	provider := newGoogleProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.gcp.zalan.do."}), provider.NewZoneIDFilter([]string{""}), false, originalEndpoints)
@@ -514,6 +520,8 @@ func TestNewFilteredRecords(t *testing.T) {
 		endpoint.NewEndpointWithTTL("update-test.zone-1.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypeA, 0, "8.8.8.8"),
 		endpoint.NewEndpoint("delete-test.zone-1.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypeA, "8.8.8.8"),
 		endpoint.NewEndpoint("delete-test-cname.zone-1.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypeCNAME, "qux.elb.amazonaws.com"),
+		endpoint.NewEndpoint("mx-test.zone-1.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypeMX, "10 mail.example.com", "20 backup.example.com"),
+		endpoint.NewEndpoint("_sip._tcp.srv-test.zone-1.ext-dns-test-2.gcp.zalan.do", endpoint.RecordTypeSRV, "10 5 5060 sip.example.com"),
 	})
 
 	validateChangeRecords(t, records, []*dns.ResourceRecordSet{
@@ -523,6 +531,8 @@ func TestNewFilteredRecords(t *testing.T) {
 		{Name: "update-test.zone-1.ext-dns-test-2.gcp.zalan.do.", Rrdatas: []string{"8.8.8.8"}, Type: "A", Ttl: 300},
 		{Name: "delete-test.zone-1.ext-dns-test-2.gcp.zalan.do.", Rrdatas: []string{"8.8.8.8"}, Type: "A", Ttl: 300},
 		{Name: "delete-test-cname.zone-1.ext-dns-test-2.gcp.zalan.do.", Rrdatas: []string{"qux.elb.amazonaws.com."}, Type: "CNAME", Ttl: 300},
+		{Name: "mx-test.zone-1.ext-dns-test-2.gcp.zalan.do.", Rrdatas: []string{"10 mail.example.com.", "20 backup.example.com."}, Type: "MX", Ttl: 300},
+		{Name: "_sip._tcp.srv-test.zone-1.ext-dns-test-2.gcp.zalan.do.", Rrdatas: []string{"10 5 5060 sip.example.com."}, Type: "SRV", Ttl: 300},
 	})
 }
 
@@ -820,7 +830,7 @@ func clearGoogleRecords(t *testing.T, provider *GoogleProvider, zone string) {
 	require.NoError(t, provider.resourceRecordSetsClient.List(provider.project, zone).Pages(context.Background(), func(resp *dns.ResourceRecordSetsListResponse) error {
 		for _, r := range resp.Rrsets {
 			switch r.Type {
-			case endpoint.RecordTypeA, endpoint.RecordTypeCNAME:
+			case endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME, endpoint.RecordTypeMX, endpoint.RecordTypeSRV, endpoint.RecordTypeCAA, endpoint.RecordTypePTR:
 				recordSets = append(recordSets, r)
 			}
 		}
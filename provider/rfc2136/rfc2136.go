@@ -169,11 +169,26 @@ OuterLoop:
 			rrValues = []string{rr.(*dns.AAAA).AAAA.String()}
 			rrType = "AAAA"
 		case dns.TypeTXT:
-			rrValues = (rr.(*dns.TXT).Txt)
+			rrValues = []string{txtTarget(rr.(*dns.TXT).Txt)}
 			rrType = "TXT"
 		case dns.TypeNS:
 			rrValues = []string{rr.(*dns.NS).Ns}
 			rrType = "NS"
+		case dns.TypeMX:
+			mx := rr.(*dns.MX)
+			rrValues = []string{(&endpoint.MXTarget{Preference: mx.Preference, Exchange: trimTrailingDot(mx.Mx)}).String()}
+			rrType = "MX"
+		case dns.TypeSRV:
+			srv := rr.(*dns.SRV)
+			rrValues = []string{(&endpoint.SRVTarget{Priority: srv.Priority, Weight: srv.Weight, Port: srv.Port, Target: trimTrailingDot(srv.Target)}).String()}
+			rrType = "SRV"
+		case dns.TypeCAA:
+			caa := rr.(*dns.CAA)
+			rrValues = []string{(&endpoint.CAATarget{Flags: caa.Flag, Tag: caa.Tag, Value: caa.Value}).String()}
+			rrType = "CAA"
+		case dns.TypePTR:
+			rrValues = []string{rr.(*dns.PTR).Ptr}
+			rrType = "PTR"
 		default:
 			continue // Unhandled record type
 		}
@@ -351,6 +366,10 @@ func (r rfc2136Provider) AddRecord(m *dns.Msg, ep *endpoint.Endpoint) error {
 	}
 
 	for _, target := range ep.Targets {
+		target, err := rrData(ep.RecordType, target)
+		if err != nil {
+			return err
+		}
 		newRR := fmt.Sprintf("%s %d %s %s", ep.DNSName, ttl, ep.RecordType, target)
 		log.Infof("Adding RR: %s", newRR)
 
@@ -368,6 +387,10 @@ func (r rfc2136Provider) AddRecord(m *dns.Msg, ep *endpoint.Endpoint) error {
 func (r rfc2136Provider) RemoveRecord(m *dns.Msg, ep *endpoint.Endpoint) error {
 	log.Debugf("RemoveRecord.ep=%s", ep)
 	for _, target := range ep.Targets {
+		target, err := rrData(ep.RecordType, target)
+		if err != nil {
+			return err
+		}
 		newRR := fmt.Sprintf("%s %d %s %s", ep.DNSName, ep.RecordTTL, ep.RecordType, target)
 		log.Infof("Removing RR: %s", newRR)
 
@@ -382,6 +405,34 @@ func (r rfc2136Provider) RemoveRecord(m *dns.Msg, ep *endpoint.Endpoint) error {
 	return nil
 }
 
+// rrData returns the target in the presentation format of the record data. TXT targets are always quoted,
+// so that values containing spaces are not split into several character strings.
+func rrData(recordType, target string) (string, error) {
+	if recordType != endpoint.RecordTypeTXT {
+		return target, nil
+	}
+	txt, err := endpoint.ParseTXTTarget(target)
+	if err != nil {
+		return "", fmt.Errorf("failed to build RR: %v", err)
+	}
+	return txt.String(), nil
+}
+
+// txtTarget returns the target of a TXT record, a list of quoted character strings if there are several of them
+func txtTarget(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return endpoint.TXTTarget(values).String()
+}
+
+func trimTrailingDot(hostname string) string {
+	if hostname == "." {
+		return hostname
+	}
+	return strings.TrimSuffix(hostname, ".")
+}
+
 func (r rfc2136Provider) SendMessage(msg *dns.Msg) error {
 	if r.dryRun {
 		log.Debugf("SendMessage.skipped")
@@ -154,6 +154,64 @@ func TestRfc2136GetRecords(t *testing.T) {
 	assert.True(t, contains(recs, "v2.foo.com"))
 }
 
+func TestRfc2136GetRecordData(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	stub := newStub()
+	err := stub.setOutput([]string{
+		"foo.com 3600 IN MX 10 mail.foo.com.",
+		"foo.com 3600 IN MX 20 backup.foo.com.",
+		"_sip._tcp.foo.com 3600 IN SRV 10 5 5060 sip.foo.com.",
+		"foo.com 3600 IN CAA 0 issue \"letsencrypt.org\"",
+		"1.2.0.192.in-addr.arpa 3600 IN PTR foo.com.",
+		"spf.foo.com 3600 IN TXT \"v=spf1 -all\"",
+		"long.foo.com 3600 IN TXT \"" + long[:255] + "\" \"" + long[255:] + "\"",
+	})
+	assert.NoError(t, err)
+
+	provider, err := createRfc2136StubProvider(stub)
+	assert.NoError(t, err)
+
+	recs, err := provider.Records(context.Background())
+	assert.NoError(t, err)
+
+	targets := map[string][]string{}
+	for _, rec := range recs {
+		targets[rec.RecordType+" "+rec.DNSName] = rec.Targets
+	}
+	assert.Equal(t, map[string][]string{
+		"MX foo.com":                 {"10 mail.foo.com", "20 backup.foo.com"},
+		"SRV _sip._tcp.foo.com":      {"10 5 5060 sip.foo.com"},
+		"CAA foo.com":                {`0 issue "letsencrypt.org"`},
+		"PTR 1.2.0.192.in-addr.arpa": {"foo.com"},
+		"TXT spf.foo.com":            {"v=spf1 -all"},
+		"TXT long.foo.com":           {`"` + long[:255] + `" "` + long[255:] + `"`},
+	}, targets)
+}
+
+func TestRfc2136ApplyChangesRecordData(t *testing.T) {
+	stub := newStub()
+	provider, err := createRfc2136StubProvider(stub)
+	assert.NoError(t, err)
+
+	p := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("foo.com", endpoint.RecordTypeMX, "10 mail.foo.com"),
+			endpoint.NewEndpoint("_sip._tcp.foo.com", endpoint.RecordTypeSRV, "10 5 5060 sip.foo.com"),
+			endpoint.NewEndpoint("foo.com", endpoint.RecordTypeCAA, `0 issue "letsencrypt.org"`),
+			endpoint.NewEndpoint("spf.foo.com", endpoint.RecordTypeTXT, "v=spf1 -all"),
+		},
+	}
+
+	err = provider.ApplyChanges(context.Background(), p)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 4, len(stub.createMsgs))
+	assert.Contains(t, stub.createMsgs[0].String(), "MX\t10 mail.foo.com.")
+	assert.Contains(t, stub.createMsgs[1].String(), "SRV\t10 5 5060 sip.foo.com.")
+	assert.Contains(t, stub.createMsgs[2].String(), "CAA\t0 issue \"letsencrypt.org\"")
+	assert.Contains(t, stub.createMsgs[3].String(), "TXT\t\"v=spf1 -all\"")
+}
+
 func TestRfc2136ApplyChanges(t *testing.T) {
 	stub := newStub()
 	provider, err := createRfc2136StubProvider(stub)
@@ -159,6 +159,20 @@ func TestAWSSDRegistry_Records_ApplyChanges(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestAWSSDRegistry_UpdateLabels(t *testing.T) {
+	r, err := NewAWSSDRegistry(newInMemoryProvider(nil, nil), "owner")
+	require.NoError(t, err)
+
+	ep := endpoint.NewEndpoint("foo.test-zone.example.org", endpoint.RecordTypeA, "1.2.3.4")
+	ep.Labels[endpoint.ResourceLabelKey] = "service/default/foo"
+	ep.Labels[endpoint.ResourceCreationTimestampLabelKey] = "2022-01-01T00:00:00Z"
+	ep.Labels[endpoint.ResourcePriorityLabelKey] = "10"
+	r.updateLabels([]*endpoint.Endpoint{ep})
+
+	// the labels for the resolution of conflicts are not stored in the description
+	assert.Equal(t, "heritage=external-dns,external-dns/owner=owner,external-dns/resource=service/default/foo", ep.Labels[endpoint.AWSSDDescriptionLabel])
+}
+
 func newEndpointWithOwnerAndDescription(dnsName, target, recordType, ownerID string, description string) *endpoint.Endpoint {
 	e := endpoint.NewEndpoint(dnsName, recordType, target)
 	e.Labels[endpoint.OwnerLabelKey] = ownerID
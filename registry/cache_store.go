@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// recordsCacheKey is the key of the snapshot in the binary data of the ConfigMap
+const recordsCacheKey = "records.json.gz"
+
+// RecordsSnapshot is the state of the records cache persisted by a RecordsCacheStore
+type RecordsSnapshot struct {
+	// Fingerprint identifies the configuration the records were read with, snapshots of another configuration are discarded
+	Fingerprint string `json:"fingerprint"`
+	// RefreshTime is the time the records were read from the provider, the snapshot expires along with the cache
+	RefreshTime time.Time            `json:"refreshTime"`
+	Records     []*endpoint.Endpoint `json:"records"`
+}
+
+// RecordsCacheStore persists the records cache of the TXT registry, so that restarted instances and new leaders
+// start with a warm cache instead of listing all records of the provider
+type RecordsCacheStore interface {
+	// Load returns the persisted snapshot, nil if there is none
+	Load(ctx context.Context) (*RecordsSnapshot, error)
+	// Save persists the snapshot, replacing the previous one
+	Save(ctx context.Context, snapshot *RecordsSnapshot) error
+	// Invalidate removes the persisted snapshot
+	Invalidate(ctx context.Context) error
+}
+
+// fileRecordsCacheStore persists the records cache in a local file, e.g. on a persistent volume
+type fileRecordsCacheStore struct {
+	path string
+}
+
+// NewFileRecordsCacheStore returns a RecordsCacheStore persisting the records cache in the given file
+func NewFileRecordsCacheStore(path string) RecordsCacheStore {
+	return &fileRecordsCacheStore{path: path}
+}
+
+func (s *fileRecordsCacheStore) Load(_ context.Context) (*RecordsSnapshot, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeRecordsSnapshot(data)
+}
+
+func (s *fileRecordsCacheStore) Save(_ context.Context, snapshot *RecordsSnapshot) error {
+	data, err := encodeRecordsSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	// the snapshot is written to a temporary file first so that it is never read partially written
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
+
+func (s *fileRecordsCacheStore) Invalidate(_ context.Context) error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// configMapRecordsCacheStore persists the records cache in a ConfigMap, which is shared by all replicas
+// so that a new leader starts with the cache of the previous one
+type configMapRecordsCacheStore struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+}
+
+// NewConfigMapRecordsCacheStore returns a RecordsCacheStore persisting the records cache in the given ConfigMap.
+// The snapshot is compressed, it must not exceed the size limit of a ConfigMap of 1 MiB.
+func NewConfigMapRecordsCacheStore(client kubernetes.Interface, namespace, name string) RecordsCacheStore {
+	return &configMapRecordsCacheStore{client: client, namespace: namespace, name: name}
+}
+
+func (s *configMapRecordsCacheStore) Load(ctx context.Context) (*RecordsSnapshot, error) {
+	cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s/%s: %w", s.namespace, s.name, err)
+	}
+	data, ok := cm.BinaryData[recordsCacheKey]
+	if !ok {
+		return nil, nil
+	}
+	return decodeRecordsSnapshot(data)
+}
+
+func (s *configMapRecordsCacheStore) Save(ctx context.Context, snapshot *RecordsSnapshot) error {
+	data, err := encodeRecordsSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: s.namespace,
+					Name:      s.name,
+					Labels:    map[string]string{managedByLabelKey: managedByLabelValue},
+				},
+				BinaryData: map[string][]byte{recordsCacheKey: data},
+			}
+			_, err = s.client.CoreV1().ConfigMaps(s.namespace).Create(ctx, cm, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				return apierrors.NewConflict(corev1.Resource("configmaps"), s.name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if cm.BinaryData == nil {
+			cm.BinaryData = map[string][]byte{}
+		}
+		cm.BinaryData[recordsCacheKey] = data
+		_, err = s.client.CoreV1().ConfigMaps(s.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func (s *configMapRecordsCacheStore) Invalidate(ctx context.Context) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, ok := cm.BinaryData[recordsCacheKey]; !ok {
+			return nil
+		}
+		delete(cm.BinaryData, recordsCacheKey)
+		_, err = s.client.CoreV1().ConfigMaps(s.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// encodeRecordsSnapshot returns the snapshot as gzip compressed JSON
+func encodeRecordsSnapshot(snapshot *RecordsSnapshot) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(snapshot); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeRecordsSnapshot(data []byte) (*RecordsSnapshot, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid records cache: %w", err)
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid records cache: %w", err)
+	}
+	snapshot := &RecordsSnapshot{}
+	if err := json.Unmarshal(raw, snapshot); err != nil {
+		return nil, fmt.Errorf("invalid records cache: %w", err)
+	}
+	for _, ep := range snapshot.Records {
+		if ep.Labels == nil {
+			ep.Labels = endpoint.NewLabels()
+		}
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func testRecordsCacheStore(t *testing.T, store RecordsCacheStore) {
+	ctx := context.Background()
+
+	snapshot, err := store.Load(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, snapshot, "nothing is stored yet")
+
+	require.NoError(t, store.Invalidate(ctx), "invalidating an empty store succeeds")
+
+	refreshTime := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	records := []*endpoint.Endpoint{
+		newEndpointWithOwnerResource("foo.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "owner", "ingress/default/foo"),
+		endpoint.NewEndpoint("bar.test-zone.example.org", endpoint.RecordTypeCNAME, "foo.test-zone.example.org"),
+	}
+	require.NoError(t, store.Save(ctx, &RecordsSnapshot{Fingerprint: "first", RefreshTime: refreshTime, Records: records}))
+	require.NoError(t, store.Save(ctx, &RecordsSnapshot{Fingerprint: "second", RefreshTime: refreshTime, Records: records}))
+
+	snapshot, err = store.Load(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, snapshot)
+	assert.Equal(t, "second", snapshot.Fingerprint, "the latest snapshot replaces the previous one")
+	assert.True(t, refreshTime.Equal(snapshot.RefreshTime))
+	assert.Equal(t, records, snapshot.Records)
+
+	require.NoError(t, store.Invalidate(ctx))
+	snapshot, err = store.Load(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+}
+
+func TestFileRecordsCacheStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records-cache")
+	testRecordsCacheStore(t, NewFileRecordsCacheStore(path))
+
+	require.NoError(t, os.WriteFile(path, []byte("invalid"), 0o600))
+	_, err := NewFileRecordsCacheStore(path).Load(context.Background())
+	assert.Error(t, err)
+}
+
+func TestConfigMapRecordsCacheStore(t *testing.T) {
+	testRecordsCacheStore(t, NewConfigMapRecordsCacheStore(fake.NewSimpleClientset(), "default", "external-dns-cache"))
+}
@@ -0,0 +1,503 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// configMapRegistryLabelKey is the label of the ConfigMaps of a registry, its value is the name of the registry
+	configMapRegistryLabelKey = "externaldns.k8s.io/registry"
+	managedByLabelKey         = "app.kubernetes.io/managed-by"
+	managedByLabelValue       = "external-dns"
+)
+
+// ConfigMapRegistry implements registry interface with ownership stored in ConfigMaps within the cluster,
+// hence the DNS zones contain the records only. The ownership is sharded over several ConfigMaps
+// to stay within the size limit of a single one.
+type ConfigMapRegistry struct {
+	provider  provider.Provider
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	shards    int
+	ownerID   string // refers to the owner id of the current instance
+	dryRun    bool
+
+	// mapper and wildcardReplacement locate the TXT ownership records which are imported
+	mapper              nameMapper
+	wildcardReplacement string
+
+	// locations maps the keys of the entries to the ConfigMap they were read from
+	locations map[string]string
+
+	// maintenance stores the changes found during the last run of Records: the imports of the TXT ownership as updates
+	// of the records from no owner to the owner of their TXT records, the deletions of the imported TXT records and the
+	// deletions of the entries of this instance whose records are missing
+	maintenance *plan.Changes
+}
+
+// ownershipEntry is the ownership of a single record
+type ownershipEntry struct {
+	DNSName       string          `json:"dnsName"`
+	RecordType    string          `json:"recordType"`
+	SetIdentifier string          `json:"setIdentifier,omitempty"`
+	Labels        endpoint.Labels `json:"labels"`
+}
+
+// NewConfigMapRegistry returns new ConfigMapRegistry object. The ConfigMaps are named after the registry
+// and suffixed with their shard. The TXT prefix, suffix and wildcard replacement locate the ownership
+// records of the TXT registry which are imported.
+func NewConfigMapRegistry(provider provider.Provider, client kubernetes.Interface, namespace, name string, shards int, ownerID, txtPrefix, txtSuffix, txtWildcardReplacement string, dryRun bool) (*ConfigMapRegistry, error) {
+	if ownerID == "" {
+		return nil, errors.New("owner id cannot be empty")
+	}
+	if name == "" {
+		return nil, errors.New("configmap registry name cannot be empty")
+	}
+	if shards < 1 {
+		return nil, errors.New("configmap registry needs at least one shard")
+	}
+	if len(txtPrefix) > 0 && len(txtSuffix) > 0 {
+		return nil, errors.New("txt-prefix and txt-suffix are mutual exclusive")
+	}
+
+	return &ConfigMapRegistry{
+		provider:            provider,
+		client:              client,
+		namespace:           namespace,
+		name:                name,
+		shards:              shards,
+		ownerID:             ownerID,
+		dryRun:              dryRun,
+		mapper:              newaffixNameMapper(txtPrefix, txtSuffix, txtWildcardReplacement),
+		wildcardReplacement: txtWildcardReplacement,
+		locations:           map[string]string{},
+	}, nil
+}
+
+func (im *ConfigMapRegistry) GetDomainFilter() endpoint.DomainFilterInterface {
+	return im.provider.GetDomainFilter()
+}
+
+// Records returns the current records from the dns provider with their ownership labels.
+// Ownership TXT records of the TXT registry are hidden, their labels apply to all records without
+// ownership in the ConfigMaps yet. The imports into the ConfigMaps, the deletions of the TXT records
+// of this instance once imported and the releases of entries whose records are missing are returned
+// by MaintenanceChanges.
+func (im *ConfigMapRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	records, err := im.provider.Records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := im.readEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := []*endpoint.Endpoint{}
+	txtLabels := map[string]endpoint.Labels{}
+	ownTXTRecords := []*endpoint.Endpoint{}
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeTXT {
+			// We simply assume that TXT records for the registry will always have only one target.
+			labels, err := endpoint.NewLabelsFromString(record.Targets[0], nil)
+			if err == nil {
+				key := fmt.Sprintf("%s::%s", im.mapper.toEndpointName(record.DNSName), record.SetIdentifier)
+				delete(labels, endpoint.RecordNameLabelKey)
+				txtLabels[key] = labels
+				if labels[endpoint.OwnerLabelKey] == im.ownerID {
+					ownTXTRecords = append(ownTXTRecords, record)
+				}
+				continue
+			}
+			if err == endpoint.ErrUnverifiable {
+				log.Debugf("Skipping import of encrypted TXT record %s", record.DNSName)
+				continue
+			}
+			if err != endpoint.ErrInvalidHeritage {
+				return nil, err
+			}
+		}
+		endpoints = append(endpoints, record)
+	}
+
+	maintenance := &plan.Changes{}
+	// the keys of the stored entries of the records and of the TXT records whose import is still pending
+	found := map[string]struct{}{}
+	pending := map[string]struct{}{}
+	for _, ep := range endpoints {
+		if ep.Labels == nil {
+			ep.Labels = endpoint.NewLabels()
+		}
+		key := ownershipKey(ep.DNSName, ep.RecordType, ep.SetIdentifier)
+		if entry, ok := entries[key]; ok {
+			found[key] = struct{}{}
+			for k, v := range entry.Labels {
+				ep.Labels[k] = v
+			}
+			continue
+		}
+		if labels, ok := txtLabels[im.txtKey(ep)]; ok {
+			pending[im.txtKey(ep)] = struct{}{}
+			imported := ep.DeepCopy()
+			for k, v := range labels {
+				ep.Labels[k] = v
+			}
+			maintenance.UpdateOld = append(maintenance.UpdateOld, imported)
+			maintenance.UpdateNew = append(maintenance.UpdateNew, ep)
+		}
+	}
+
+	// the TXT records of this instance are deleted once the ownership of all their records is stored
+	for _, txt := range ownTXTRecords {
+		if _, ok := pending[fmt.Sprintf("%s::%s", im.mapper.toEndpointName(txt.DNSName), txt.SetIdentifier)]; !ok {
+			maintenance.Delete = append(maintenance.Delete, txt)
+		}
+	}
+
+	// entries of this instance whose records are missing from the zones are released, unless the domain filter
+	// hides the records
+	released := []*endpoint.Endpoint{}
+	for key, entry := range entries {
+		if _, ok := found[key]; ok || entry.Labels[endpoint.OwnerLabelKey] != im.ownerID || !im.GetDomainFilter().Match(entry.DNSName) {
+			continue
+		}
+		released = append(released, &endpoint.Endpoint{
+			DNSName:       entry.DNSName,
+			RecordType:    entry.RecordType,
+			SetIdentifier: entry.SetIdentifier,
+			Labels:        entry.Labels,
+		})
+	}
+	sort.Slice(released, func(i, j int) bool {
+		return released[i].DNSName < released[j].DNSName
+	})
+	maintenance.Delete = append(maintenance.Delete, released...)
+	im.maintenance = maintenance
+
+	return endpoints, nil
+}
+
+// MaintenanceChanges returns the changes found during the last run of Records: the imports of the TXT ownership,
+// i.e. updates of the records without entries from no owner to the owner of their TXT records, and the deletions of
+// the imported TXT records of this instance and of the entries of this instance whose records are missing
+func (im *ConfigMapRegistry) MaintenanceChanges() *plan.Changes {
+	return im.maintenance
+}
+
+// ApplyMaintenanceChanges stores the imported ownership of the records in the ConfigMaps, deletes the imported TXT
+// records from the zones and releases the entries of missing records. The ownership TXT records are told apart from
+// the entries by their targets, the records without ownership TXT records are not changed.
+func (im *ConfigMapRegistry) ApplyMaintenanceChanges(ctx context.Context, changes *plan.Changes) error {
+	im.maintenance = nil
+	imports := make([]*ownershipEntry, 0, len(changes.UpdateNew))
+	for _, r := range changes.UpdateNew {
+		imports = append(imports, newOwnershipEntry(r))
+	}
+	txtRecords := &plan.Changes{}
+	releases := []*ownershipEntry{}
+	for _, r := range changes.Delete {
+		if isOwnershipTXTRecord(r) {
+			txtRecords.Delete = append(txtRecords.Delete, r)
+		} else {
+			releases = append(releases, newOwnershipEntry(r))
+		}
+	}
+
+	if len(imports) > 0 {
+		log.Infof("Importing the ownership of %d record(s) from TXT records into the ConfigMaps of registry %s/%s", len(imports), im.namespace, im.name)
+	}
+	if len(releases) > 0 {
+		log.Infof("Releasing the ownership of %d missing record(s) in the ConfigMaps of registry %s/%s", len(releases), im.namespace, im.name)
+	}
+	if err := im.write(ctx, imports, releases); err != nil {
+		return err
+	}
+	if txtRecords.HasChanges() {
+		log.Infof("Deleting %d imported ownership TXT record(s)", len(txtRecords.Delete))
+		return im.provider.ApplyChanges(ctx, txtRecords)
+	}
+	return nil
+}
+
+// MissingRecords returns nil because the ownership is not stored in records
+func (im *ConfigMapRegistry) MissingRecords() []*endpoint.Endpoint {
+	return nil
+}
+
+// ApplyChanges updates dns provider with the changes and stores the ownership of the changed records.
+// The ownership of created records is stored before they are created so that they are never without owner,
+// it is released again if they failed to be created. The one of deleted records is released once they are deleted.
+func (im *ConfigMapRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	filteredChanges := &plan.Changes{
+		Create:    changes.Create,
+		UpdateNew: filterOwnedRecords(im.ownerID, changes.UpdateNew),
+		UpdateOld: filterOwnedRecords(im.ownerID, changes.UpdateOld),
+		Delete:    filterOwnedRecords(im.ownerID, changes.Delete),
+	}
+
+	claims := []*ownershipEntry{}
+	for _, r := range filteredChanges.Create {
+		if r.Labels == nil {
+			r.Labels = make(map[string]string)
+		}
+		r.Labels[endpoint.OwnerLabelKey] = im.ownerID
+		claims = append(claims, newOwnershipEntry(r))
+	}
+	for _, r := range filteredChanges.UpdateNew {
+		claims = append(claims, newOwnershipEntry(r))
+	}
+	if err := im.write(ctx, claims, nil); err != nil {
+		return err
+	}
+
+	err := im.provider.ApplyChanges(ctx, filteredChanges)
+
+	// the claims of failed creates are released, the records may belong to someone else
+	releases := []*ownershipEntry{}
+	for _, r := range filteredChanges.Create {
+		if provider.RecordErrorFor(err, r) != nil {
+			releases = append(releases, newOwnershipEntry(r))
+		}
+	}
+	for _, r := range filteredChanges.Delete {
+		if provider.RecordErrorFor(err, r) == nil {
+			releases = append(releases, newOwnershipEntry(r))
+		}
+	}
+	if writeErr := im.write(ctx, nil, releases); writeErr != nil {
+		if err == nil {
+			return writeErr
+		}
+		log.Errorf("Failed to release the ownership of failed or deleted records: %v", writeErr)
+	}
+	return err
+}
+
+// PropertyValuesEqual compares two attribute values for equality
+func (im *ConfigMapRegistry) PropertyValuesEqual(name string, previous string, current string) bool {
+	return im.provider.PropertyValuesEqual(name, previous, current)
+}
+
+// AdjustEndpoints modifies the endpoints as needed by the specific provider
+func (im *ConfigMapRegistry) AdjustEndpoints(endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	return im.provider.AdjustEndpoints(endpoints)
+}
+
+/**
+  ConfigMap registry specific private methods
+*/
+
+// readEntries returns the entries of all ConfigMaps of the registry by their key
+func (im *ConfigMapRegistry) readEntries(ctx context.Context) (map[string]*ownershipEntry, error) {
+	configMaps, err := im.client.CoreV1().ConfigMaps(im.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: configMapRegistryLabelKey + "=" + im.name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the ConfigMaps of registry %s/%s: %w", im.namespace, im.name, err)
+	}
+
+	entries := map[string]*ownershipEntry{}
+	im.locations = map[string]string{}
+	for _, cm := range configMaps.Items {
+		for key, value := range cm.Data {
+			entry := &ownershipEntry{}
+			if err := json.Unmarshal([]byte(value), entry); err != nil {
+				log.Warnf("Ignoring invalid ownership entry %s in ConfigMap %s/%s: %v", key, cm.Namespace, cm.Name, err)
+				continue
+			}
+			entries[key] = entry
+			im.locations[key] = cm.Name
+		}
+	}
+	return entries, nil
+}
+
+// write stores the upserted entries in their shard and removes the deleted ones from the ConfigMaps
+func (im *ConfigMapRegistry) write(ctx context.Context, upserts, deletes []*ownershipEntry) error {
+	if len(upserts) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	type shardChanges struct {
+		upserts map[string]string
+		deletes []string
+	}
+	changesByConfigMap := map[string]*shardChanges{}
+	changesOf := func(name string) *shardChanges {
+		if _, ok := changesByConfigMap[name]; !ok {
+			changesByConfigMap[name] = &shardChanges{upserts: map[string]string{}}
+		}
+		return changesByConfigMap[name]
+	}
+
+	for _, entry := range upserts {
+		key := ownershipKey(entry.DNSName, entry.RecordType, entry.SetIdentifier)
+		value, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		name := im.shardName(key)
+		changesOf(name).upserts[key] = string(value)
+		// the number of shards has changed since the entry was stored
+		if location, ok := im.locations[key]; ok && location != name {
+			changesOf(location).deletes = append(changesOf(location).deletes, key)
+		}
+	}
+	for _, entry := range deletes {
+		key := ownershipKey(entry.DNSName, entry.RecordType, entry.SetIdentifier)
+		name := im.shardName(key)
+		if location, ok := im.locations[key]; ok {
+			name = location
+		}
+		changesOf(name).deletes = append(changesOf(name).deletes, key)
+	}
+
+	for name, changes := range changesByConfigMap {
+		if im.dryRun {
+			log.Infof("Would store the ownership of %d and release the one of %d record(s) in ConfigMap %s/%s", len(changes.upserts), len(changes.deletes), im.namespace, name)
+			continue
+		}
+		if err := im.updateConfigMap(ctx, name, changes.upserts, changes.deletes); err != nil {
+			return fmt.Errorf("failed to update ConfigMap %s/%s: %w", im.namespace, name, err)
+		}
+		for key := range changes.upserts {
+			im.locations[key] = name
+		}
+		for _, key := range changes.deletes {
+			if im.locations[key] == name {
+				delete(im.locations, key)
+			}
+		}
+	}
+	return nil
+}
+
+// updateConfigMap applies the changes to the ConfigMap, it is retried if another instance updated it concurrently
+func (im *ConfigMapRegistry) updateConfigMap(ctx context.Context, name string, upserts map[string]string, deletes []string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := im.client.CoreV1().ConfigMaps(im.namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			if len(upserts) == 0 {
+				return nil
+			}
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: im.namespace,
+					Name:      name,
+					Labels: map[string]string{
+						configMapRegistryLabelKey: im.name,
+						managedByLabelKey:         managedByLabelValue,
+					},
+				},
+				Data: upserts,
+			}
+			_, err = im.client.CoreV1().ConfigMaps(im.namespace).Create(ctx, cm, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				return apierrors.NewConflict(corev1.Resource("configmaps"), name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		changed := false
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		for key, value := range upserts {
+			if cm.Data[key] != value {
+				cm.Data[key] = value
+				changed = true
+			}
+		}
+		for _, key := range deletes {
+			if _, ok := cm.Data[key]; ok {
+				delete(cm.Data, key)
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		_, err = im.client.CoreV1().ConfigMaps(im.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// shardName returns the name of the ConfigMap storing the entry with the given key
+func (im *ConfigMapRegistry) shardName(key string) string {
+	hash, _ := hex.DecodeString(key[:16])
+	return fmt.Sprintf("%s-%d", im.name, binary.BigEndian.Uint64(hash)%uint64(im.shards))
+}
+
+// txtKey returns the key of the TXT ownership labels of the endpoint
+func (im *ConfigMapRegistry) txtKey(ep *endpoint.Endpoint) string {
+	dnsNameSplit := strings.Split(ep.DNSName, ".")
+	// If specified, replace a leading asterisk in the generated txt record name with some other string
+	if im.wildcardReplacement != "" && dnsNameSplit[0] == "*" {
+		dnsNameSplit[0] = im.wildcardReplacement
+	}
+	return fmt.Sprintf("%s::%s", strings.Join(dnsNameSplit, "."), ep.SetIdentifier)
+}
+
+// isOwnershipTXTRecord returns true if the record is an ownership TXT record of the TXT registry
+func isOwnershipTXTRecord(ep *endpoint.Endpoint) bool {
+	if ep.RecordType != endpoint.RecordTypeTXT || len(ep.Targets) == 0 {
+		return false
+	}
+	_, err := endpoint.NewLabelsFromString(ep.Targets[0], nil)
+	return err == nil
+}
+
+func newOwnershipEntry(ep *endpoint.Endpoint) *ownershipEntry {
+	return &ownershipEntry{
+		DNSName:       ep.DNSName,
+		RecordType:    ep.RecordType,
+		SetIdentifier: ep.SetIdentifier,
+		Labels:        ep.Labels.Persistent(),
+	}
+}
+
+// ownershipKey returns the ConfigMap key of the ownership entry of a record. Record names and set identifiers
+// may contain characters which are invalid in keys, hence the key is a hash of them.
+func ownershipKey(dnsName, recordType, setIdentifier string) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(strings.TrimSuffix(dnsName, ".")) + "/" + recordType + "/" + setIdentifier))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,325 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+)
+
+func newTestConfigMapRegistry(t *testing.T, shards int, dryRun bool, records ...*endpoint.Endpoint) (*ConfigMapRegistry, *inmemory.InMemoryProvider, *fake.Clientset) {
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{Create: records}))
+
+	client := fake.NewSimpleClientset()
+	r, err := NewConfigMapRegistry(p, client, "default", "external-dns", shards, "owner", "txt.", "", "", dryRun)
+	require.NoError(t, err)
+	return r, p, client
+}
+
+// storedEntries returns the ownership entries of all ConfigMaps by the name of their record
+func storedEntries(t *testing.T, client *fake.Clientset) map[string]*ownershipEntry {
+	configMaps, err := client.CoreV1().ConfigMaps("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	entries := map[string]*ownershipEntry{}
+	for _, cm := range configMaps.Items {
+		assert.Equal(t, "external-dns", cm.Labels[configMapRegistryLabelKey])
+		for key, value := range cm.Data {
+			entry := &ownershipEntry{}
+			require.NoError(t, json.Unmarshal([]byte(value), entry))
+			assert.Equal(t, ownershipKey(entry.DNSName, entry.RecordType, entry.SetIdentifier), key)
+			entries[entry.DNSName+"/"+entry.RecordType] = entry
+		}
+	}
+	return entries
+}
+
+func TestNewConfigMapRegistry(t *testing.T) {
+	p := inmemory.NewInMemoryProvider()
+	client := fake.NewSimpleClientset()
+
+	_, err := NewConfigMapRegistry(p, client, "default", "external-dns", 1, "", "", "", "", false)
+	assert.Error(t, err, "owner id is required")
+
+	_, err = NewConfigMapRegistry(p, client, "default", "", 1, "owner", "", "", "", false)
+	assert.Error(t, err, "name is required")
+
+	_, err = NewConfigMapRegistry(p, client, "default", "external-dns", 0, "owner", "", "", "", false)
+	assert.Error(t, err, "at least one shard is required")
+
+	_, err = NewConfigMapRegistry(p, client, "default", "external-dns", 1, "owner", "txt.", "-txt", "", false)
+	assert.Error(t, err, "prefix and suffix are mutual exclusive")
+
+	r, err := NewConfigMapRegistry(p, client, "default", "external-dns", 1, "owner", "", "", "", false)
+	require.NoError(t, err)
+	assert.Equal(t, p, r.provider)
+	assert.Equal(t, "owner", r.ownerID)
+}
+
+func TestConfigMapRegistryApplyChanges(t *testing.T) {
+	ctx := context.Background()
+	r, p, client := newTestConfigMapRegistry(t, 1, false,
+		newEndpointWithOwner("existing.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+		newEndpointWithOwner("foreign.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, ""),
+	)
+	require.NoError(t, r.write(ctx, []*ownershipEntry{
+		newOwnershipEntry(newEndpointWithOwnerResource("existing.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "owner", "ingress/default/existing")),
+		newOwnershipEntry(newEndpointWithOwner("foreign.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, "other")),
+	}, nil))
+
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	assert.True(t, testutils.SameEndpoints(records, []*endpoint.Endpoint{
+		newEndpointWithOwnerResource("existing.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "owner", "ingress/default/existing"),
+		newEndpointWithOwner("foreign.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, "other"),
+	}))
+
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwnerAndLabels("new.test-zone.example.org", "new.loadbalancer.com", endpoint.RecordTypeCNAME, "", endpoint.Labels{
+				endpoint.ResourceLabelKey: "ingress/default/new",
+				// the labels for the resolution of conflicts are not stored
+				endpoint.ResourceCreationTimestampLabelKey: "2022-01-01T00:00:00Z",
+				endpoint.ResourcePriorityLabelKey:          "10",
+			}),
+		},
+		Delete: []*endpoint.Endpoint{
+			newEndpointWithOwner("existing.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "owner"),
+			// records of other owners are never deleted
+			newEndpointWithOwner("foreign.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, "other"),
+		},
+	}))
+
+	// the zone contains the records only
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	names := []string{}
+	for _, record := range records {
+		names = append(names, record.DNSName+"/"+record.RecordType)
+	}
+	assert.ElementsMatch(t, []string{"new.test-zone.example.org/CNAME", "foreign.test-zone.example.org/A"}, names)
+
+	entries := storedEntries(t, client)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, endpoint.Labels{endpoint.OwnerLabelKey: "owner", endpoint.ResourceLabelKey: "ingress/default/new"}, entries["new.test-zone.example.org/CNAME"].Labels)
+	assert.Equal(t, endpoint.Labels{endpoint.OwnerLabelKey: "other"}, entries["foreign.test-zone.example.org/A"].Labels)
+}
+
+func TestConfigMapRegistryUpdate(t *testing.T) {
+	ctx := context.Background()
+	r, _, client := newTestConfigMapRegistry(t, 1, false,
+		newEndpointWithOwner("web.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+	)
+	old := newEndpointWithOwnerResource("web.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "owner", "ingress/default/old")
+	require.NoError(t, r.write(ctx, []*ownershipEntry{newOwnershipEntry(old)}, nil))
+
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{old},
+		UpdateNew: []*endpoint.Endpoint{newEndpointWithOwnerResource("web.test-zone.example.org", "5.6.7.8", endpoint.RecordTypeA, "owner", "ingress/default/new")},
+	}))
+
+	entries := storedEntries(t, client)
+	assert.Equal(t, "ingress/default/new", entries["web.test-zone.example.org/A"].Labels[endpoint.ResourceLabelKey])
+}
+
+func TestConfigMapRegistryImportsTXTOwnership(t *testing.T) {
+	ctx := context.Background()
+	r, p, client := newTestConfigMapRegistry(t, 1, false,
+		newEndpointWithOwner("bar.test-zone.example.org", "my-domain.com", endpoint.RecordTypeCNAME, ""),
+		newEndpointWithOwner("txt.bar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner,external-dns/resource=service/default/bar\"", endpoint.RecordTypeTXT, ""),
+		newEndpointWithOwner("txt.cname-bar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner,external-dns/resource=service/default/bar\"", endpoint.RecordTypeTXT, ""),
+		newEndpointWithOwner("baz.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+		newEndpointWithOwner("txt.baz.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, ""),
+		newEndpointWithOwner("qux.test-zone.example.org", "random", endpoint.RecordTypeTXT, ""),
+	)
+
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	assert.True(t, testutils.SameEndpoints(records, []*endpoint.Endpoint{
+		newEndpointWithOwnerResource("bar.test-zone.example.org", "my-domain.com", endpoint.RecordTypeCNAME, "owner", "service/default/bar"),
+		newEndpointWithOwner("baz.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "other"),
+		newEndpointWithOwner("qux.test-zone.example.org", "random", endpoint.RecordTypeTXT, ""),
+	}))
+
+	// the imports are only planned by Records
+	assert.Empty(t, storedEntries(t, client))
+	changes := MaintenanceChanges(r)
+	require.NotNil(t, changes)
+	assert.True(t, testutils.SameEndpoints(changes.UpdateOld, []*endpoint.Endpoint{
+		newEndpointWithOwner("bar.test-zone.example.org", "my-domain.com", endpoint.RecordTypeCNAME, ""),
+		newEndpointWithOwner("baz.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+	}))
+	assert.Empty(t, changes.Create)
+	assert.Empty(t, changes.Delete)
+
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ApplyMaintenanceChanges(ctx, r, changes))
+	assert.Nil(t, MaintenanceChanges(r))
+
+	// the zone is not changed by the imports
+	after, err := p.Records(ctx)
+	require.NoError(t, err)
+	assert.True(t, testutils.SameEndpoints(after, records))
+
+	entries := storedEntries(t, client)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, endpoint.Labels{endpoint.OwnerLabelKey: "owner", endpoint.ResourceLabelKey: "service/default/bar"}, entries["bar.test-zone.example.org/CNAME"].Labels)
+	assert.Equal(t, endpoint.Labels{endpoint.OwnerLabelKey: "other"}, entries["baz.test-zone.example.org/A"].Labels)
+
+	_, err = r.Records(ctx)
+	require.NoError(t, err)
+	changes = MaintenanceChanges(r)
+	assert.Empty(t, changes.UpdateNew, "imported records are not imported again")
+
+	// the imported TXT records of this instance are deleted once their import is stored
+	names := []string{}
+	for _, txt := range changes.Delete {
+		names = append(names, txt.DNSName)
+	}
+	assert.ElementsMatch(t, []string{"txt.bar.test-zone.example.org", "txt.cname-bar.test-zone.example.org"}, names)
+	require.NoError(t, ApplyMaintenanceChanges(ctx, r, changes))
+	after, err = p.Records(ctx)
+	require.NoError(t, err)
+	names = []string{}
+	for _, record := range after {
+		names = append(names, record.DNSName)
+	}
+	assert.ElementsMatch(t, []string{
+		"bar.test-zone.example.org",
+		"baz.test-zone.example.org",
+		"txt.baz.test-zone.example.org",
+		"qux.test-zone.example.org",
+	}, names, "the TXT records of other owners are kept")
+	assert.Len(t, storedEntries(t, client), 2)
+}
+
+func TestConfigMapRegistryReleasesOwnershipOfMissingRecords(t *testing.T) {
+	ctx := context.Background()
+	r, _, client := newTestConfigMapRegistry(t, 1, false)
+	require.NoError(t, r.write(ctx, []*ownershipEntry{
+		newOwnershipEntry(newEndpointWithOwner("gone.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "owner")),
+		newOwnershipEntry(newEndpointWithOwner("foreign.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, "other")),
+	}, nil))
+
+	// the releases are only planned by Records
+	_, err := r.Records(ctx)
+	require.NoError(t, err)
+	changes := MaintenanceChanges(r)
+	require.Len(t, changes.Delete, 1)
+	assert.Equal(t, "gone.test-zone.example.org", changes.Delete[0].DNSName)
+	assert.Len(t, storedEntries(t, client), 2)
+
+	require.NoError(t, ApplyMaintenanceChanges(ctx, r, changes))
+	entries := storedEntries(t, client)
+	assert.Len(t, entries, 1)
+	assert.Contains(t, entries, "foreign.test-zone.example.org/A", "the entries of other owners are kept")
+}
+
+func TestConfigMapRegistryReleasesFailedCreates(t *testing.T) {
+	ctx := context.Background()
+	r, _, client := newTestConfigMapRegistry(t, 1, false,
+		newEndpointWithOwner("manual.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+	)
+
+	err := r.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{newEndpointWithOwner("manual.test-zone.example.org", "5.6.7.8", endpoint.RecordTypeA, "")},
+	})
+	require.Error(t, err)
+	assert.Empty(t, storedEntries(t, client), "the record created by hand is not claimed")
+}
+
+func TestConfigMapRegistrySharding(t *testing.T) {
+	ctx := context.Background()
+	r, _, client := newTestConfigMapRegistry(t, 4, false)
+
+	create := []*endpoint.Endpoint{}
+	for i := 0; i < 40; i++ {
+		create = append(create, newEndpointWithOwner(fmt.Sprintf("host-%d.test-zone.example.org", i), "1.2.3.4", endpoint.RecordTypeA, ""))
+	}
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Create: create}))
+
+	configMaps, err := client.CoreV1().ConfigMaps("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	names := []string{}
+	for _, cm := range configMaps.Items {
+		names = append(names, cm.Name)
+	}
+	assert.ElementsMatch(t, []string{"external-dns-0", "external-dns-1", "external-dns-2", "external-dns-3"}, names)
+	assert.Len(t, storedEntries(t, client), 40)
+
+	// entries are moved to their new shard once they are written after the number of shards changed
+	r.shards = 1
+	_, err = r.Records(ctx)
+	require.NoError(t, err)
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		UpdateOld: create[:1],
+		UpdateNew: []*endpoint.Endpoint{newEndpointWithOwner(create[0].DNSName, "5.6.7.8", endpoint.RecordTypeA, "owner")},
+	}))
+	cm, err := client.CoreV1().ConfigMaps("default").Get(ctx, "external-dns-0", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, cm.Data, ownershipKey(create[0].DNSName, endpoint.RecordTypeA, ""))
+	assert.Len(t, storedEntries(t, client), 40)
+}
+
+func TestConfigMapRegistryDryRun(t *testing.T) {
+	ctx := context.Background()
+	r, _, client := newTestConfigMapRegistry(t, 1, true)
+
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{newEndpointWithOwner("new.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "")},
+	}))
+
+	configMaps, err := client.CoreV1().ConfigMaps("default").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, configMaps.Items)
+}
+
+func TestConfigMapRegistryIgnoresInvalidEntries(t *testing.T) {
+	ctx := context.Background()
+	r, _, client := newTestConfigMapRegistry(t, 1, false,
+		newEndpointWithOwner("web.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+	)
+	_, err := client.CoreV1().ConfigMaps("default").Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "external-dns-0",
+			Labels:    map[string]string{configMapRegistryLabelKey: "external-dns"},
+		},
+		Data: map[string]string{"invalid": "{"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Empty(t, records[0].Labels[endpoint.OwnerLabelKey])
+}
@@ -38,6 +38,30 @@ type Registry interface {
 	MissingRecords() []*endpoint.Endpoint
 }
 
+// maintainer is implemented by registries changing their own ownership records, e.g. to adopt or migrate them.
+// Records must not apply these changes, they are applied by the controller under its policies.
+type maintainer interface {
+	MaintenanceChanges() *plan.Changes
+	ApplyMaintenanceChanges(ctx context.Context, changes *plan.Changes) error
+}
+
+// MaintenanceChanges returns the changes of the ownership records found by the last call of Records,
+// nil if the registry does not maintain them
+func MaintenanceChanges(r Registry) *plan.Changes {
+	if m, ok := r.(maintainer); ok {
+		return m.MaintenanceChanges()
+	}
+	return nil
+}
+
+// ApplyMaintenanceChanges applies the changes of the ownership records which passed the policies
+func ApplyMaintenanceChanges(ctx context.Context, r Registry, changes *plan.Changes) error {
+	if m, ok := r.(maintainer); ok {
+		return m.ApplyMaintenanceChanges(ctx, changes)
+	}
+	return nil
+}
+
 // TODO(ideahitme): consider moving this to Plan
 func filterOwnedRecords(ownerID string, eps []*endpoint.Endpoint) []*endpoint.Endpoint {
 	filtered := []*endpoint.Endpoint{}
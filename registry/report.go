@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// OwnershipOwned is the status of records owned by an instance of ExternalDNS
+	OwnershipOwned = "owned"
+	// OwnershipUnowned is the status of records not owned by any instance of ExternalDNS
+	OwnershipUnowned = "unowned"
+	// OwnershipOrphaned is the status of ownership records whose records do not exist anymore
+	OwnershipOrphaned = "orphaned"
+)
+
+// OwnershipReportFormats are the supported formats of the ownership report
+var OwnershipReportFormats = []string{"table", "json"}
+
+// OwnershipReportEntry describes the ownership of a single record
+type OwnershipReportEntry struct {
+	DNSName       string   `json:"dnsName"`
+	RecordType    string   `json:"recordType"`
+	SetIdentifier string   `json:"setIdentifier,omitempty"`
+	Targets       []string `json:"targets"`
+	Owner         string   `json:"owner,omitempty"`
+	Resource      string   `json:"resource,omitempty"`
+	Status        string   `json:"status"`
+	// RecordName is the name of the record an orphaned ownership record with a hashed name belonged to
+	RecordName string `json:"recordName,omitempty"`
+}
+
+// orphanedRecordsLister is implemented by registries detecting ownership records whose records do not exist anymore
+type orphanedRecordsLister interface {
+	OrphanedRecords() []*endpoint.Endpoint
+}
+
+// OrphanedRecords returns the orphaned ownership records found by the last call of Records which read the records
+// from the provider, nil if the registry does not detect them or has only read its records cache so far
+func OrphanedRecords(r Registry) []*endpoint.Endpoint {
+	if lister, ok := r.(orphanedRecordsLister); ok {
+		return lister.OrphanedRecords()
+	}
+	return nil
+}
+
+// NewOwnershipReport returns the ownership of the records as returned by Records and of the orphaned
+// ownership records, sorted by name, type and set identifier
+func NewOwnershipReport(records, orphans []*endpoint.Endpoint) []OwnershipReportEntry {
+	entries := make([]OwnershipReportEntry, 0, len(records)+len(orphans))
+	add := func(ep *endpoint.Endpoint, status string) {
+		entries = append(entries, OwnershipReportEntry{
+			DNSName:       ep.DNSName,
+			RecordType:    ep.RecordType,
+			SetIdentifier: ep.SetIdentifier,
+			Targets:       ep.Targets,
+			Owner:         ep.Labels[endpoint.OwnerLabelKey],
+			Resource:      ep.Labels[endpoint.ResourceLabelKey],
+			Status:        status,
+			RecordName:    ep.Labels[endpoint.RecordNameLabelKey],
+		})
+	}
+	for _, ep := range records {
+		if ep.Labels[endpoint.OwnerLabelKey] == "" {
+			add(ep, OwnershipUnowned)
+		} else {
+			add(ep, OwnershipOwned)
+		}
+	}
+	for _, ep := range orphans {
+		add(ep, OwnershipOrphaned)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].DNSName != entries[j].DNSName {
+			return entries[i].DNSName < entries[j].DNSName
+		}
+		if entries[i].RecordType != entries[j].RecordType {
+			return entries[i].RecordType < entries[j].RecordType
+		}
+		return entries[i].SetIdentifier < entries[j].SetIdentifier
+	})
+	return entries
+}
+
+// WriteOwnershipReport writes the ownership report in one of OwnershipReportFormats
+func WriteOwnershipReport(w io.Writer, entries []OwnershipReportEntry, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tTYPE\tSET IDENTIFIER\tOWNER\tRESOURCE\tSTATUS")
+		for _, e := range entries {
+			name := e.DNSName
+			if e.RecordName != "" {
+				name += " (" + e.RecordName + ")"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", name, e.RecordType, orNone(e.SetIdentifier), orNone(e.Owner), orNone(e.Resource), e.Status)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown ownership report format %q, supported formats: %s", format, strings.Join(OwnershipReportFormats, ", "))
+	}
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
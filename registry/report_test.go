@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+)
+
+func TestNewOwnershipReport(t *testing.T) {
+	records := []*endpoint.Endpoint{
+		newEndpointWithOwnerResource("b.example.org", "1.2.3.4", endpoint.RecordTypeA, "owner", "ingress/default/b"),
+		newEndpointWithOwner("a.example.org", "1.2.3.5", endpoint.RecordTypeA, ""),
+		newEndpointWithOwner("a.example.org", "a.example.com", endpoint.RecordTypeCNAME, ""),
+	}
+	orphans := []*endpoint.Endpoint{
+		newEndpointWithOwner("c.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, "other"),
+	}
+
+	assert.Equal(t, []OwnershipReportEntry{
+		{DNSName: "a.example.org", RecordType: endpoint.RecordTypeA, Targets: []string{"1.2.3.5"}, Status: OwnershipUnowned},
+		{DNSName: "a.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: []string{"a.example.com"}, Status: OwnershipUnowned},
+		{DNSName: "b.example.org", RecordType: endpoint.RecordTypeA, Targets: []string{"1.2.3.4"}, Owner: "owner", Resource: "ingress/default/b", Status: OwnershipOwned},
+		{DNSName: "c.example.org", RecordType: endpoint.RecordTypeTXT, Targets: []string{"\"heritage=external-dns,external-dns/owner=other\""}, Owner: "other", Status: OwnershipOrphaned},
+	}, NewOwnershipReport(records, orphans))
+}
+
+func TestWriteOwnershipReport(t *testing.T) {
+	entries := []OwnershipReportEntry{
+		{DNSName: "a.example.org", RecordType: endpoint.RecordTypeA, Targets: []string{"1.2.3.5"}, Status: OwnershipUnowned},
+		{DNSName: "b.example.org", RecordType: endpoint.RecordTypeA, Targets: []string{"1.2.3.4"}, Owner: "owner", Resource: "ingress/default/b", Status: OwnershipOwned},
+	}
+
+	var table bytes.Buffer
+	require.NoError(t, WriteOwnershipReport(&table, entries, "table"))
+	assert.Equal(t, `NAME           TYPE  SET IDENTIFIER  OWNER   RESOURCE           STATUS
+a.example.org  A     <none>          <none>  <none>             unowned
+b.example.org  A     <none>          owner   ingress/default/b  owned
+`, table.String())
+
+	var json bytes.Buffer
+	require.NoError(t, WriteOwnershipReport(&json, entries[1:], "json"))
+	assert.JSONEq(t, `[{"dnsName":"b.example.org","recordType":"A","targets":["1.2.3.4"],"owner":"owner","resource":"ingress/default/b","status":"owned"}]`, json.String())
+
+	assert.Error(t, WriteOwnershipReport(&json, entries, "xml"))
+}
+
+func TestOrphanedRecords(t *testing.T) {
+	r, err := NewNoopRegistry(inmemory.NewInMemoryProvider())
+	require.NoError(t, err)
+	assert.Nil(t, OrphanedRecords(r))
+}
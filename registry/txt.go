@@ -18,8 +18,13 @@ package registry
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base32"
 	"errors"
 	"fmt"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -32,16 +37,53 @@ import (
 
 const recordTemplate = "%{record_type}"
 
+const (
+	// TXTFormatLegacy writes the TXT records in the old format only, e.g. foo.example.org
+	TXTFormatLegacy = "legacy"
+	// TXTFormatDual writes the TXT records in both the old and the new format
+	TXTFormatDual = "dual"
+	// TXTFormatTyped writes the TXT records in the new format containing the record type only, e.g. a-foo.example.org,
+	// and deletes the TXT records in the old format
+	TXTFormatTyped = "typed"
+)
+
+// TXTFormats are the supported formats of the TXT records
+var TXTFormats = []string{TXTFormatLegacy, TXTFormatDual, TXTFormatTyped}
+
+const (
+	// TXTNameMapperAffix names the TXT records after their records, with the record type and the prefix or suffix added
+	// to the first label, e.g. a-foo.example.org
+	TXTNameMapperAffix = "affix"
+	// TXTNameMapperHashed replaces the first label of the records by a hash of their name, e.g. a-<hash>.example.org,
+	// which is valid for wildcards and long labels as well. The names of the records are stored in the TXT records.
+	TXTNameMapperHashed = "hashed"
+)
+
+// TXTNameMappers are the supported strategies to name the TXT records
+var TXTNameMappers = []string{TXTNameMapperAffix, TXTNameMapperHashed}
+
+// maxDNSNameLength is the maximum length of a DNS name in its text representation without trailing dot
+const maxDNSNameLength = 253
+
+// maxTXTStringLength is the maximum length of a character string of a TXT record
+const maxTXTStringLength = 255
+
 // TXTRegistry implements registry interface with ownership implemented via associated TXT records
 type TXTRegistry struct {
 	provider provider.Provider
 	ownerID  string // refers to the owner id of the current instance
 	mapper   nameMapper
+	// nameMapping is the strategy of mapper, one of TXTNameMappers
+	nameMapping string
 
 	// cache the records in memory and update on an interval instead.
 	recordsCache            []*endpoint.Endpoint
 	recordsCacheRefreshTime time.Time
 	cacheInterval           time.Duration
+	// cacheStore persists the records cache, it is loaded once by the first run of Records (default: disabled)
+	cacheStore       RecordsCacheStore
+	cacheFingerprint string
+	cacheStoreLoaded bool
 
 	// optional string to use to replace the asterisk in wildcard entries - without using this,
 	// registry TXT records corresponding to wildcard records will be invalid (and rejected by most providers), due to
@@ -52,10 +94,157 @@ type TXTRegistry struct {
 
 	// missingTXTRecords stores TXT records which are missing after the migration to the new format
 	missingTXTRecords []*endpoint.Endpoint
+
+	// labelKeys encrypt and sign the labels of the TXT records, they are stored in plain text if nil
+	labelKeys *endpoint.LabelKeys
+	// acceptUnprotected accepts plain TXT records in spite of labelKeys and protects the ones of this instance
+	acceptUnprotected bool
+
+	// previousOwnerIDs are the owner ids whose records are adopted by this instance
+	previousOwnerIDs map[string]struct{}
+
+	// adoptRegex matches the DNS names of the records without owner which are adopted by this instance
+	adoptRegex *regexp.Regexp
+
+	// gcInterval is the minimum interval between the garbage collections of orphaned TXT records planned by Records (default: disabled)
+	gcInterval time.Duration
+	// gcMaxDeletions limits the number of orphaned TXT records deleted by a single garbage collection, and of TXT records
+	// in the old format deleted by a single synchronization, 0 means unlimited
+	gcMaxDeletions int
+	lastGCTime     time.Time
+
+	// maintenance stores the changes of the TXT records of this instance found during the last run of Records,
+	// i.e. adoptions, protections, deletions in the old format and garbage collections
+	maintenance *plan.Changes
+
+	// format is the format of the TXT records written, TXT records are read in all formats
+	format string
+	// existingTXTRecords stores the keys of the TXT records found during the last run of Records
+	existingTXTRecords map[string]struct{}
+	// orphanedRecords stores the TXT records of any owner found during the last run of Records which belong to none of the records
+	orphanedRecords []*endpoint.Endpoint
+
+	// ownerIDs are the owner ids this instance acts on behalf of, including ownerID
+	ownerIDs map[string]struct{}
+	// ownerIDMappings assign the records of resources to the owner ids, the first matching one applies
+	ownerIDMappings []OwnerIDMapping
+}
+
+// OwnerIDMapping assigns the records of the resources matching ResourcePattern to OwnerID
+type OwnerIDMapping struct {
+	OwnerID string
+	// ResourcePattern is matched against the resource the records originate from, i.e. <kind>/<namespace>/<name>,
+	// using the syntax of path.Match, e.g. */team-a/* for all resources of the namespace team-a
+	ResourcePattern string
+}
+
+// ParseOwnerIDMapping parses an OwnerIDMapping given as <owner-id>=<resource-pattern>
+func ParseOwnerIDMapping(mapping string) (OwnerIDMapping, error) {
+	parts := strings.SplitN(mapping, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return OwnerIDMapping{}, fmt.Errorf("owner id mapping %q is not in the format <owner-id>=<resource-pattern>", mapping)
+	}
+	if _, err := path.Match(parts[1], ""); err != nil {
+		return OwnerIDMapping{}, fmt.Errorf("invalid resource pattern in owner id mapping %q: %w", mapping, err)
+	}
+	return OwnerIDMapping{OwnerID: parts[0], ResourcePattern: parts[1]}, nil
+}
+
+// TXTRegistryOption configures optional features of the TXTRegistry
+type TXTRegistryOption func(*TXTRegistry)
+
+// WithTXTLabelKeys encrypts and signs the labels of the TXT records with the given keys. TXT records which are not
+// protected by the keys are considered as unowned, unless acceptUnprotected is set to migrate plain TXT records.
+// The plain TXT records of this instance are protected when accepted.
+func WithTXTLabelKeys(keys *endpoint.LabelKeys, acceptUnprotected bool) TXTRegistryOption {
+	return func(im *TXTRegistry) {
+		im.labelKeys = keys
+		im.acceptUnprotected = acceptUnprotected
+	}
+}
+
+// WithTXTPreviousOwnerIDs adopts the records owned by any of the given owner ids, i.e. their TXT records are
+// rewritten to the owner id of this instance. It is used to rename an owner id or to hand records over between instances.
+func WithTXTPreviousOwnerIDs(ownerIDs []string) TXTRegistryOption {
+	return func(im *TXTRegistry) {
+		im.previousOwnerIDs = make(map[string]struct{}, len(ownerIDs))
+		for _, ownerID := range ownerIDs {
+			// owner ids may be given as comma separated list as well
+			for _, id := range strings.Split(ownerID, ",") {
+				if id = strings.TrimSpace(id); id != "" {
+					im.previousOwnerIDs[id] = struct{}{}
+				}
+			}
+		}
+	}
+}
+
+// WithTXTAdoptRegex adopts the existing records without owner whose DNS names match the given regex, if they are desired.
+// Desired endpoints labeled for adoption adopt existing records regardless of the regex.
+func WithTXTAdoptRegex(adoptRegex *regexp.Regexp) TXTRegistryOption {
+	return func(im *TXTRegistry) {
+		if adoptRegex != nil && adoptRegex.String() != "" {
+			im.adoptRegex = adoptRegex
+		}
+	}
+}
+
+// WithTXTGarbageCollection deletes the TXT records of this instance whose records do not exist anymore.
+// The garbage is collected while reading the records at most once per interval, deleting at most maxDeletions
+// TXT records at once (0 means unlimited).
+func WithTXTGarbageCollection(interval time.Duration, maxDeletions int) TXTRegistryOption {
+	return func(im *TXTRegistry) {
+		im.gcInterval = interval
+		im.gcMaxDeletions = maxDeletions
+	}
+}
+
+// WithTXTFormat writes the TXT records in the given format, one of TXTFormats (default: TXTFormatDual).
+// TXT records of the other formats are deleted along with their records.
+func WithTXTFormat(format string) TXTRegistryOption {
+	return func(im *TXTRegistry) {
+		im.format = format
+	}
+}
+
+// WithTXTNameMapper names the TXT records with the given strategy, one of TXTNameMappers (default: TXTNameMapperAffix).
+// The hashed names cannot be combined with a prefix, suffix or wildcard replacement.
+func WithTXTNameMapper(nameMapping string) TXTRegistryOption {
+	return func(im *TXTRegistry) {
+		im.nameMapping = nameMapping
+	}
+}
+
+// WithTXTRecordsCacheStore persists the records cache in the given store, so that it survives restarts. The persisted
+// records are used as long as the cache interval has not passed since they were read from the provider and they
+// were read with the configuration identified by the fingerprint. The persisted records are invalidated whenever
+// applying changes fails. It has no effect without a cache interval.
+func WithTXTRecordsCacheStore(store RecordsCacheStore, fingerprint string) TXTRegistryOption {
+	return func(im *TXTRegistry) {
+		im.cacheStore = store
+		im.cacheFingerprint = fingerprint
+	}
+}
+
+// WithTXTOwnerIDs makes the registry act on behalf of the given owner ids in addition to its own one. Records are created
+// with the owner id requested by the resource, mapped by the first matching mapping or the own owner id otherwise.
+// Records of each owner id are only updated by resources assigned to the same owner id.
+func WithTXTOwnerIDs(ownerIDs []string, mappings []OwnerIDMapping) TXTRegistryOption {
+	return func(im *TXTRegistry) {
+		for _, ownerID := range ownerIDs {
+			// owner ids may be given as comma separated list as well
+			for _, id := range strings.Split(ownerID, ",") {
+				if id = strings.TrimSpace(id); id != "" {
+					im.ownerIDs[id] = struct{}{}
+				}
+			}
+		}
+		im.ownerIDMappings = mappings
+	}
 }
 
 // NewTXTRegistry returns new TXTRegistry object
-func NewTXTRegistry(provider provider.Provider, txtPrefix, txtSuffix, ownerID string, cacheInterval time.Duration, txtWildcardReplacement string, managedRecordTypes []string) (*TXTRegistry, error) {
+func NewTXTRegistry(provider provider.Provider, txtPrefix, txtSuffix, ownerID string, cacheInterval time.Duration, txtWildcardReplacement string, managedRecordTypes []string, opts ...TXTRegistryOption) (*TXTRegistry, error) {
 	if ownerID == "" {
 		return nil, errors.New("owner id cannot be empty")
 	}
@@ -66,18 +255,48 @@ func NewTXTRegistry(provider provider.Provider, txtPrefix, txtSuffix, ownerID st
 
 	mapper := newaffixNameMapper(txtPrefix, txtSuffix, txtWildcardReplacement)
 
-	return &TXTRegistry{
+	im := &TXTRegistry{
 		provider:            provider,
 		ownerID:             ownerID,
 		mapper:              mapper,
 		cacheInterval:       cacheInterval,
 		wildcardReplacement: txtWildcardReplacement,
 		managedRecordTypes:  managedRecordTypes,
-	}, nil
+		format:              TXTFormatDual,
+		nameMapping:         TXTNameMapperAffix,
+		ownerIDs:            map[string]struct{}{ownerID: {}},
+	}
+	for _, opt := range opts {
+		opt(im)
+	}
+	switch im.nameMapping {
+	case TXTNameMapperAffix:
+	case TXTNameMapperHashed:
+		if txtPrefix != "" || txtSuffix != "" || txtWildcardReplacement != "" {
+			return nil, errors.New("hashed TXT names cannot be combined with a TXT prefix, suffix or wildcard replacement")
+		}
+		im.mapper = hashedNameMapper{}
+	default:
+		return nil, fmt.Errorf("unknown TXT name mapper: %s", im.nameMapping)
+	}
+	for _, mapping := range im.ownerIDMappings {
+		if !im.isOwner(mapping.OwnerID) {
+			return nil, fmt.Errorf("owner id %s of the mapping of %s is none of the owner ids", mapping.OwnerID, mapping.ResourcePattern)
+		}
+	}
+	for previousOwnerID := range im.previousOwnerIDs {
+		if im.isOwner(previousOwnerID) {
+			return nil, errors.New("previous owner ids cannot contain the owner ids")
+		}
+	}
+	if im.format != TXTFormatLegacy && im.format != TXTFormatDual && im.format != TXTFormatTyped {
+		return nil, fmt.Errorf("unknown TXT format: %s", im.format)
+	}
+	return im, nil
 }
 
 func getSupportedTypes() []string {
-	return []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME, endpoint.RecordTypeNS}
+	return []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME, endpoint.RecordTypeNS, endpoint.RecordTypeMX, endpoint.RecordTypeSRV, endpoint.RecordTypeCAA, endpoint.RecordTypePTR}
 }
 
 func (im *TXTRegistry) GetDomainFilter() endpoint.DomainFilterInterface {
@@ -88,6 +307,10 @@ func (im *TXTRegistry) GetDomainFilter() endpoint.DomainFilterInterface {
 // If TXT records was created previously to indicate ownership its corresponding value
 // will be added to the endpoints Labels map
 func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	if im.cacheInterval > 0 && im.cacheStore != nil && !im.cacheStoreLoaded {
+		im.cacheStoreLoaded = true
+		im.loadRecordsCache(ctx)
+	}
 	// If we have the zones cached AND we have refreshed the cache since the
 	// last given interval, then just use the cached results.
 	if im.recordsCache != nil && time.Since(im.recordsCacheRefreshTime) < im.cacheInterval {
@@ -105,6 +328,12 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 
 	labelMap := map[string]endpoint.Labels{}
 	txtRecordsMap := map[string]struct{}{}
+	existingTXTRecords := map[string]struct{}{}
+	// ownTXTRecords are the TXT records of this instance which are not rewritten
+	ownTXTRecords := []*endpoint.Endpoint{}
+	maintenance := &plan.Changes{}
+	protected := 0
+	names := txtEndpointNames(im.mapper, records)
 
 	for _, record := range records {
 		if record.RecordType != endpoint.RecordTypeTXT {
@@ -112,7 +341,16 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 			continue
 		}
 		// We simply assume that TXT records for the registry will always have only one target.
-		labels, err := endpoint.NewLabelsFromString(record.Targets[0])
+		labels, err := endpoint.NewLabelsFromString(record.Targets[0], im.keysFor(record.DNSName, record.SetIdentifier))
+		unprotected := false
+		if err == endpoint.ErrUnprotected && im.acceptUnprotected {
+			labels, err = endpoint.NewLabelsFromString(record.Targets[0], nil)
+			unprotected = true
+		}
+		if err == endpoint.ErrUnprotected || err == endpoint.ErrUnverifiable {
+			log.Warnf("Ignoring the ownership of TXT record %s: %v", record.DNSName, err)
+			err = endpoint.ErrInvalidHeritage
+		}
 		if err == endpoint.ErrInvalidHeritage {
 			// if no heritage is found or it is invalid
 			// case when value of txt record cannot be identified
@@ -123,9 +361,34 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 		if err != nil {
 			return nil, err
 		}
-		key := fmt.Sprintf("%s::%s", im.mapper.toEndpointName(record.DNSName), record.SetIdentifier)
+		key := fmt.Sprintf("%s::%s", txtEndpointName(im.mapper, record.DNSName, labels, names), record.SetIdentifier)
+		// the records keep the labels as found until the rewrites are applied
+		if _, ok := im.previousOwnerIDs[labels[endpoint.OwnerLabelKey]]; ok {
+			if adopted := im.withLabels(record, adoptLabels(labels, im.ownerID)); txtTooLong(adopted) {
+				log.Warnf("Cannot adopt TXT record %s, its labels would exceed %d characters", record.DNSName, maxTXTStringLength)
+			} else {
+				log.Infof("Adopting TXT record %s from owner %s", record.DNSName, labels[endpoint.OwnerLabelKey])
+				maintenance.UpdateOld = append(maintenance.UpdateOld, withFoundLabels(record, labels))
+				maintenance.UpdateNew = append(maintenance.UpdateNew, adopted)
+			}
+		} else if unprotected && im.isOwner(labels[endpoint.OwnerLabelKey]) {
+			if rewritten := im.withLabels(record, labels); txtTooLong(rewritten) {
+				log.Warnf("Cannot protect TXT record %s, its labels would exceed %d characters", record.DNSName, maxTXTStringLength)
+			} else {
+				protected++
+				maintenance.UpdateOld = append(maintenance.UpdateOld, withFoundLabels(record, labels))
+				maintenance.UpdateNew = append(maintenance.UpdateNew, rewritten)
+			}
+		} else if im.isOwner(labels[endpoint.OwnerLabelKey]) {
+			ownTXTRecords = append(ownTXTRecords, withFoundLabels(record, labels))
+		}
 		labelMap[key] = labels
 		txtRecordsMap[record.DNSName] = struct{}{}
+		existingTXTRecords[txtRecordKey(record.DNSName, record.SetIdentifier)] = struct{}{}
+	}
+	im.existingTXTRecords = existingTXTRecords
+	if protected > 0 {
+		log.Infof("Protecting %d plain TXT record(s) of this instance", protected)
 	}
 
 	for _, ep := range endpoints {
@@ -141,13 +404,15 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 		key := fmt.Sprintf("%s::%s", dnsName, ep.SetIdentifier)
 		if labels, ok := labelMap[key]; ok {
 			for k, v := range labels {
-				ep.Labels[k] = v
+				if k != endpoint.RecordNameLabelKey {
+					ep.Labels[k] = v
+				}
 			}
 		}
 
 		// Handle the migration of TXT records created before the new format (introduced in v0.12.0).
 		// The migration is done for the TXT records owned by this instance only.
-		if len(txtRecordsMap) > 0 && ep.Labels[endpoint.OwnerLabelKey] == im.ownerID {
+		if len(txtRecordsMap) > 0 && im.isOwner(ep.Labels[endpoint.OwnerLabelKey]) {
 			if plan.IsManagedRecord(ep.RecordType, im.managedRecordTypes) {
 				// Get desired TXT records and detect the missing ones
 				desiredTXTs := im.generateTXTRecord(ep)
@@ -157,9 +422,15 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 						missingDesiredTXTs = append(missingDesiredTXTs, desiredTXT)
 					}
 				}
-				if len(desiredTXTs) > len(missingDesiredTXTs) {
+				managed := false
+				for _, txt := range im.generateTXTRecordWithFormat(ep, TXTFormatDual) {
+					if _, exists := txtRecordsMap[txt.DNSName]; exists {
+						managed = true
+					}
+				}
+				if managed {
 					// Add missing TXT records only if those are managed (by externaldns) ones.
-					// The unmanaged record has both of the desired TXT records missing.
+					// The unmanaged record has the TXT records of all formats missing.
 					missingEndpoints = append(missingEndpoints, missingDesiredTXTs...)
 				}
 			}
@@ -170,32 +441,236 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 	if im.cacheInterval > 0 {
 		im.recordsCache = endpoints
 		im.recordsCacheRefreshTime = time.Now()
+		im.saveRecordsCache(ctx)
 	}
 
 	im.missingTXTRecords = missingEndpoints
+	im.orphanedRecords = im.orphanedTXTRecords(records, true)
+
+	if im.format == TXTFormatTyped {
+		maintenance.Delete = append(maintenance.Delete, im.legacyTXTRecords(endpoints, ownTXTRecords, txtRecordsMap, names)...)
+	}
+
+	if im.gcInterval > 0 && time.Since(im.lastGCTime) >= im.gcInterval {
+		im.lastGCTime = time.Now()
+		maintenance.Delete = append(maintenance.Delete, im.garbage(records)...)
+	}
+
+	im.maintenance = maintenance
 
 	return endpoints, nil
 }
 
+// loadRecordsCache warms the records cache up with the records of the cache store, unless they are expired
+// or were read with another configuration
+func (im *TXTRegistry) loadRecordsCache(ctx context.Context) {
+	snapshot, err := im.cacheStore.Load(ctx)
+	if err != nil {
+		log.Warnf("Failed to load the records cache: %v", err)
+		return
+	}
+	if snapshot == nil {
+		return
+	}
+	if snapshot.Fingerprint != im.cacheFingerprint {
+		log.Info("Discarding the stored records cache of another configuration")
+		return
+	}
+	if time.Since(snapshot.RefreshTime) >= im.cacheInterval {
+		log.Debug("Discarding the expired stored records cache")
+		return
+	}
+	log.Infof("Loaded %d record(s) from the stored records cache", len(snapshot.Records))
+	im.recordsCache = snapshot.Records
+	im.recordsCacheRefreshTime = snapshot.RefreshTime
+}
+
+// saveRecordsCache persists the records cache in the cache store, failures are logged only
+func (im *TXTRegistry) saveRecordsCache(ctx context.Context) {
+	if im.cacheStore == nil || im.recordsCache == nil {
+		return
+	}
+	snapshot := &RecordsSnapshot{
+		Fingerprint: im.cacheFingerprint,
+		RefreshTime: im.recordsCacheRefreshTime,
+		Records:     im.recordsCache,
+	}
+	if err := im.cacheStore.Save(ctx, snapshot); err != nil {
+		log.Warnf("Failed to store the records cache: %v", err)
+	}
+}
+
+// invalidateRecordsCache drops the records cache, so that the records are read from the provider by the next run of Records
+func (im *TXTRegistry) invalidateRecordsCache(ctx context.Context) {
+	im.recordsCache = nil
+	if im.cacheStore == nil {
+		return
+	}
+	if err := im.cacheStore.Invalidate(ctx); err != nil {
+		log.Warnf("Failed to invalidate the stored records cache: %v", err)
+	}
+}
+
+// keysFor returns the label keys bound to the TXT record with the given name and set identifier
+func (im *TXTRegistry) keysFor(txtName, setIdentifier string) *endpoint.LabelKeys {
+	return im.labelKeys.ForRecord(txtName, endpoint.RecordTypeTXT, setIdentifier)
+}
+
+// withFoundLabels returns a copy of the TXT record labeled with the labels stored in it
+func withFoundLabels(record *endpoint.Endpoint, labels endpoint.Labels) *endpoint.Endpoint {
+	found := *record
+	found.Labels = labels
+	return &found
+}
+
+// withLabels returns a copy of the TXT record storing the given labels
+func (im *TXTRegistry) withLabels(record *endpoint.Endpoint, labels endpoint.Labels) *endpoint.Endpoint {
+	txt := endpoint.NewEndpointWithTTL(record.DNSName, endpoint.RecordTypeTXT, record.RecordTTL, labels.Persistent().Serialize(true, im.keysFor(record.DNSName, record.SetIdentifier))).WithSetIdentifier(record.SetIdentifier)
+	txt.ProviderSpecific = record.ProviderSpecific
+	return txt
+}
+
+// adoptLabels returns a copy of the labels owned by the given owner id
+func adoptLabels(labels endpoint.Labels, ownerID string) endpoint.Labels {
+	adopted := endpoint.Labels{}
+	for k, v := range labels {
+		adopted[k] = v
+	}
+	adopted[endpoint.OwnerLabelKey] = ownerID
+	return adopted
+}
+
+// MaintenanceChanges returns the changes of the TXT records of this instance found during the last run of Records.
+// They adopt the TXT records of the previous owner ids, protect the plain TXT records of this instance and delete
+// the TXT records in the old format and, if the garbage collection is due, the orphaned TXT records.
+func (im *TXTRegistry) MaintenanceChanges() *plan.Changes {
+	return im.maintenance
+}
+
+// ApplyMaintenanceChanges applies the changes of the TXT records of this instance which passed the policies.
+// The records cache is invalidated, so that the next run of Records reads the records with their new ownership
+// and plans the changes which failed again.
+func (im *TXTRegistry) ApplyMaintenanceChanges(ctx context.Context, changes *plan.Changes) error {
+	im.maintenance = nil
+	defer im.invalidateRecordsCache(ctx)
+
+	if err := im.provider.ApplyChanges(ctx, changes); err != nil {
+		return err
+	}
+	for _, txt := range changes.Delete {
+		delete(im.existingTXTRecords, txtRecordKey(txt.DNSName, txt.SetIdentifier))
+	}
+	log.Infof("Applied %d change(s) of the TXT records of this instance", len(changes.UpdateNew)+len(changes.Delete))
+	return nil
+}
+
+// OrphanedRecords returns the TXT records of any owner which belong to none of the records.
+// The orphaned records are collected during the run of Records method reading the records from the provider,
+// they are nil as long as Records only returned the records cache loaded from the cache store.
+func (im *TXTRegistry) OrphanedRecords() []*endpoint.Endpoint {
+	return im.orphanedRecords
+}
+
+// GarbageChanges returns the deletions of the TXT records of this instance whose records do not exist anymore,
+// in both the old and the new format. Like the changes of MaintenanceChanges, they are applied by ApplyMaintenanceChanges.
+func (im *TXTRegistry) GarbageChanges(ctx context.Context) (*plan.Changes, error) {
+	records, err := im.provider.Records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &plan.Changes{Delete: im.garbage(records)}, nil
+}
+
+// garbage returns the orphaned TXT records of this instance deleted by a single garbage collection
+func (im *TXTRegistry) garbage(records []*endpoint.Endpoint) []*endpoint.Endpoint {
+	orphans := im.orphanedTXTRecords(records, false)
+	if len(orphans) == 0 {
+		log.Debug("No orphaned TXT records found")
+		return nil
+	}
+	deletions := orphans
+	if im.gcMaxDeletions > 0 && len(deletions) > im.gcMaxDeletions {
+		deletions = deletions[:im.gcMaxDeletions]
+	}
+	for _, txt := range deletions {
+		if name := txt.Labels[endpoint.RecordNameLabelKey]; name != "" {
+			log.Infof("Deleting orphaned TXT record %s of record %s", txt.DNSName, name)
+		} else {
+			log.Infof("Deleting orphaned TXT record %s", txt.DNSName)
+		}
+	}
+	if left := len(orphans) - len(deletions); left > 0 {
+		log.Infof("%d orphaned TXT record(s) left for the next garbage collection", left)
+	}
+	return deletions
+}
+
+// orphanedTXTRecords returns the TXT records owned by this instance, or by any owner if anyOwner is set, which belong
+// to none of the records, sorted by name. The returned TXT records are copies holding the labels stored in them.
+func (im *TXTRegistry) orphanedTXTRecords(records []*endpoint.Endpoint, anyOwner bool) []*endpoint.Endpoint {
+	used := map[string]struct{}{}
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeTXT {
+			continue
+		}
+		used[txtRecordKey(im.mapper.toTXTName(record.DNSName), record.SetIdentifier)] = struct{}{}
+		used[txtRecordKey(im.mapper.toNewTXTName(record.DNSName, record.RecordType), record.SetIdentifier)] = struct{}{}
+	}
+
+	orphans := []*endpoint.Endpoint{}
+	for _, record := range records {
+		if record.RecordType != endpoint.RecordTypeTXT || len(record.Targets) == 0 {
+			continue
+		}
+		if _, ok := used[txtRecordKey(record.DNSName, record.SetIdentifier)]; ok {
+			continue
+		}
+		labels, err := endpoint.NewLabelsFromString(record.Targets[0], im.keysFor(record.DNSName, record.SetIdentifier))
+		if err == endpoint.ErrUnprotected && im.acceptUnprotected {
+			labels, err = endpoint.NewLabelsFromString(record.Targets[0], nil)
+		}
+		if err != nil || (!anyOwner && !im.isOwner(labels[endpoint.OwnerLabelKey])) {
+			continue
+		}
+		orphan := *record
+		orphan.Labels = labels
+		orphans = append(orphans, &orphan)
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		return orphans[i].DNSName < orphans[j].DNSName
+	})
+	return orphans
+}
+
 // MissingRecords returns the TXT record to be created.
 // The missing records are collected during the run of Records method.
 func (im *TXTRegistry) MissingRecords() []*endpoint.Endpoint {
 	return im.missingTXTRecords
 }
 
-// generateTXTRecord generates both "old" and "new" TXT records.
-// Once we decide to drop old format we need to drop toTXTName() and rename toNewTXTName
+// generateTXTRecord generates the TXT records of the configured format.
 func (im *TXTRegistry) generateTXTRecord(r *endpoint.Endpoint) []*endpoint.Endpoint {
+	return im.generateTXTRecordWithFormat(r, im.format)
+}
+
+// generateTXTRecordWithFormat generates the "old" and/or "new" TXT records of the given format.
+// The "old" TXT record is generated in the typed format as well if the name of the "new" one is invalid.
+func (im *TXTRegistry) generateTXTRecordWithFormat(r *endpoint.Endpoint, format string) []*endpoint.Endpoint {
 	// Missing TXT records are added to the set of changes.
 	// Obviously, we don't need any other TXT record for them.
 	if r.RecordType == endpoint.RecordTypeTXT {
 		return nil
 	}
 	// old TXT record format
-	txt := endpoint.NewEndpoint(im.mapper.toTXTName(r.DNSName), endpoint.RecordTypeTXT, r.Labels.Serialize(true)).WithSetIdentifier(r.SetIdentifier)
+	txtName := im.mapper.toTXTName(r.DNSName)
+	txt := endpoint.NewEndpoint(txtName, endpoint.RecordTypeTXT, im.txtLabels(r, txtName).Serialize(true, im.keysFor(txtName, r.SetIdentifier))).WithSetIdentifier(r.SetIdentifier)
 	txt.ProviderSpecific = r.ProviderSpecific
+	if format == TXTFormatLegacy {
+		return []*endpoint.Endpoint{txt}
+	}
 	// new TXT record format (containing record type)
-	txtNew := endpoint.NewEndpoint(im.mapper.toNewTXTName(r.DNSName, r.RecordType), endpoint.RecordTypeTXT, r.Labels.Serialize(true))
+	txtNewName := im.mapper.toNewTXTName(r.DNSName, r.RecordType)
+	txtNew := endpoint.NewEndpoint(txtNewName, endpoint.RecordTypeTXT, im.txtLabels(r, txtNewName).Serialize(true, im.keysFor(txtNewName, r.SetIdentifier)))
 	if txtNew != nil {
 		txtNew.WithSetIdentifier(r.SetIdentifier)
 		txtNew.ProviderSpecific = r.ProviderSpecific
@@ -203,24 +678,150 @@ func (im *TXTRegistry) generateTXTRecord(r *endpoint.Endpoint) []*endpoint.Endpo
 		return []*endpoint.Endpoint{txt}
 	}
 
+	if format == TXTFormatTyped {
+		return []*endpoint.Endpoint{txtNew}
+	}
 	return []*endpoint.Endpoint{txt, txtNew}
 }
 
+// txtLabels returns the labels stored in the TXT record with the given name of the record. Hashed names cannot be
+// mapped back, hence the name of the record is stored as well, unless the labels would exceed a TXT string then.
+func (im *TXTRegistry) txtLabels(r *endpoint.Endpoint, txtName string) endpoint.Labels {
+	labels := r.Labels.Persistent()
+	delete(labels, endpoint.RecordNameLabelKey)
+	if im.nameMapping != TXTNameMapperHashed {
+		return labels
+	}
+	named := labels.Persistent()
+	named[endpoint.RecordNameLabelKey] = strings.ToLower(strings.TrimSuffix(r.DNSName, "."))
+	if len(strings.Trim(named.Serialize(true, im.keysFor(txtName, r.SetIdentifier)), "\"")) > maxTXTStringLength {
+		log.Debugf("Not storing the name of record %s in TXT record %s, the labels would exceed %d characters", r.DNSName, txtName, maxTXTStringLength)
+		return labels
+	}
+	return named
+}
+
+// staleTXTRecords returns the existing TXT records of the record in the formats which are not written anymore
+func (im *TXTRegistry) staleTXTRecords(r *endpoint.Endpoint) []*endpoint.Endpoint {
+	if im.format == TXTFormatDual {
+		return nil
+	}
+	desired := map[string]struct{}{}
+	for _, txt := range im.generateTXTRecord(r) {
+		desired[txtRecordKey(txt.DNSName, txt.SetIdentifier)] = struct{}{}
+	}
+	stale := []*endpoint.Endpoint{}
+	for _, txt := range im.generateTXTRecordWithFormat(r, TXTFormatDual) {
+		key := txtRecordKey(txt.DNSName, txt.SetIdentifier)
+		if _, ok := desired[key]; ok {
+			continue
+		}
+		if _, ok := im.existingTXTRecords[key]; ok {
+			delete(im.existingTXTRecords, key)
+			stale = append(stale, txt)
+		}
+	}
+	return stale
+}
+
+// legacyTXTRecords returns the TXT records of this instance in the old format whose records all have TXT records
+// in the new format, so that they can be deleted. Like the garbage collection, at most gcMaxDeletions are returned at once.
+func (im *TXTRegistry) legacyTXTRecords(endpoints, ownTXTRecords []*endpoint.Endpoint, txtRecordsMap map[string]struct{}, names map[string]string) []*endpoint.Endpoint {
+	// the records sharing a TXT record in the old format, keyed by the TXT record
+	recordsByLegacyTXT := map[string][]*endpoint.Endpoint{}
+	for _, ep := range endpoints {
+		if ep.RecordType == endpoint.RecordTypeTXT || !plan.IsManagedRecord(ep.RecordType, im.managedRecordTypes) {
+			continue
+		}
+		key := txtRecordKey(im.mapper.toTXTName(ep.DNSName), ep.SetIdentifier)
+		recordsByLegacyTXT[key] = append(recordsByLegacyTXT[key], ep)
+	}
+
+	legacy := []*endpoint.Endpoint{}
+	for _, txt := range ownTXTRecords {
+		// TXT records in the new format map to a different name in the old format
+		if !strings.EqualFold(im.mapper.toTXTName(txtEndpointName(im.mapper, txt.DNSName, txt.Labels, names)), strings.TrimSuffix(txt.DNSName, ".")) {
+			continue
+		}
+		key := txtRecordKey(txt.DNSName, txt.SetIdentifier)
+		migrated := true
+		for _, ep := range recordsByLegacyTXT[key] {
+			if _, exists := txtRecordsMap[im.mapper.toNewTXTName(ep.DNSName, ep.RecordType)]; !exists {
+				migrated = false
+			}
+		}
+		if migrated {
+			legacy = append(legacy, txt)
+		}
+	}
+	sort.Slice(legacy, func(i, j int) bool {
+		return legacy[i].DNSName < legacy[j].DNSName
+	})
+	deletions := legacy
+	if im.gcMaxDeletions > 0 && len(deletions) > im.gcMaxDeletions {
+		deletions = deletions[:im.gcMaxDeletions]
+	}
+	for _, txt := range deletions {
+		log.Infof("Deleting TXT record %s in the old format", txt.DNSName)
+	}
+	if left := len(legacy) - len(deletions); left > 0 {
+		log.Infof("%d TXT record(s) in the old format left for the next synchronization", left)
+	}
+	return deletions
+}
+
+// txtRecordKey returns the key of the TXT record with the given name and set identifier
+func txtRecordKey(dnsName, setIdentifier string) string {
+	return strings.ToLower(strings.TrimSuffix(dnsName, ".")) + "::" + setIdentifier
+}
+
 // ApplyChanges updates dns provider with the changes
 // for each created/deleted record it will also take into account TXT records for creation/deletion
 func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	updateOld, updateNew := im.ownedUpdates(changes)
 	filteredChanges := &plan.Changes{
-		Create:    changes.Create,
-		UpdateNew: filterOwnedRecords(im.ownerID, changes.UpdateNew),
-		UpdateOld: filterOwnedRecords(im.ownerID, changes.UpdateOld),
-		Delete:    filterOwnedRecords(im.ownerID, changes.Delete),
+		Create:    []*endpoint.Endpoint{},
+		UpdateNew: updateNew,
+		UpdateOld: updateOld,
+		Delete:    im.ownedRecords(changes.Delete),
 	}
-	for _, r := range filteredChanges.Create {
+	adoptOld, adoptNew := im.adoptableRecords(changes)
+	// the records whose TXT records cannot be stored are not changed
+	var rejected provider.ChangeErrors
+	for _, r := range changes.Create {
+		ownerID, ok := im.requestedOwner(r)
+		if !ok {
+			log.Debugf(`Skipping endpoint %v because this instance does not act on behalf of the requested owner id "%s"`, r, r.Labels[endpoint.RequestedOwnerLabelKey])
+			continue
+		}
 		if r.Labels == nil {
 			r.Labels = make(map[string]string)
 		}
-		r.Labels[endpoint.OwnerLabelKey] = im.ownerID
-
+		r.Labels[endpoint.OwnerLabelKey] = ownerID
+		delete(r.Labels, endpoint.AdoptLabelKey)
+		delete(r.Labels, endpoint.RequestedOwnerLabelKey)
+		if err := im.txtLengthError(r); err != nil {
+			rejected = append(rejected, &provider.RecordError{Endpoint: r, Err: err})
+			continue
+		}
+		filteredChanges.Create = append(filteredChanges.Create, r)
+	}
+	for _, r := range updateNew {
+		delete(r.Labels, endpoint.AdoptLabelKey)
+		delete(r.Labels, endpoint.RequestedOwnerLabelKey)
+	}
+	if len(updateOld) == len(updateNew) {
+		filteredChanges.UpdateOld, filteredChanges.UpdateNew = []*endpoint.Endpoint{}, []*endpoint.Endpoint{}
+		for i, r := range updateNew {
+			if err := im.txtLengthError(r); err != nil {
+				rejected = append(rejected, &provider.RecordError{Endpoint: r, Err: err})
+				continue
+			}
+			filteredChanges.UpdateOld = append(filteredChanges.UpdateOld, updateOld[i])
+			filteredChanges.UpdateNew = append(filteredChanges.UpdateNew, r)
+		}
+	}
+	for _, r := range filteredChanges.Create {
 		filteredChanges.Create = append(filteredChanges.Create, im.generateTXTRecord(r)...)
 
 		if im.cacheInterval > 0 {
@@ -233,6 +834,7 @@ func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes)
 		// !!! TXT record value is uniquely generated from the Labels of the endpoint. Hence old TXT record can be uniquely reconstructed
 		// !!! After migration to the new TXT registry format we can drop records in old format here!!!
 		filteredChanges.Delete = append(filteredChanges.Delete, im.generateTXTRecord(r)...)
+		filteredChanges.Delete = append(filteredChanges.Delete, im.staleTXTRecords(r)...)
 
 		if im.cacheInterval > 0 {
 			im.removeFromCache(r)
@@ -244,6 +846,8 @@ func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes)
 		// when we updateOld TXT records for which value has changed (due to new label) this would still work because
 		// !!! TXT record value is uniquely generated from the Labels of the endpoint. Hence old TXT record can be uniquely reconstructed
 		filteredChanges.UpdateOld = append(filteredChanges.UpdateOld, im.generateTXTRecord(r)...)
+		// the TXT records of formats not written anymore are deleted instead of updated
+		filteredChanges.Delete = append(filteredChanges.Delete, im.staleTXTRecords(r)...)
 		// remove old version of record from cache
 		if im.cacheInterval > 0 {
 			im.removeFromCache(r)
@@ -259,11 +863,154 @@ func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes)
 		}
 	}
 
+	// adopted records have no TXT records yet, hence they are created
+	for i, r := range adoptNew {
+		ownerID, ok := im.requestedOwner(r)
+		if !ok {
+			continue
+		}
+		r.Labels[endpoint.OwnerLabelKey] = ownerID
+		delete(r.Labels, endpoint.AdoptLabelKey)
+		delete(r.Labels, endpoint.RequestedOwnerLabelKey)
+		if err := im.txtLengthError(r); err != nil {
+			rejected = append(rejected, &provider.RecordError{Endpoint: r, Err: err})
+			continue
+		}
+		log.Infof("Adopting %s record %s without owner", r.RecordType, r.DNSName)
+		filteredChanges.UpdateOld = append(filteredChanges.UpdateOld, adoptOld[i])
+		filteredChanges.UpdateNew = append(filteredChanges.UpdateNew, r)
+		filteredChanges.Create = append(filteredChanges.Create, im.generateTXTRecord(r)...)
+		if im.cacheInterval > 0 {
+			im.removeFromCache(adoptOld[i])
+			im.addToCache(r)
+		}
+	}
+
 	// when caching is enabled, disable the provider from using the cache
 	if im.cacheInterval > 0 {
 		ctx = context.WithValue(ctx, provider.RecordsContextKey, nil)
 	}
-	return im.provider.ApplyChanges(ctx, filteredChanges)
+	err := im.provider.ApplyChanges(ctx, filteredChanges)
+	if im.cacheInterval > 0 {
+		if err != nil {
+			// the cache contains the changes which may have failed
+			im.invalidateRecordsCache(ctx)
+		} else {
+			im.saveRecordsCache(ctx)
+		}
+	}
+	return withRejected(err, rejected)
+}
+
+// txtLengthError returns an error if the labels of the record exceed a character string in one of its TXT records
+func (im *TXTRegistry) txtLengthError(r *endpoint.Endpoint) error {
+	for _, txt := range im.generateTXTRecord(r) {
+		if txtTooLong(txt) {
+			return fmt.Errorf("the labels stored in TXT record %s exceed %d characters", txt.DNSName, maxTXTStringLength)
+		}
+	}
+	return nil
+}
+
+// txtTooLong tells whether the labels stored in the TXT record exceed a character string
+func txtTooLong(txt *endpoint.Endpoint) bool {
+	return len(strings.Trim(txt.Targets[0], "\"")) > maxTXTStringLength
+}
+
+// withRejected adds the errors of the records rejected by the registry to the error of the provider.
+// The rejected records are lost if the provider failed for unknown records, all records failed then.
+func withRejected(err error, rejected provider.ChangeErrors) error {
+	if len(rejected) == 0 {
+		return err
+	}
+	if err == nil {
+		return rejected
+	}
+	var changeErrors provider.ChangeErrors
+	if errors.As(err, &changeErrors) {
+		return append(changeErrors, rejected...)
+	}
+	return err
+}
+
+// OwnerIDs returns the sorted owner ids this instance acts on behalf of
+func (im *TXTRegistry) OwnerIDs() []string {
+	ownerIDs := make([]string, 0, len(im.ownerIDs))
+	for ownerID := range im.ownerIDs {
+		ownerIDs = append(ownerIDs, ownerID)
+	}
+	sort.Strings(ownerIDs)
+	return ownerIDs
+}
+
+// isOwner tells whether this instance acts on behalf of the owner id
+func (im *TXTRegistry) isOwner(ownerID string) bool {
+	_, ok := im.ownerIDs[ownerID]
+	return ok
+}
+
+// requestedOwner returns the owner id the desired endpoint is managed under, false if this instance
+// does not act on behalf of the owner id requested by the endpoint
+func (im *TXTRegistry) requestedOwner(ep *endpoint.Endpoint) (string, bool) {
+	ownerID := ep.Labels[endpoint.RequestedOwnerLabelKey]
+	if ownerID == "" {
+		return im.ownerID, true
+	}
+	return ownerID, im.isOwner(ownerID)
+}
+
+// ownedRecords returns the records owned by any of the owner ids of this instance
+func (im *TXTRegistry) ownedRecords(eps []*endpoint.Endpoint) []*endpoint.Endpoint {
+	filtered := []*endpoint.Endpoint{}
+	for _, ep := range eps {
+		if endpointOwner := ep.Labels[endpoint.OwnerLabelKey]; !im.isOwner(endpointOwner) {
+			log.Debugf(`Skipping endpoint %v because owner id does not match, found: "%s"`, ep, endpointOwner)
+			continue
+		}
+		filtered = append(filtered, ep)
+	}
+	return filtered
+}
+
+// ownedUpdates returns the updates of records owned by any of the owner ids of this instance,
+// which are desired by endpoints assigned to the same owner id
+func (im *TXTRegistry) ownedUpdates(changes *plan.Changes) (updateOld, updateNew []*endpoint.Endpoint) {
+	if len(changes.UpdateOld) != len(changes.UpdateNew) {
+		return im.ownedRecords(changes.UpdateOld), im.ownedRecords(changes.UpdateNew)
+	}
+	updateOld, updateNew = []*endpoint.Endpoint{}, []*endpoint.Endpoint{}
+	for i, r := range changes.UpdateNew {
+		old := changes.UpdateOld[i]
+		ownerID := old.Labels[endpoint.OwnerLabelKey]
+		if !im.isOwner(ownerID) {
+			log.Debugf(`Skipping endpoint %v because owner id does not match, found: "%s"`, r, ownerID)
+			continue
+		}
+		if requested, _ := im.requestedOwner(r); requested != ownerID {
+			log.Debugf(`Skipping endpoint %v because it is owned by "%s" but requested by "%s"`, r, ownerID, requested)
+			continue
+		}
+		updateOld = append(updateOld, old)
+		updateNew = append(updateNew, r)
+	}
+	return updateOld, updateNew
+}
+
+// adoptableRecords returns the updates of records without owner which are desired by endpoints labeled for adoption
+func (im *TXTRegistry) adoptableRecords(changes *plan.Changes) (updateOld, updateNew []*endpoint.Endpoint) {
+	if len(changes.UpdateOld) != len(changes.UpdateNew) {
+		return nil, nil
+	}
+	for i, r := range changes.UpdateNew {
+		old := changes.UpdateOld[i]
+		if old.Labels[endpoint.OwnerLabelKey] != "" || r.Labels[endpoint.AdoptLabelKey] != "true" ||
+			old.DNSName != r.DNSName || old.RecordType != r.RecordType || old.SetIdentifier != r.SetIdentifier {
+			continue
+		}
+		updateOld = append(updateOld, old)
+		updateNew = append(updateNew, r)
+	}
+	return updateOld, updateNew
 }
 
 // PropertyValuesEqual compares two attribute values for equality
@@ -273,9 +1020,36 @@ func (im *TXTRegistry) PropertyValuesEqual(name string, previous string, current
 
 // AdjustEndpoints modifies the endpoints as needed by the specific provider
 func (im *TXTRegistry) AdjustEndpoints(endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	for _, ep := range endpoints {
+		if ep.Labels == nil {
+			ep.Labels = endpoint.NewLabels()
+		}
+		if im.adoptRegex != nil && im.adoptRegex.MatchString(ep.DNSName) {
+			ep.Labels[endpoint.AdoptLabelKey] = "true"
+		}
+		if ownerID := im.mappedOwner(ep); ownerID != "" && ep.Labels[endpoint.RequestedOwnerLabelKey] == "" {
+			ep.Labels[endpoint.RequestedOwnerLabelKey] = ownerID
+		}
+	}
 	return im.provider.AdjustEndpoints(endpoints)
 }
 
+// mappedOwner returns the owner id of the first mapping matching any of the resources of the endpoint
+func (im *TXTRegistry) mappedOwner(ep *endpoint.Endpoint) string {
+	if len(im.ownerIDMappings) == 0 || ep.Labels[endpoint.ResourceLabelKey] == "" {
+		return ""
+	}
+	resources := strings.Split(ep.Labels[endpoint.ResourceLabelKey], endpoint.ResourceLabelSeparator)
+	for _, mapping := range im.ownerIDMappings {
+		for _, resource := range resources {
+			if matched, _ := path.Match(mapping.ResourcePattern, resource); matched {
+				return mapping.OwnerID
+			}
+		}
+	}
+	return ""
+}
+
 /**
   TXT registry specific private methods
 */
@@ -291,6 +1065,32 @@ type nameMapper interface {
 	toNewTXTName(string, string) string
 }
 
+// txtEndpointNames maps the names of the TXT records of all records to the names of the records
+func txtEndpointNames(mapper nameMapper, records []*endpoint.Endpoint) map[string]string {
+	names := map[string]string{}
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeTXT {
+			continue
+		}
+		names[txtRecordKey(mapper.toTXTName(record.DNSName), "")] = record.DNSName
+		names[txtRecordKey(mapper.toNewTXTName(record.DNSName, record.RecordType), "")] = record.DNSName
+	}
+	return names
+}
+
+// txtEndpointName returns the name of the record the TXT record with the given name and labels belongs to. Names which
+// cannot be mapped back, i.e. hashed names, are looked up in the names of the TXT records of the existing records,
+// the name stored in the labels applies to TXT records whose records do not exist.
+func txtEndpointName(mapper nameMapper, txtDNSName string, labels endpoint.Labels, names map[string]string) string {
+	if name := mapper.toEndpointName(txtDNSName); name != "" {
+		return name
+	}
+	if name, ok := names[txtRecordKey(txtDNSName, "")]; ok {
+		return name
+	}
+	return labels[endpoint.RecordNameLabelKey]
+}
+
 type affixNameMapper struct {
 	prefix              string
 	suffix              string
@@ -426,6 +1226,40 @@ func (pr affixNameMapper) toNewTXTName(endpointDNSName, recordType string) strin
 	return prefix + DNSName[0] + suffix + "." + DNSName[1]
 }
 
+// hashedNameMapper replaces the first label of the records by a hash of their name. The hashed labels are valid
+// for any record, e.g. wildcards or records with labels too long to add the record type. The names cannot be mapped
+// back, the TXT registry stores the names of the records in their TXT records instead, see txtLabels.
+type hashedNameMapper struct{}
+
+var _ nameMapper = hashedNameMapper{}
+
+func (pr hashedNameMapper) toEndpointName(txtDNSName string) string {
+	return ""
+}
+
+func (pr hashedNameMapper) toTXTName(endpointDNSName string) string {
+	return pr.hashedName(endpointDNSName, "")
+}
+
+func (pr hashedNameMapper) toNewTXTName(endpointDNSName, recordType string) string {
+	return pr.hashedName(endpointDNSName, strings.ToLower(recordType)+"-")
+}
+
+// hashedName replaces the first label of the name by the prefixed hash of the name. Further labels are dropped
+// if the name would exceed the maximum length otherwise.
+func (pr hashedNameMapper) hashedName(endpointDNSName, prefix string) string {
+	name := strings.ToLower(strings.TrimSuffix(endpointDNSName, "."))
+	sum := sha256.Sum256([]byte(name))
+	// 80 bits of the hash encoded in 16 characters
+	hashed := prefix + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(sum[:10]))
+
+	parent := strings.Split(name, ".")[1:]
+	for len(parent) > 0 && len(hashed)+1+len(strings.Join(parent, ".")) > maxDNSNameLength {
+		parent = parent[1:]
+	}
+	return strings.Join(append([]string{hashed}, parent...), ".")
+}
+
 func (im *TXTRegistry) addToCache(ep *endpoint.Endpoint) {
 	if im.recordsCache != nil {
 		im.recordsCache = append(im.recordsCache, ep)
@@ -18,7 +18,10 @@ package registry
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -1042,6 +1045,631 @@ func TestCacheMethods(t *testing.T) {
 	}
 }
 
+func TestTXTRegistryLabelKeys(t *testing.T) {
+	ctx := context.Background()
+	keys, err := endpoint.NewLabelKeys([]byte("0123456789abcdef"), []byte("signing-key"))
+	require.NoError(t, err)
+
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("plain.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("plain.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("foreign.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("foreign.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, ""),
+		},
+	}))
+
+	r, err := NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{}, WithTXTLabelKeys(keys, false))
+	require.NoError(t, err)
+
+	// records are created with protected TXT records
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{newEndpointWithOwnerResource("new.test-zone.example.org", "new.loadbalancer.com", endpoint.RecordTypeCNAME, "", "ingress/default/new")},
+	}))
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	copied := ""
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeTXT && strings.Contains(record.DNSName, "new") {
+			assert.NotContains(t, record.Targets[0], "ingress/default/new")
+			labels, err := endpoint.NewLabelsFromString(record.Targets[0], keys.ForRecord(record.DNSName, endpoint.RecordTypeTXT, record.SetIdentifier))
+			require.NoError(t, err)
+			assert.Equal(t, endpoint.Labels{endpoint.OwnerLabelKey: "owner", endpoint.ResourceLabelKey: "ingress/default/new"}, labels)
+			copied = record.Targets[0]
+		}
+	}
+
+	// plain TXT records and protected TXT records copied to other records are not trusted
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("copy.test-zone.example.org", "1.2.3.6", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("copy.test-zone.example.org", copied, endpoint.RecordTypeTXT, ""),
+		},
+	}))
+	records, err = r.Records(ctx)
+	require.NoError(t, err)
+	owners := map[string]string{}
+	for _, record := range records {
+		if record.RecordType != endpoint.RecordTypeTXT {
+			owners[record.DNSName] = record.Labels[endpoint.OwnerLabelKey]
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"plain.test-zone.example.org":   "",
+		"foreign.test-zone.example.org": "",
+		"copy.test-zone.example.org":    "",
+		"new.test-zone.example.org":     "owner",
+	}, owners)
+
+	// the protected TXT records are deleted along with their record
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		Delete: []*endpoint.Endpoint{newEndpointWithOwnerResource("new.test-zone.example.org", "new.loadbalancer.com", endpoint.RecordTypeCNAME, "owner", "ingress/default/new")},
+	}))
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	for _, record := range records {
+		assert.NotContains(t, record.DNSName, "new")
+	}
+}
+
+func TestTXTRegistryLabelsLength(t *testing.T) {
+	ctx := context.Background()
+	keys, err := endpoint.NewLabelKeys([]byte("0123456789abcdef"), []byte("signing-key"))
+	require.NoError(t, err)
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+	r, err := NewTXTRegistry(p, "", "", "owner", 0, "", []string{}, WithTXTLabelKeys(keys, false))
+	require.NoError(t, err)
+
+	long := newEndpointWithOwnerResource("long.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "", "ingress/default/"+strings.Repeat("x", 100))
+	err = r.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			long,
+			newEndpointWithOwnerResource("short.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, "", "ingress/default/short"),
+		},
+	})
+	require.Error(t, err)
+	assert.Error(t, provider.RecordErrorFor(err, long), "the record whose labels do not fit into its TXT records is rejected")
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	names := []string{}
+	for _, record := range records {
+		assert.NotContains(t, record.DNSName, "long")
+		if record.RecordType == endpoint.RecordTypeTXT {
+			assert.LessOrEqual(t, len(strings.Trim(record.Targets[0], "\"")), maxTXTStringLength)
+		}
+		names = append(names, record.DNSName+"/"+record.RecordType)
+	}
+	assert.ElementsMatch(t, []string{"short.test-zone.example.org/A", "short.test-zone.example.org/TXT", "a-short.test-zone.example.org/TXT"}, names)
+}
+
+func TestTXTRegistryLabelKeysMigration(t *testing.T) {
+	ctx := context.Background()
+	keys, err := endpoint.NewLabelKeys(nil, []byte("signing-key"))
+	require.NoError(t, err)
+
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("plain.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("plain.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("foreign.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("foreign.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, ""),
+		},
+	}))
+
+	r, err := NewTXTRegistry(p, "", "", "owner", 0, "", []string{}, WithTXTLabelKeys(keys, true))
+	require.NoError(t, err)
+
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	owners := map[string]string{}
+	for _, record := range records {
+		owners[record.DNSName] = record.Labels[endpoint.OwnerLabelKey]
+	}
+	assert.Equal(t, map[string]string{
+		"plain.test-zone.example.org":   "owner",
+		"foreign.test-zone.example.org": "other",
+	}, owners)
+
+	// only the TXT records of this instance are protected, by the maintenance rather than Records
+	maintenance := r.MaintenanceChanges()
+	require.Len(t, maintenance.UpdateNew, 1)
+	assert.Equal(t, "plain.test-zone.example.org", maintenance.UpdateNew[0].DNSName)
+	require.NoError(t, r.ApplyMaintenanceChanges(ctx, maintenance))
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	targets := map[string]string{}
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeTXT {
+			targets[record.DNSName] = record.Targets[0]
+		}
+	}
+	assert.Equal(t, endpoint.Labels{endpoint.OwnerLabelKey: "owner"}.Serialize(true, keys.ForRecord("plain.test-zone.example.org", endpoint.RecordTypeTXT, "")), targets["plain.test-zone.example.org"])
+	assert.Equal(t, "\"heritage=external-dns,external-dns/owner=other\"", targets["foreign.test-zone.example.org"])
+}
+
+func TestTXTRegistryPreviousOwnerIDs(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("old.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("old.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=old1,external-dns/resource=ingress/default/old\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("a-old.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=old1,external-dns/resource=ingress/default/old\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("older.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("older.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=old2\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("foreign.test-zone.example.org", "1.2.3.6", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("foreign.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, ""),
+		},
+	}))
+
+	_, err := NewTXTRegistry(p, "", "", "new", 0, "", []string{}, WithTXTPreviousOwnerIDs([]string{"old1", "new"}))
+	require.Error(t, err)
+
+	r, err := NewTXTRegistry(p, "", "", "new", 0, "", []string{endpoint.RecordTypeA}, WithTXTPreviousOwnerIDs([]string{"old1,old2"}))
+	require.NoError(t, err)
+
+	owners := func() map[string]string {
+		records, err := r.Records(ctx)
+		require.NoError(t, err)
+		owners := map[string]string{}
+		for _, record := range records {
+			if record.RecordType == endpoint.RecordTypeA {
+				owners[record.DNSName] = record.Labels[endpoint.OwnerLabelKey]
+			}
+		}
+		return owners
+	}
+	// the records keep their previous owner until the maintenance is applied
+	assert.Equal(t, map[string]string{
+		"old.test-zone.example.org":     "old1",
+		"older.test-zone.example.org":   "old2",
+		"foreign.test-zone.example.org": "other",
+	}, owners())
+	maintenance := r.MaintenanceChanges()
+	assert.Len(t, maintenance.UpdateOld, 3)
+	assert.Len(t, maintenance.UpdateNew, 3)
+	require.NoError(t, r.ApplyMaintenanceChanges(ctx, maintenance))
+	assert.Equal(t, map[string]string{
+		"old.test-zone.example.org":     "new",
+		"older.test-zone.example.org":   "new",
+		"foreign.test-zone.example.org": "other",
+	}, owners())
+	assert.False(t, r.MaintenanceChanges().HasChanges())
+
+	// the TXT records of the previous owners are rewritten, other labels are kept
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	targets := map[string]string{}
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeTXT {
+			targets[record.DNSName] = record.Targets[0]
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"old.test-zone.example.org":     "\"heritage=external-dns,external-dns/owner=new,external-dns/resource=ingress/default/old\"",
+		"a-old.test-zone.example.org":   "\"heritage=external-dns,external-dns/owner=new,external-dns/resource=ingress/default/old\"",
+		"older.test-zone.example.org":   "\"heritage=external-dns,external-dns/owner=new\"",
+		"foreign.test-zone.example.org": "\"heritage=external-dns,external-dns/owner=other\"",
+	}, targets)
+	// the missing TXT records in the new format are created for adopted records
+	missing := r.MissingRecords()
+	require.Len(t, missing, 1)
+	assert.Equal(t, "a-older.test-zone.example.org", missing[0].DNSName)
+	assert.Equal(t, "\"heritage=external-dns,external-dns/owner=new\"", missing[0].Targets[0])
+
+	// adopted records are managed by the new owner
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		Delete: []*endpoint.Endpoint{newEndpointWithOwnerResource("old.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "new", "ingress/default/old")},
+	}))
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	for _, record := range records {
+		assert.NotContains(t, []string{"old.test-zone.example.org", "a-old.test-zone.example.org"}, record.DNSName)
+	}
+}
+
+func TestTXTRegistryPreviousOwnerIDsFailure(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("old.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("old.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=old\"", endpoint.RecordTypeTXT, ""),
+		},
+	}))
+
+	r, err := NewTXTRegistry(&failingApplyProvider{p}, "", "", "new", 0, "", []string{}, WithTXTPreviousOwnerIDs([]string{"old"}))
+	require.NoError(t, err)
+
+	// the records keep their previous owner as long as their TXT records cannot be rewritten
+	_, err = r.Records(ctx)
+	require.NoError(t, err)
+	require.Error(t, r.ApplyMaintenanceChanges(ctx, r.MaintenanceChanges()))
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	assert.True(t, r.MaintenanceChanges().HasChanges(), "the adoption is planned again")
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeA {
+			assert.Equal(t, "old", record.Labels[endpoint.OwnerLabelKey])
+		}
+	}
+}
+
+// failingApplyProvider is a provider which fails to apply any changes
+type failingApplyProvider struct {
+	provider.Provider
+}
+
+func (p *failingApplyProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	return errors.New("failed to apply changes")
+}
+
+func TestTXTRegistryAdoptUnowned(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("legacy.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("annotated.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("other.test-zone.example.org", "1.2.3.6", endpoint.RecordTypeA, ""),
+		},
+	}))
+
+	r, err := NewTXTRegistry(p, "", "", "owner", 0, "", []string{}, WithTXTAdoptRegex(regexp.MustCompile(`^legacy\.`)))
+	require.NoError(t, err)
+
+	desired := r.AdjustEndpoints([]*endpoint.Endpoint{
+		newEndpointWithOwnerResource("legacy.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "", "ingress/default/legacy"),
+		newEndpointWithOwnerResource("annotated.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, "", "ingress/default/annotated"),
+		newEndpointWithOwnerResource("other.test-zone.example.org", "1.2.3.6", endpoint.RecordTypeA, "", "ingress/default/other"),
+	})
+	desired[1].Labels[endpoint.AdoptLabelKey] = "true"
+	assert.Equal(t, "true", desired[0].Labels[endpoint.AdoptLabelKey])
+	assert.Equal(t, "", desired[2].Labels[endpoint.AdoptLabelKey])
+
+	current, err := r.Records(ctx)
+	require.NoError(t, err)
+	p.OnApplyChanges = func(ctx context.Context, got *plan.Changes) {
+		assert.Len(t, got.UpdateNew, 2, "records not labeled for adoption stay unowned")
+		assert.Len(t, got.Create, 4)
+	}
+	changes := &plan.Changes{}
+	for _, ep := range desired {
+		for _, c := range current {
+			if c.DNSName == ep.DNSName {
+				changes.UpdateOld = append(changes.UpdateOld, c)
+				changes.UpdateNew = append(changes.UpdateNew, ep)
+			}
+		}
+	}
+	require.NoError(t, r.ApplyChanges(ctx, changes))
+	p.OnApplyChanges = nil
+
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	labels := map[string]endpoint.Labels{}
+	for _, record := range records {
+		labels[record.DNSName] = record.Labels
+	}
+	assert.Equal(t, map[string]endpoint.Labels{
+		"legacy.test-zone.example.org":    {endpoint.OwnerLabelKey: "owner", endpoint.ResourceLabelKey: "ingress/default/legacy"},
+		"annotated.test-zone.example.org": {endpoint.OwnerLabelKey: "owner", endpoint.ResourceLabelKey: "ingress/default/annotated"},
+		"other.test-zone.example.org":     {endpoint.OwnerLabelKey: ""},
+	}, labels, "the adoption label is not stored")
+
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	txtRecords := []string{}
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeTXT {
+			txtRecords = append(txtRecords, record.DNSName)
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"legacy.test-zone.example.org", "a-legacy.test-zone.example.org",
+		"annotated.test-zone.example.org", "a-annotated.test-zone.example.org",
+	}, txtRecords)
+}
+
+func TestTXTRegistryCollectGarbage(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("a-foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("gone.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("a-gone.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("cname-removed.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("foreign.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("manual.test-zone.example.org", "\"some text\"", endpoint.RecordTypeTXT, ""),
+		},
+	}))
+	txtRecords := func() []string {
+		records, err := p.Records(ctx)
+		require.NoError(t, err)
+		names := []string{}
+		for _, record := range records {
+			if record.RecordType == endpoint.RecordTypeTXT {
+				names = append(names, record.DNSName)
+			}
+		}
+		return names
+	}
+
+	r, err := NewTXTRegistry(p, "", "", "owner", 0, "", []string{}, WithTXTGarbageCollection(0, 2))
+	require.NoError(t, err)
+	collectGarbage := func() []*endpoint.Endpoint {
+		garbage, err := r.GarbageChanges(ctx)
+		require.NoError(t, err)
+		require.NoError(t, r.ApplyMaintenanceChanges(ctx, garbage))
+		return garbage.Delete
+	}
+
+	deleted := collectGarbage()
+	assert.Len(t, deleted, 2, "the number of deletions is limited")
+	assert.Equal(t, "a-gone.test-zone.example.org", deleted[0].DNSName)
+	assert.Equal(t, "cname-removed.test-zone.example.org", deleted[1].DNSName)
+
+	deleted = collectGarbage()
+	require.Len(t, deleted, 1)
+	assert.Equal(t, "gone.test-zone.example.org", deleted[0].DNSName)
+
+	assert.Empty(t, collectGarbage())
+	assert.ElementsMatch(t, []string{
+		"foo.test-zone.example.org",
+		"a-foo.test-zone.example.org",
+		"foreign.test-zone.example.org",
+		"manual.test-zone.example.org",
+	}, txtRecords())
+}
+
+func TestTXTRegistryGarbageCollectionInterval(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("gone.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+		},
+	}))
+
+	r, err := NewTXTRegistry(p, "", "", "owner", 0, "", []string{}, WithTXTGarbageCollection(time.Hour, 0))
+	require.NoError(t, err)
+
+	_, err = r.Records(ctx)
+	require.NoError(t, err)
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	assert.Len(t, records, 1, "reading the records does not change any")
+	maintenance := r.MaintenanceChanges()
+	require.Len(t, maintenance.Delete, 1)
+	require.NoError(t, r.ApplyMaintenanceChanges(ctx, maintenance))
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	// no further garbage collection within the interval
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("gone.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+		},
+	}))
+	_, err = r.Records(ctx)
+	require.NoError(t, err)
+	assert.False(t, r.MaintenanceChanges().HasChanges())
+}
+
+func TestTXTRegistryFormat(t *testing.T) {
+	for _, tc := range []struct {
+		format   string
+		expected []string
+	}{
+		{TXTFormatLegacy, []string{"foo.test-zone.example.org"}},
+		{TXTFormatDual, []string{"foo.test-zone.example.org", "a-foo.test-zone.example.org"}},
+		{TXTFormatTyped, []string{"a-foo.test-zone.example.org"}},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			ctx := context.Background()
+			p := inmemory.NewInMemoryProvider()
+			require.NoError(t, p.CreateZone(testZone))
+			r, err := NewTXTRegistry(p, "", "", "owner", 0, "", []string{}, WithTXTFormat(tc.format))
+			require.NoError(t, err)
+
+			require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+				Create: []*endpoint.Endpoint{newEndpointWithOwner("foo.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "")},
+			}))
+			records, err := p.Records(ctx)
+			require.NoError(t, err)
+			txtRecords := []string{}
+			for _, record := range records {
+				if record.RecordType == endpoint.RecordTypeTXT {
+					txtRecords = append(txtRecords, record.DNSName)
+				}
+			}
+			assert.ElementsMatch(t, tc.expected, txtRecords)
+		})
+	}
+
+	_, err := NewTXTRegistry(inmemory.NewInMemoryProvider(), "", "", "owner", 0, "", []string{}, WithTXTFormat("unknown"))
+	assert.Error(t, err)
+}
+
+func TestTXTRegistryFormatTypedMigration(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("dual.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("dual.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("a-dual.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("legacy.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("legacy.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("foreign.test-zone.example.org", "1.2.3.6", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("foreign.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, ""),
+		},
+	}))
+	txtRecords := func() []string {
+		records, err := p.Records(ctx)
+		require.NoError(t, err)
+		names := []string{}
+		for _, record := range records {
+			if record.RecordType == endpoint.RecordTypeTXT {
+				names = append(names, record.DNSName)
+			}
+		}
+		return names
+	}
+
+	r, err := NewTXTRegistry(p, "", "", "owner", 0, "", []string{endpoint.RecordTypeA}, WithTXTFormat(TXTFormatTyped))
+	require.NoError(t, err)
+
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	for _, record := range records {
+		if record.DNSName != "foreign.test-zone.example.org" {
+			assert.Equal(t, "owner", record.Labels[endpoint.OwnerLabelKey], "records are owned with TXT records in either format")
+		}
+	}
+	// the TXT record in the old format is only deleted once the one in the new format exists
+	maintenance := r.MaintenanceChanges()
+	require.Len(t, maintenance.Delete, 1)
+	assert.Equal(t, "dual.test-zone.example.org", maintenance.Delete[0].DNSName)
+	require.NoError(t, r.ApplyMaintenanceChanges(ctx, maintenance))
+	assert.ElementsMatch(t, []string{
+		"a-dual.test-zone.example.org",
+		"legacy.test-zone.example.org",
+		"foreign.test-zone.example.org",
+	}, txtRecords())
+	missing := r.MissingRecords()
+	require.Len(t, missing, 1)
+	assert.Equal(t, "a-legacy.test-zone.example.org", missing[0].DNSName)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: missing}))
+
+	// deleting a record deletes its TXT records in both formats
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		Delete: []*endpoint.Endpoint{newEndpointWithOwner("legacy.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, "owner")},
+	}))
+	assert.ElementsMatch(t, []string{
+		"a-dual.test-zone.example.org",
+		"foreign.test-zone.example.org",
+	}, txtRecords())
+}
+
+func TestTXTRegistryOwnerIDs(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("rec-a.test-zone.example.org", "1.1.1.1", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("rec-a.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=a\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("rec-b.test-zone.example.org", "2.2.2.2", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("rec-b.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=b\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("rec-c.test-zone.example.org", "3.3.3.3", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("rec-c.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=c\"", endpoint.RecordTypeTXT, ""),
+		},
+	}))
+
+	mapping, err := ParseOwnerIDMapping("b=*/team-b/*")
+	require.NoError(t, err)
+	_, err = NewTXTRegistry(p, "", "", "a", 0, "", []string{}, WithTXTOwnerIDs(nil, []OwnerIDMapping{mapping}))
+	require.Error(t, err, "mappings must refer to the owner ids")
+	r, err := NewTXTRegistry(p, "", "", "a", 0, "", []string{}, WithTXTFormat(TXTFormatLegacy), WithTXTOwnerIDs([]string{"b"}, []OwnerIDMapping{mapping}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, r.OwnerIDs())
+
+	current, err := r.Records(ctx)
+	require.NoError(t, err)
+	currentByName := map[string]*endpoint.Endpoint{}
+	for _, ep := range current {
+		currentByName[ep.DNSName] = ep
+	}
+
+	desired := r.AdjustEndpoints([]*endpoint.Endpoint{
+		newEndpointWithOwnerResource("new-b.test-zone.example.org", "4.4.4.4", endpoint.RecordTypeA, "", "ingress/team-b/new"),
+		newEndpointWithOwnerResource("new-a.test-zone.example.org", "5.5.5.5", endpoint.RecordTypeA, "", "ingress/default/new"),
+		newEndpointWithOwnerResource("new-c.test-zone.example.org", "6.6.6.6", endpoint.RecordTypeA, "", "ingress/default/other"),
+		newEndpointWithOwnerResource("rec-a.test-zone.example.org", "1.1.1.2", endpoint.RecordTypeA, "a", "ingress/default/rec"),
+		newEndpointWithOwnerResource("rec-b.test-zone.example.org", "2.2.2.3", endpoint.RecordTypeA, "b", "ingress/default/rec"),
+		newEndpointWithOwnerResource("rec-c.test-zone.example.org", "3.3.3.4", endpoint.RecordTypeA, "c", "ingress/team-b/rec"),
+	})
+	desired[2].Labels[endpoint.RequestedOwnerLabelKey] = "c"
+	assert.Equal(t, "b", desired[0].Labels[endpoint.RequestedOwnerLabelKey])
+	assert.Equal(t, "", desired[1].Labels[endpoint.RequestedOwnerLabelKey])
+
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		Create:    desired[:3],
+		UpdateOld: []*endpoint.Endpoint{currentByName["rec-a.test-zone.example.org"], currentByName["rec-b.test-zone.example.org"], currentByName["rec-c.test-zone.example.org"]},
+		UpdateNew: desired[3:],
+	}))
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	txtTargets := map[string]string{}
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeTXT {
+			txtTargets[record.DNSName] = record.Targets[0]
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"new-b.test-zone.example.org": "\"heritage=external-dns,external-dns/owner=b,external-dns/resource=ingress/team-b/new\"",
+		"new-a.test-zone.example.org": "\"heritage=external-dns,external-dns/owner=a,external-dns/resource=ingress/default/new\"",
+		"rec-a.test-zone.example.org": "\"heritage=external-dns,external-dns/owner=a,external-dns/resource=ingress/default/rec\"",
+		"rec-b.test-zone.example.org": "\"heritage=external-dns,external-dns/owner=b\"",
+		"rec-c.test-zone.example.org": "\"heritage=external-dns,external-dns/owner=c\"",
+	}, txtTargets, "the requested owner label is not stored")
+
+	records, err = r.Records(ctx)
+	require.NoError(t, err)
+	owners := map[string]string{}
+	for _, record := range records {
+		owners[record.DNSName] = record.Labels[endpoint.OwnerLabelKey] + " " + record.Targets[0]
+	}
+	assert.Equal(t, map[string]string{
+		"new-b.test-zone.example.org": "b 4.4.4.4",
+		"new-a.test-zone.example.org": "a 5.5.5.5",
+		"rec-a.test-zone.example.org": "a 1.1.1.2",
+		"rec-b.test-zone.example.org": "b 2.2.2.2",
+		"rec-c.test-zone.example.org": "c 3.3.3.3",
+	}, owners, "records are only updated by resources assigned to their owner id")
+
+	// records of all owner ids are deleted
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		Delete: []*endpoint.Endpoint{currentByName["rec-b.test-zone.example.org"], currentByName["rec-c.test-zone.example.org"]},
+	}))
+	records, err = r.Records(ctx)
+	require.NoError(t, err)
+	names := []string{}
+	for _, record := range records {
+		names = append(names, record.DNSName)
+	}
+	assert.ElementsMatch(t, []string{"new-a.test-zone.example.org", "new-b.test-zone.example.org", "rec-a.test-zone.example.org", "rec-c.test-zone.example.org"}, names)
+}
+
+func TestParseOwnerIDMapping(t *testing.T) {
+	mapping, err := ParseOwnerIDMapping("team-a=*/team-a/*")
+	require.NoError(t, err)
+	assert.Equal(t, OwnerIDMapping{OwnerID: "team-a", ResourcePattern: "*/team-a/*"}, mapping)
+
+	for _, invalid := range []string{"team-a", "=*/team-a/*", "team-a=", "team-a=[team-a"} {
+		_, err := ParseOwnerIDMapping(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
 func TestDropPrefix(t *testing.T) {
 	mapper := newaffixNameMapper("foo-%{record_type}-", "", "")
 	cnameRecord := "foo-cname-test.example.com"
@@ -1147,7 +1775,11 @@ func TestNewTXTScheme(t *testing.T) {
 }
 
 func TestGenerateTXT(t *testing.T) {
-	record := newEndpointWithOwner("foo.test-zone.example.org", "new-foo.loadbalancer.com", endpoint.RecordTypeCNAME, "owner")
+	// the labels for the resolution of conflicts are not stored
+	record := newEndpointWithOwnerAndLabels("foo.test-zone.example.org", "new-foo.loadbalancer.com", endpoint.RecordTypeCNAME, "owner", endpoint.Labels{
+		endpoint.ResourceCreationTimestampLabelKey: "2022-01-01T00:00:00Z",
+		endpoint.ResourcePriorityLabelKey:          "10",
+	})
 	expectedTXT := []*endpoint.Endpoint{
 		{
 			DNSName:    "foo.test-zone.example.org",
@@ -1194,3 +1826,212 @@ func newEndpointWithOwnerResource(dnsName, target, recordType, ownerID, resource
 	e.Labels[endpoint.ResourceLabelKey] = resource
 	return e
 }
+
+func TestTXTRegistryHashedNames(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+	longName := strings.Repeat("x", 62) + ".test-zone.example.org"
+	txtRecords := func() []*endpoint.Endpoint {
+		records, err := p.Records(ctx)
+		require.NoError(t, err)
+		txts := []*endpoint.Endpoint{}
+		for _, record := range records {
+			if record.RecordType == endpoint.RecordTypeTXT {
+				txts = append(txts, record)
+			}
+		}
+		return txts
+	}
+
+	r, err := NewTXTRegistry(p, "", "", "owner", 0, "", []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME}, WithTXTNameMapper(TXTNameMapperHashed))
+	require.NoError(t, err)
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwnerResource(longName, "1.2.3.4", endpoint.RecordTypeA, "", "ingress/default/long"),
+			newEndpointWithOwnerResource("*.test-zone.example.org", "foo.example.com", endpoint.RecordTypeCNAME, "", "ingress/default/wildcard"),
+		},
+	}))
+
+	txts := txtRecords()
+	require.Len(t, txts, 4, "TXT records are created in both formats")
+	for _, txt := range txts {
+		assert.True(t, strings.HasSuffix(txt.DNSName, ".test-zone.example.org"), "TXT record %s stays in the zone", txt.DNSName)
+		for _, label := range strings.Split(txt.DNSName, ".") {
+			assert.LessOrEqual(t, len(label), 63, "TXT record %s has valid labels", txt.DNSName)
+			assert.NotEqual(t, "*", label, "TXT record %s is no wildcard", txt.DNSName)
+		}
+		assert.Regexp(t, `^"heritage=external-dns,external-dns/owner=owner,external-dns/record-name=(x{62}|\*)\.test-zone\.example\.org,external-dns/resource=ingress/default/(long|wildcard)"$`, txt.Targets[0],
+			"TXT record %s stores the labels and the name of its record", txt.DNSName)
+	}
+
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	for _, record := range records {
+		assert.Equal(t, "owner", record.Labels[endpoint.OwnerLabelKey], "record %s is owned", record.DNSName)
+		assert.NotContains(t, record.Labels, endpoint.RecordNameLabelKey, "record %s does not carry its stored name", record.DNSName)
+	}
+	assert.Empty(t, r.OrphanedRecords())
+
+	// the TXT records in the old format are deleted once the registry writes the new format only
+	typed, err := NewTXTRegistry(p, "", "", "owner", 0, "", []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME}, WithTXTNameMapper(TXTNameMapperHashed), WithTXTFormat(TXTFormatTyped))
+	require.NoError(t, err)
+	_, err = typed.Records(ctx)
+	require.NoError(t, err)
+	require.NoError(t, typed.ApplyMaintenanceChanges(ctx, typed.MaintenanceChanges()))
+	assert.Len(t, txtRecords(), 2)
+	records, err = typed.Records(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, typed.ApplyChanges(ctx, &plan.Changes{Delete: records}))
+	assert.Empty(t, txtRecords(), "TXT records are deleted along with their records")
+
+	_, err = NewTXTRegistry(p, "txt.", "", "owner", 0, "", []string{}, WithTXTNameMapper(TXTNameMapperHashed))
+	assert.Error(t, err)
+	_, err = NewTXTRegistry(p, "", "", "owner", 0, "", []string{}, WithTXTNameMapper("unknown"))
+	assert.Error(t, err)
+}
+
+func TestTXTRegistryHashedNamesOrphans(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+
+	r, err := NewTXTRegistry(p, "", "", "owner", 0, "", []string{endpoint.RecordTypeA}, WithTXTNameMapper(TXTNameMapperHashed), WithTXTFormat(TXTFormatTyped))
+	require.NoError(t, err)
+	record := newEndpointWithOwnerResource("*.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "", "ingress/default/wildcard")
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{record}}))
+	// the record is deleted behind the back of the registry
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Delete: []*endpoint.Endpoint{record}}))
+
+	_, err = r.Records(ctx)
+	require.NoError(t, err)
+	orphans := r.OrphanedRecords()
+	require.Len(t, orphans, 1)
+	assert.Equal(t, "*.test-zone.example.org", orphans[0].Labels[endpoint.RecordNameLabelKey], "orphaned TXT records are mapped back to the names of their records")
+
+	report := NewOwnershipReport(nil, orphans)
+	require.Len(t, report, 1)
+	assert.Equal(t, "*.test-zone.example.org", report[0].RecordName)
+}
+
+func TestTXTRegistryHashedNamesLength(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	require.NoError(t, p.CreateZone(testZone))
+	name := strings.Repeat(strings.Repeat("x", 62)+".", 3) + strings.Repeat("y", 39) + ".test-zone.example.org"
+	require.Len(t, name, 250)
+
+	r, err := NewTXTRegistry(p, "", "", "owner", 0, "", []string{endpoint.RecordTypeA}, WithTXTNameMapper(TXTNameMapperHashed))
+	require.NoError(t, err)
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{newEndpointWithOwnerResource(name, "1.2.3.4", endpoint.RecordTypeA, "", "ingress/default/long")},
+	}))
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	txts := 0
+	for _, record := range records {
+		if record.RecordType != endpoint.RecordTypeTXT {
+			continue
+		}
+		txts++
+		assert.LessOrEqual(t, len(record.DNSName), maxDNSNameLength)
+		for _, target := range record.Targets {
+			assert.LessOrEqual(t, len(strings.Trim(target, "\"")), 255, "TXT record %s fits into a character string", record.DNSName)
+		}
+	}
+	assert.Equal(t, 2, txts)
+
+	records, err = r.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "owner", records[0].Labels[endpoint.OwnerLabelKey])
+	assert.Equal(t, "ingress/default/long", records[0].Labels[endpoint.ResourceLabelKey])
+	assert.Empty(t, r.OrphanedRecords(), "TXT records without the name of their records are still matched by their hashed names")
+}
+
+func TestHashedNameMapper(t *testing.T) {
+	mapper := hashedNameMapper{}
+	assert.Equal(t, mapper.toTXTName("foo.example.org"), mapper.toTXTName("FOO.example.org."), "names are hashed case insensitive")
+	assert.Regexp(t, `^[a-z2-7]{16}\.example\.org$`, mapper.toTXTName("foo.example.org"))
+	assert.Regexp(t, `^cname-[a-z2-7]{16}\.example\.org$`, mapper.toNewTXTName("foo.example.org", endpoint.RecordTypeCNAME))
+	assert.NotEqual(t, mapper.toTXTName("foo.example.org"), mapper.toTXTName("bar.example.org"))
+	assert.Equal(t, "", mapper.toEndpointName(mapper.toTXTName("foo.example.org")))
+
+	// labels are dropped to stay within the maximum length of a name
+	long := "a." + strings.Repeat(strings.Repeat("x", 60)+".", 4) + "org"
+	require.LessOrEqual(t, len(long), maxDNSNameLength)
+	assert.LessOrEqual(t, len(mapper.toNewTXTName(long, endpoint.RecordTypeCNAME)), maxDNSNameLength)
+	assert.True(t, strings.HasSuffix(mapper.toNewTXTName(long, endpoint.RecordTypeCNAME), ".org"))
+}
+
+// countingProvider is a provider which counts the listings of its records
+type countingProvider struct {
+	provider.Provider
+	listings int
+}
+
+func (p *countingProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	p.listings++
+	return p.Provider.Records(ctx)
+}
+
+func TestTXTRegistryRecordsCacheStore(t *testing.T) {
+	ctx := context.Background()
+	inmemoryProvider := inmemory.NewInMemoryProvider()
+	require.NoError(t, inmemoryProvider.CreateZone(testZone))
+	require.NoError(t, inmemoryProvider.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+		},
+	}))
+	store := NewFileRecordsCacheStore(filepath.Join(t.TempDir(), "records-cache"))
+	newRegistry := func(p provider.Provider, fingerprint string) *TXTRegistry {
+		r, err := NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{}, WithTXTRecordsCacheStore(store, fingerprint))
+		require.NoError(t, err)
+		return r
+	}
+
+	p := &countingProvider{Provider: inmemoryProvider}
+	expected, err := newRegistry(p, "config").Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.listings)
+
+	// a restarted instance starts with the stored records
+	p = &countingProvider{Provider: inmemoryProvider}
+	r := newRegistry(p, "config")
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p.listings)
+	assert.Equal(t, expected, records)
+	assert.Equal(t, "owner", records[0].Labels[endpoint.OwnerLabelKey])
+	assert.Nil(t, r.OrphanedRecords(), "the orphaned records are only found when reading the provider")
+
+	// applied changes are stored as well
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{newEndpointWithOwner("bar.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, "")},
+	}))
+	snapshot, err := store.Load(ctx)
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Records, 2)
+
+	// the stored records of another configuration are discarded
+	p = &countingProvider{Provider: inmemoryProvider}
+	_, err = newRegistry(p, "other").Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.listings)
+
+	// failing changes invalidate the stored records
+	r = newRegistry(&failingApplyProvider{inmemoryProvider}, "other")
+	_, err = r.Records(ctx)
+	require.NoError(t, err)
+	require.Error(t, r.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{newEndpointWithOwner("baz.test-zone.example.org", "1.2.3.6", endpoint.RecordTypeA, "")},
+	}))
+	snapshot, err = store.Load(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+}
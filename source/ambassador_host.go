@@ -253,6 +253,11 @@ func parseAmbLoadBalancerService(service string) (namespace, name string, err er
 }
 
 func (sc *ambassadorHostSource) AddEventHandler(ctx context.Context, handler func()) {
+	log.Debug("Adding event handler for Ambassador Host")
+
+	// Right now there is no way to remove event handler from informer, see:
+	// https://github.com/kubernetes/kubernetes/issues/79610
+	sc.ambassadorHostInformer.Informer().AddEventHandler(eventHandlerFunc(handler))
 }
 
 // unstructuredConverter handles conversions between unstructured.Unstructured and Ambassador types
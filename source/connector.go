@@ -19,7 +19,10 @@ package source
 import (
 	"context"
 	"encoding/gob"
+	"fmt"
 	"net"
+	"reflect"
+	"sort"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -35,12 +38,16 @@ const (
 // to a remote tcp server. The encoding/decoding is done using encoder/gob package.
 type connectorSource struct {
 	remoteServer string
+	pollInterval time.Duration
 }
 
-// NewConnectorSource creates a new connectorSource with the given config.
-func NewConnectorSource(remoteServer string) (Source, error) {
+// NewConnectorSource creates a new connectorSource with the given config. The pollInterval is the
+// interval in which the remote server is polled for changed endpoints once an event handler was added,
+// polling is disabled if it is zero.
+func NewConnectorSource(remoteServer string, pollInterval time.Duration) (Source, error) {
 	return &connectorSource{
 		remoteServer: remoteServer,
+		pollInterval: pollInterval,
 	}, nil
 }
 
@@ -48,7 +55,8 @@ func NewConnectorSource(remoteServer string) (Source, error) {
 func (cs *connectorSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	endpoints := []*endpoint.Endpoint{}
 
-	conn, err := net.DialTimeout("tcp", cs.remoteServer, dialTimeout)
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", cs.remoteServer)
 	if err != nil {
 		log.Errorf("Connection error: %v", err)
 		return nil, err
@@ -66,5 +74,51 @@ func (cs *connectorSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint,
 	return endpoints, nil
 }
 
+// AddEventHandler adds an event handler that is triggered if the endpoints of the remote server change.
+// The remote server cannot announce changes, so it is polled every pollInterval until the context is done.
+// A poll which takes longer than the interval delays the next one instead of piling up connections.
 func (cs *connectorSource) AddEventHandler(ctx context.Context, handler func()) {
+	if cs.pollInterval <= 0 {
+		log.Debug("Polling of the connector is disabled, not adding event handler")
+		return
+	}
+
+	log.Debugf("Adding event handler for connector, polling %s every %s", cs.remoteServer, cs.pollInterval)
+
+	go func() {
+		ticker := time.NewTicker(cs.pollInterval)
+		defer ticker.Stop()
+
+		var last []string
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			endpoints, err := cs.Endpoints(ctx)
+			if err != nil {
+				continue
+			}
+			current := endpointKeys(endpoints)
+			if last != nil && !reflect.DeepEqual(last, current) {
+				handler()
+			}
+			last = current
+		}
+	}()
+}
+
+// endpointKeys returns the sorted string representations of the endpoints, which are equal if the endpoints are
+func endpointKeys(endpoints []*endpoint.Endpoint) []string {
+	keys := make([]string, 0, len(endpoints))
+	for _, ep := range endpoints {
+		targets := make([]string, len(ep.Targets))
+		copy(targets, ep.Targets)
+		sort.Strings(targets)
+		keys = append(keys, fmt.Sprintf("%s %s %s %d %v %v %v", ep.DNSName, ep.RecordType, ep.SetIdentifier, ep.RecordTTL, targets, ep.Labels, ep.ProviderSpecific))
+	}
+	sort.Strings(keys)
+	return keys
 }
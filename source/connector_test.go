@@ -20,7 +20,9 @@ import (
 	"context"
 	"encoding/gob"
 	"net"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -61,6 +63,8 @@ func TestConnectorSource(t *testing.T) {
 	suite.Run(t, new(ConnectorSuite))
 	t.Run("Interface", testConnectorSourceImplementsSource)
 	t.Run("Endpoints", testConnectorSourceEndpoints)
+	t.Run("AddEventHandler", testConnectorSourceAddEventHandler)
+	t.Run("AddEventHandlerWithoutPolling", testConnectorSourceAddEventHandlerWithoutPolling)
 }
 
 // testConnectorSourceImplementsSource tests that connectorSource is a valid Source.
@@ -129,7 +133,7 @@ func testConnectorSourceEndpoints(t *testing.T) {
 				defer ln.Close()
 				addr = ln.Addr().String()
 			}
-			cs, _ := NewConnectorSource(addr)
+			cs, _ := NewConnectorSource(addr, 0)
 
 			endpoints, err := cs.Endpoints(context.Background())
 			if ti.expectError {
@@ -143,3 +147,83 @@ func testConnectorSourceEndpoints(t *testing.T) {
 		})
 	}
 }
+
+// testConnectorSourceAddEventHandler tests that the event handler is triggered once the endpoints of the remote server change.
+func testConnectorSourceAddEventHandler(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// the remote server serves the same endpoints to the first connections and changed ones afterwards
+	var connections int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			target := "1.2.3.4"
+			if atomic.AddInt32(&connections, 1) > 3 {
+				target = "5.6.7.8"
+			}
+			gob.NewEncoder(conn).Encode([]*endpoint.Endpoint{endpoint.NewEndpoint("abc.example.org", endpoint.RecordTypeA, target)})
+			conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cs := &connectorSource{remoteServer: ln.Addr().String(), pollInterval: 10 * time.Millisecond}
+	events := make(chan struct{}, 10)
+	cs.AddEventHandler(ctx, func() {
+		events <- struct{}{}
+	})
+
+	select {
+	case <-events:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event handler was not triggered by the changed endpoints")
+	}
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&connections), int32(4))
+
+	// unchanged endpoints do not trigger the handler
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, events)
+}
+
+// testConnectorSourceAddEventHandlerWithoutPolling tests that the remote server is not polled if the poll interval is zero.
+func testConnectorSourceAddEventHandlerWithoutPolling(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var connections int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&connections, 1)
+			conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cs, _ := NewConnectorSource(ln.Addr().String(), 0)
+	cs.AddEventHandler(ctx, func() {})
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Zero(t, atomic.LoadInt32(&connections))
+}
@@ -248,6 +248,7 @@ func (sc *httpProxySource) setResourceLabel(httpProxy *projectcontour.HTTPProxy,
 	for _, ep := range endpoints {
 		ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("HTTPProxy/%s/%s", httpProxy.Namespace, httpProxy.Name)
 	}
+	setResourceLabels(httpProxy.ObjectMeta, endpoints)
 }
 
 // endpointsFromHTTPProxyConfig extracts the endpoints from a Contour HTTPProxy object
@@ -28,8 +28,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"sigs.k8s.io/external-dns/endpoint"
@@ -114,7 +116,28 @@ func NewCRDSource(crdClient rest.Interface, namespace, kind string, annotationFi
 	}, nil
 }
 
+// AddEventHandler adds an event handler that should be triggered if a watched DNSEndpoint changes.
+// The DNSEndpoints are only watched once an event handler is added, Endpoints always lists them.
 func (cs *crdSource) AddEventHandler(ctx context.Context, handler func()) {
+	log.Debug("Adding event handler for CRD")
+
+	informer := cache.NewSharedInformer(
+		&cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				opts.LabelSelector = cs.labelSelector.String()
+				return cs.List(ctx, &opts)
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				opts.LabelSelector = cs.labelSelector.String()
+				return cs.watch(ctx, &opts)
+			},
+		},
+		&endpoint.DNSEndpoint{},
+		0,
+	)
+	informer.AddEventHandler(eventHandlerFunc(handler))
+
+	go informer.Run(ctx.Done())
 }
 
 // Endpoints returns endpoint objects.
@@ -146,9 +169,14 @@ func (cs *crdSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error
 				continue
 			}
 
+			targets, err := normalizeTargets(ep)
+			if err != nil {
+				log.Warnf("Endpoint %s with DNSName %s has an invalid target: %v", dnsEndpoint.ObjectMeta.Name, ep.DNSName, err)
+				continue
+			}
 			illegalTarget := false
-			for _, target := range ep.Targets {
-				if strings.HasSuffix(target, ".") {
+			for _, target := range targets {
+				if !hasRecordData(ep.RecordType) && strings.HasSuffix(target, ".") {
 					illegalTarget = true
 					break
 				}
@@ -157,6 +185,7 @@ func (cs *crdSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error
 				log.Warnf("Endpoint %s with DNSName %s has an illegal target. The subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com')", dnsEndpoint.ObjectMeta.Name, ep.DNSName)
 				continue
 			}
+			ep.Targets = targets
 
 			if ep.Labels == nil {
 				ep.Labels = endpoint.NewLabels()
@@ -183,10 +212,34 @@ func (cs *crdSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error
 	return endpoints, nil
 }
 
+// normalizeTargets validates the targets of the endpoint and returns them in their canonical form
+func normalizeTargets(ep *endpoint.Endpoint) (endpoint.Targets, error) {
+	targets := make(endpoint.Targets, 0, len(ep.Targets))
+	for _, target := range ep.Targets {
+		normalized, err := endpoint.NormalizeTarget(ep.RecordType, target)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, normalized)
+	}
+	return targets, nil
+}
+
+// hasRecordData returns true for record types whose targets are not a plain hostname or address,
+// they may end with a dot
+func hasRecordData(recordType string) bool {
+	switch recordType {
+	case endpoint.RecordTypeMX, endpoint.RecordTypeSRV, endpoint.RecordTypeCAA, endpoint.RecordTypeTXT:
+		return true
+	}
+	return false
+}
+
 func (cs *crdSource) setResourceLabel(crd *endpoint.DNSEndpoint, endpoints []*endpoint.Endpoint) {
 	for _, ep := range endpoints {
 		ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("crd/%s/%s", crd.ObjectMeta.Namespace, crd.ObjectMeta.Name)
 	}
+	setResourceLabels(crd.ObjectMeta, endpoints)
 }
 
 func (cs *crdSource) List(ctx context.Context, opts *metav1.ListOptions) (result *endpoint.DNSEndpointList, err error) {
@@ -200,6 +253,15 @@ func (cs *crdSource) List(ctx context.Context, opts *metav1.ListOptions) (result
 	return
 }
 
+func (cs *crdSource) watch(ctx context.Context, opts *metav1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return cs.crdClient.Get().
+		Namespace(cs.namespace).
+		Resource(cs.crdResource).
+		VersionedParams(opts, cs.codec).
+		Watch(ctx)
+}
+
 func (cs *crdSource) UpdateStatus(ctx context.Context, dnsEndpoint *endpoint.DNSEndpoint) (result *endpoint.DNSEndpoint, err error) {
 	result = &endpoint.DNSEndpoint{}
 	err = cs.crdClient.Put().
@@ -119,6 +119,7 @@ func TestCRDSource(t *testing.T) {
 	suite.Run(t, new(CRDSuite))
 	t.Run("Interface", testCRDSourceImplementsSource)
 	t.Run("Endpoints", testCRDSourceEndpoints)
+	t.Run("RecordData", testCRDSourceRecordData)
 }
 
 // testCRDSourceImplementsSource tests that crdSource is a valid Source.
@@ -423,6 +424,43 @@ func testCRDSourceEndpoints(t *testing.T) {
 	}
 }
 
+// testCRDSourceRecordData tests that the targets of typed records are validated and normalized.
+func testCRDSourceRecordData(t *testing.T) {
+	longTXT := strings.Repeat("v", 300)
+	endpoints := []*endpoint.Endpoint{
+		{DNSName: "example.org", Targets: endpoint.Targets{"10 mail.example.org.", "20  backup.example.org"}, RecordType: endpoint.RecordTypeMX},
+		{DNSName: "example.org", Targets: endpoint.Targets{"0 issue letsencrypt.org"}, RecordType: endpoint.RecordTypeCAA},
+		{DNSName: "_sip._tcp.example.org", Targets: endpoint.Targets{"10 5 5060 sip.example.org."}, RecordType: endpoint.RecordTypeSRV},
+		{DNSName: "long.example.org", Targets: endpoint.Targets{longTXT}, RecordType: endpoint.RecordTypeTXT},
+		{DNSName: "spf.example.org", Targets: endpoint.Targets{"v=spf1 include:example.org."}, RecordType: endpoint.RecordTypeTXT},
+		{DNSName: "v6.example.org", Targets: endpoint.Targets{"2001:DB8::1"}, RecordType: endpoint.RecordTypeAAAA},
+		{DNSName: "invalid-mx.example.org", Targets: endpoint.Targets{"mail.example.org"}, RecordType: endpoint.RecordTypeMX},
+		{DNSName: "invalid-srv.example.org", Targets: endpoint.Targets{"10 5 sip.example.org"}, RecordType: endpoint.RecordTypeSRV},
+		{DNSName: "invalid-a.example.org", Targets: endpoint.Targets{"1.2.3.4", "foo.example.org"}, RecordType: endpoint.RecordTypeA},
+		{DNSName: "invalid-cname.example.org", Targets: endpoint.Targets{"foo.example.org."}, RecordType: endpoint.RecordTypeCNAME},
+	}
+	restClient := fakeRESTClient(endpoints, "test.k8s.io/v1alpha1", "DNSEndpoint", "foo", "test", nil, nil, t)
+	groupVersion, err := schema.ParseGroupVersion("test.k8s.io/v1alpha1")
+	require.NoError(t, err)
+	scheme := runtime.NewScheme()
+	require.NoError(t, addKnownTypes(scheme, groupVersion))
+
+	cs, err := NewCRDSource(restClient, "foo", "DNSEndpoint", "", labels.Everything(), scheme)
+	require.NoError(t, err)
+
+	receivedEndpoints, err := cs.Endpoints(context.Background())
+	require.NoError(t, err)
+
+	validateEndpoints(t, receivedEndpoints, []*endpoint.Endpoint{
+		{DNSName: "example.org", Targets: endpoint.Targets{"10 mail.example.org", "20 backup.example.org"}, RecordType: endpoint.RecordTypeMX},
+		{DNSName: "example.org", Targets: endpoint.Targets{`0 issue "letsencrypt.org"`}, RecordType: endpoint.RecordTypeCAA},
+		{DNSName: "_sip._tcp.example.org", Targets: endpoint.Targets{"10 5 5060 sip.example.org"}, RecordType: endpoint.RecordTypeSRV},
+		{DNSName: "long.example.org", Targets: endpoint.Targets{`"` + longTXT[:255] + `" "` + longTXT[255:] + `"`}, RecordType: endpoint.RecordTypeTXT},
+		{DNSName: "spf.example.org", Targets: endpoint.Targets{"v=spf1 include:example.org."}, RecordType: endpoint.RecordTypeTXT},
+		{DNSName: "v6.example.org", Targets: endpoint.Targets{"2001:db8::1"}, RecordType: endpoint.RecordTypeAAAA},
+	})
+}
+
 func validateCRDResource(t *testing.T, src Source, expectError bool) {
 	cs := src.(*crdSource)
 	result, err := cs.List(context.Background(), &metav1.ListOptions{})
@@ -239,6 +239,7 @@ func (src *gatewayRouteSource) Endpoints(ctx context.Context) ([]*endpoint.Endpo
 			for _, ep := range eps {
 				ep.Labels[endpoint.ResourceLabelKey] = resourceKey
 			}
+			setResourceLabels(*meta, eps)
 			endpoints = append(endpoints, eps...)
 		}
 		log.Debugf("Endpoints generated from %s %s/%s: %v", src.rtKind, meta.Namespace, meta.Name, endpoints)
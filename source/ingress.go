@@ -214,6 +214,7 @@ func (sc *ingressSource) setResourceLabel(ingress *networkv1.Ingress, endpoints
 	for _, ep := range endpoints {
 		ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("ingress/%s/%s", ingress.Namespace, ingress.Name)
 	}
+	setResourceLabels(ingress.ObjectMeta, endpoints)
 }
 
 func (sc *ingressSource) setDualstackLabel(ingress *networkv1.Ingress, endpoints []*endpoint.Endpoint) {
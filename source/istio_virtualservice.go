@@ -274,6 +274,7 @@ func (sc *virtualServiceSource) setResourceLabel(virtualservice *networkingv1alp
 	for _, ep := range endpoints {
 		ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("virtualservice/%s/%s", virtualservice.Namespace, virtualservice.Name)
 	}
+	setResourceLabels(virtualservice.ObjectMeta, endpoints)
 }
 
 // append a target to the list of targets unless it's already in the list
@@ -193,6 +193,7 @@ func (sc *kongTCPIngressSource) setResourceLabel(tcpIngress *TCPIngress, endpoin
 	for _, ep := range endpoints {
 		ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("tcpingress/%s/%s", tcpIngress.Namespace, tcpIngress.Name)
 	}
+	setResourceLabels(tcpIngress.ObjectMeta, endpoints)
 }
 
 func (sc *kongTCPIngressSource) setDualstackLabel(tcpIngress *TCPIngress, endpoints []*endpoint.Endpoint) {
@@ -19,6 +19,7 @@ package source
 import (
 	"context"
 	"fmt"
+	"strings"
 	"text/template"
 
 	log "github.com/sirupsen/logrus"
@@ -33,20 +34,39 @@ import (
 	"sigs.k8s.io/external-dns/endpoint"
 )
 
+// toBeDeletedTaint is the taint the cluster autoscaler adds to nodes it is about to drain and remove
+const toBeDeletedTaint = "ToBeDeletedByClusterAutoscaler"
+
 type nodeSource struct {
 	client           kubernetes.Interface
 	annotationFilter string
 	fqdnTemplate     *template.Template
 	nodeInformer     coreinformers.NodeInformer
+	addressTypes     []v1.NodeAddressType
+	addressFamily    string
+	excludeUnready   bool
+	aggregateFQDN    string
 }
 
-// NewNodeSource creates a new nodeSource with the given config.
-func NewNodeSource(ctx context.Context, kubeClient kubernetes.Interface, annotationFilter, fqdnTemplate string) (Source, error) {
+// NewNodeSource creates a new nodeSource with the given config. The addresses of the given types are published,
+// by default the ExternalIP and if there is none the InternalIP of a node. The address family restricts them
+// to "ipv4" or "ipv6" addresses. If excludeUnready is set, nodes which are not ready, cordoned or drained are skipped.
+// If aggregateFQDN is set, it is published with the addresses of all nodes in addition to the name of every node.
+func NewNodeSource(ctx context.Context, kubeClient kubernetes.Interface, annotationFilter, fqdnTemplate string, addressTypes []string, addressFamily string, excludeUnready bool, aggregateFQDN string) (Source, error) {
 	tmpl, err := parseTemplate(fqdnTemplate)
 	if err != nil {
 		return nil, err
 	}
 
+	nodeAddressTypes, err := parseNodeAddressTypes(addressTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateNodeAddressFamily(addressFamily); err != nil {
+		return nil, err
+	}
+
 	// Use shared informers to listen for add/update/delete of nodes.
 	// Set resync period to 0, to prevent processing when nothing has changed
 	informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0)
@@ -73,6 +93,10 @@ func NewNodeSource(ctx context.Context, kubeClient kubernetes.Interface, annotat
 		annotationFilter: annotationFilter,
 		fqdnTemplate:     tmpl,
 		nodeInformer:     nodeInformer,
+		addressTypes:     nodeAddressTypes,
+		addressFamily:    addressFamily,
+		excludeUnready:   excludeUnready,
+		aggregateFQDN:    aggregateFQDN,
 	}, nil
 }
 
@@ -89,6 +113,8 @@ func (ns *nodeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, erro
 	}
 
 	endpoints := map[string]*endpoint.Endpoint{}
+	var aggregateTargets endpoint.Targets
+	var aggregateTTL endpoint.TTL
 
 	// create endpoints for all nodes
 	for _, node := range nodes {
@@ -100,6 +126,11 @@ func (ns *nodeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, erro
 			continue
 		}
 
+		if ns.excludeUnready && !isNodeAvailable(node) {
+			log.Debugf("Skipping node %s because it is not ready, cordoned or drained", node.Name)
+			continue
+		}
+
 		log.Debugf("creating endpoint for node %s", node.Name)
 
 		ttl, err := getTTLFromAnnotations(node.Annotations)
@@ -107,10 +138,10 @@ func (ns *nodeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, erro
 			log.Warn(err)
 		}
 
-		// create new endpoint with the information we already have
+		// create new endpoint with the information we already have, it is split into A and AAAA endpoints once
+		// the addresses of all nodes are known
 		ep := &endpoint.Endpoint{
-			RecordType: "A", // hardcoded DNS record type
-			RecordTTL:  ttl,
+			RecordTTL: ttl,
 		}
 
 		if ns.fqdnTemplate != nil {
@@ -133,9 +164,17 @@ func (ns *nodeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, erro
 		if err != nil {
 			return nil, fmt.Errorf("failed to get node address from %s: %s", node.Name, err.Error())
 		}
+		if len(addrs) == 0 {
+			log.Debugf("Skipping node %s because it has no address of the selected types and family", node.Name)
+			continue
+		}
 
 		ep.Targets = endpoint.Targets(addrs)
 		ep.Labels = endpoint.NewLabels()
+		aggregateTargets = append(aggregateTargets, addrs...)
+		if ttl.IsConfigured() && (!aggregateTTL.IsConfigured() || ttl < aggregateTTL) {
+			aggregateTTL = ttl
+		}
 
 		log.Debugf("adding endpoint %s", ep)
 		if _, ok := endpoints[ep.DNSName]; ok {
@@ -145,38 +184,142 @@ func (ns *nodeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, erro
 		}
 	}
 
+	// the aggregated name gets the addresses of all nodes and the lowest TTL configured on any of them,
+	// records of the same name created from the template are merged into it
+	if ns.aggregateFQDN != "" && len(aggregateTargets) > 0 {
+		if ep, ok := endpoints[ns.aggregateFQDN]; ok {
+			ep.Targets = append(ep.Targets, aggregateTargets...)
+			ep.RecordTTL = aggregateTTL
+		} else {
+			endpoints[ns.aggregateFQDN] = &endpoint.Endpoint{DNSName: ns.aggregateFQDN, Targets: aggregateTargets, RecordTTL: aggregateTTL}
+		}
+	}
+
 	endpointsSlice := []*endpoint.Endpoint{}
 	for _, ep := range endpoints {
-		endpointsSlice = append(endpointsSlice, ep)
+		endpointsSlice = append(endpointsSlice, endpointsForAddresses(ep.DNSName, ep.RecordTTL, uniqueTargets(ep.Targets))...)
 	}
 
 	return endpointsSlice, nil
 }
 
+// AddEventHandler adds an event handler that should be triggered if a watched node changes.
 func (ns *nodeSource) AddEventHandler(ctx context.Context, handler func()) {
+	log.Debug("Adding event handler for node")
+
+	// Right now there is no way to remove event handler from informer, see:
+	// https://github.com/kubernetes/kubernetes/issues/79610
+	ns.nodeInformer.Informer().AddEventHandler(eventHandlerFunc(handler))
 }
 
-// nodeAddress returns node's externalIP and if that's not found, node's internalIP
-// basically what k8s.io/kubernetes/pkg/util/node.GetPreferredNodeAddress does
+// nodeAddresses returns the node's addresses of the configured types and address family, the node's annotations
+// take precedence over the flags. Without configured types it returns the node's externalIP and if that's not found,
+// node's internalIP, basically what k8s.io/kubernetes/pkg/util/node.GetPreferredNodeAddress does.
+// It returns no addresses without error if the node has addresses, but none of the selected types and family.
 func (ns *nodeSource) nodeAddresses(node *v1.Node) ([]string, error) {
+	addressTypes, addressFamily := ns.addressTypes, ns.addressFamily
+	if value, ok := node.Annotations[nodeAddressTypesAnnotationKey]; ok {
+		types, err := parseNodeAddressTypes(strings.Split(value, ","))
+		if err != nil {
+			log.Warnf("Ignoring annotation %s of node %s: %v", nodeAddressTypesAnnotationKey, node.Name, err)
+		} else {
+			addressTypes = types
+		}
+	}
+	if value, ok := node.Annotations[nodeAddressFamilyAnnotationKey]; ok {
+		if err := validateNodeAddressFamily(value); err != nil {
+			log.Warnf("Ignoring annotation %s of node %s: %v", nodeAddressFamilyAnnotationKey, node.Name, err)
+		} else {
+			addressFamily = value
+		}
+	}
+
 	addresses := map[v1.NodeAddressType][]string{
 		v1.NodeExternalIP: {},
 		v1.NodeInternalIP: {},
 	}
 
+	found := false
 	for _, addr := range node.Status.Addresses {
+		if _, ok := addresses[addr.Type]; !ok {
+			continue
+		}
+		found = true
+		if !matchesAddressFamily(addr.Address, addressFamily) {
+			continue
+		}
 		addresses[addr.Type] = append(addresses[addr.Type], addr.Address)
 	}
 
+	if !found {
+		return nil, fmt.Errorf("could not find node address for %s", node.Name)
+	}
+
+	if len(addressTypes) > 0 {
+		var addrs []string
+		for _, addressType := range addressTypes {
+			addrs = append(addrs, addresses[addressType]...)
+		}
+		return addrs, nil
+	}
+
 	if len(addresses[v1.NodeExternalIP]) > 0 {
 		return addresses[v1.NodeExternalIP], nil
 	}
 
-	if len(addresses[v1.NodeInternalIP]) > 0 {
-		return addresses[v1.NodeInternalIP], nil
+	return addresses[v1.NodeInternalIP], nil
+}
+
+// parseNodeAddressTypes converts the names of node address types, ExternalIP or InternalIP, to their types
+func parseNodeAddressTypes(addressTypes []string) ([]v1.NodeAddressType, error) {
+	nodeAddressTypes := make([]v1.NodeAddressType, 0, len(addressTypes))
+	for _, addressType := range addressTypes {
+		switch v1.NodeAddressType(strings.TrimSpace(addressType)) {
+		case v1.NodeExternalIP, v1.NodeInternalIP:
+			nodeAddressTypes = append(nodeAddressTypes, v1.NodeAddressType(strings.TrimSpace(addressType)))
+		default:
+			return nil, fmt.Errorf("unsupported node address type %q, expected %s or %s", addressType, v1.NodeExternalIP, v1.NodeInternalIP)
+		}
 	}
+	return nodeAddressTypes, nil
+}
+
+// validateNodeAddressFamily returns an error if the address family is neither empty, ipv4 nor ipv6
+func validateNodeAddressFamily(addressFamily string) error {
+	switch addressFamily {
+	case "", "ipv4", "ipv6":
+		return nil
+	}
+	return fmt.Errorf("unsupported node address family %q, expected ipv4 or ipv6", addressFamily)
+}
+
+// matchesAddressFamily returns true if the address belongs to the address family, any address matches an empty family
+func matchesAddressFamily(address, addressFamily string) bool {
+	switch addressFamily {
+	case "ipv4":
+		return suitableType(address) == endpoint.RecordTypeA
+	case "ipv6":
+		return suitableType(address) == endpoint.RecordTypeAAAA
+	}
+	return true
+}
 
-	return nil, fmt.Errorf("could not find node address for %s", node.Name)
+// isNodeAvailable returns false if the node is not ready, cordoned or about to be drained by the cluster autoscaler
+func isNodeAvailable(node *v1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == toBeDeletedTaint || taint.Key == v1.TaintNodeUnschedulable {
+			return false
+		}
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
 }
 
 // filterByAnnotations filters a list of nodes by a given annotation selector.
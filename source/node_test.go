@@ -19,6 +19,7 @@ package source
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -34,6 +35,8 @@ func TestNodeSource(t *testing.T) {
 
 	t.Run("NewNodeSource", testNodeSourceNewNodeSource)
 	t.Run("Endpoints", testNodeSourceEndpoints)
+	t.Run("EndpointsWithOptions", testNodeSourceEndpointsWithOptions)
+	t.Run("AddEventHandler", testNodeSourceAddEventHandler)
 }
 
 // testNodeSourceNewNodeSource tests that NewNodeService doesn't return an error.
@@ -44,6 +47,8 @@ func testNodeSourceNewNodeSource(t *testing.T) {
 		title            string
 		annotationFilter string
 		fqdnTemplate     string
+		addressTypes     []string
+		addressFamily    string
 		expectError      bool
 	}{
 		{
@@ -65,6 +70,22 @@ func testNodeSourceNewNodeSource(t *testing.T) {
 			expectError:      false,
 			annotationFilter: "kubernetes.io/ingress.class=nginx",
 		},
+		{
+			title:         "valid address types and family",
+			expectError:   false,
+			addressTypes:  []string{"InternalIP", "ExternalIP"},
+			addressFamily: "ipv6",
+		},
+		{
+			title:        "invalid address type",
+			expectError:  true,
+			addressTypes: []string{"Hostname"},
+		},
+		{
+			title:         "invalid address family",
+			expectError:   true,
+			addressFamily: "ipv5",
+		},
 	} {
 		ti := ti
 		t.Run(ti.title, func(t *testing.T) {
@@ -75,6 +96,10 @@ func testNodeSourceNewNodeSource(t *testing.T) {
 				fake.NewSimpleClientset(),
 				ti.annotationFilter,
 				ti.fqdnTemplate,
+				ti.addressTypes,
+				ti.addressFamily,
+				false,
+				"",
 			)
 
 			if ti.expectError {
@@ -358,6 +383,10 @@ func testNodeSourceEndpoints(t *testing.T) {
 				kubernetes,
 				tc.annotationFilter,
 				tc.fqdnTemplate,
+				nil,
+				"",
+				false,
+				"",
 			)
 			require.NoError(t, err)
 
@@ -373,3 +402,288 @@ func testNodeSourceEndpoints(t *testing.T) {
 		})
 	}
 }
+
+// testNodeSourceEndpointsWithOptions tests the selection of addresses and nodes and the aggregated name.
+func testNodeSourceEndpointsWithOptions(t *testing.T) {
+	t.Parallel()
+
+	ready := []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	dualStack := []v1.NodeAddress{
+		{Type: v1.NodeExternalIP, Address: "1.2.3.4"},
+		{Type: v1.NodeExternalIP, Address: "2001:db8::1"},
+		{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+		{Type: v1.NodeInternalIP, Address: "fd00::1"},
+	}
+
+	for _, tc := range []struct {
+		title          string
+		fqdnTemplate   string
+		addressTypes   []string
+		addressFamily  string
+		excludeUnready bool
+		aggregateFQDN  string
+		nodes          []*v1.Node
+		expected       []*endpoint.Endpoint
+	}{
+		{
+			title:        "internal addresses",
+			addressTypes: []string{"InternalIP"},
+			nodes: []*v1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "node1"}, Status: v1.NodeStatus{Addresses: dualStack}},
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"10.0.0.1"}},
+				{RecordType: "AAAA", DNSName: "node1", Targets: endpoint.Targets{"fd00::1"}},
+			},
+		},
+		{
+			title:        "external and internal addresses",
+			addressTypes: []string{"ExternalIP", "InternalIP"},
+			nodes: []*v1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "node1"}, Status: v1.NodeStatus{Addresses: dualStack}},
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4", "10.0.0.1"}},
+				{RecordType: "AAAA", DNSName: "node1", Targets: endpoint.Targets{"2001:db8::1", "fd00::1"}},
+			},
+		},
+		{
+			title:         "IPv6 addresses only",
+			addressFamily: "ipv6",
+			nodes: []*v1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "node1"}, Status: v1.NodeStatus{Addresses: dualStack}},
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "AAAA", DNSName: "node1", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			title:         "IPv4 addresses only falls back to the internal address",
+			addressFamily: "ipv4",
+			nodes: []*v1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "node1"}, Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+					{Type: v1.NodeExternalIP, Address: "2001:db8::1"},
+					{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				}}},
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"10.0.0.1"}},
+			},
+		},
+		{
+			title:         "nodes without address of the family are skipped",
+			addressTypes:  []string{"ExternalIP"},
+			addressFamily: "ipv6",
+			nodes: []*v1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "node1"}, Status: v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "node2"}, Status: v1.NodeStatus{Addresses: dualStack}},
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "AAAA", DNSName: "node2", Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			title:        "nodes without address of the types are skipped",
+			addressTypes: []string{"InternalIP"},
+			nodes: []*v1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "node1"}, Status: v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}}}},
+			},
+			expected: []*endpoint.Endpoint{},
+		},
+		{
+			title:         "annotations take precedence over the flags",
+			addressTypes:  []string{"ExternalIP"},
+			addressFamily: "ipv4",
+			nodes: []*v1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node1", Annotations: map[string]string{
+						nodeAddressTypesAnnotationKey:  "ExternalIP, InternalIP",
+						nodeAddressFamilyAnnotationKey: "ipv6",
+					}},
+					Status: v1.NodeStatus{Addresses: dualStack},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node2", Annotations: map[string]string{
+						nodeAddressTypesAnnotationKey:  "PublicIP",
+						nodeAddressFamilyAnnotationKey: "ipv5",
+					}},
+					Status: v1.NodeStatus{Addresses: dualStack},
+				},
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "AAAA", DNSName: "node1", Targets: endpoint.Targets{"2001:db8::1", "fd00::1"}},
+				{RecordType: "A", DNSName: "node2", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			title:          "unavailable nodes are excluded",
+			excludeUnready: true,
+			nodes: []*v1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "ready"},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.1"}}, Conditions: ready},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "not-ready"},
+					Status: v1.NodeStatus{
+						Addresses:  []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.2"}},
+						Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionFalse}},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "unknown"},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.3"}}},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "cordoned"},
+					Spec:       v1.NodeSpec{Unschedulable: true},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}}, Conditions: ready},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "draining"},
+					Spec:       v1.NodeSpec{Taints: []v1.Taint{{Key: "ToBeDeletedByClusterAutoscaler", Effect: v1.TaintEffectNoSchedule}}},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.5"}}, Conditions: ready},
+				},
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "ready", Targets: endpoint.Targets{"1.2.3.1"}},
+			},
+		},
+		{
+			title:          "unavailable nodes are included by default",
+			excludeUnready: false,
+			nodes: []*v1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "cordoned"},
+					Spec:       v1.NodeSpec{Unschedulable: true},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}}},
+				},
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "cordoned", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			title:          "per-node names and an aggregated name",
+			fqdnTemplate:   "{{.Name}}.nodes.example.org",
+			excludeUnready: true,
+			aggregateFQDN:  "nodes.example.org",
+			nodes: []*v1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}}, Conditions: ready},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node2"},
+					Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+						{Type: v1.NodeExternalIP, Address: "1.2.3.5"},
+						{Type: v1.NodeExternalIP, Address: "2001:db8::5"},
+					}, Conditions: ready},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node3"},
+					Spec:       v1.NodeSpec{Unschedulable: true},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.6"}}, Conditions: ready},
+				},
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1.nodes.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{RecordType: "A", DNSName: "node2.nodes.example.org", Targets: endpoint.Targets{"1.2.3.5"}},
+				{RecordType: "AAAA", DNSName: "node2.nodes.example.org", Targets: endpoint.Targets{"2001:db8::5"}},
+				{RecordType: "A", DNSName: "nodes.example.org", Targets: endpoint.Targets{"1.2.3.4", "1.2.3.5"}},
+				{RecordType: "AAAA", DNSName: "nodes.example.org", Targets: endpoint.Targets{"2001:db8::5"}},
+			},
+		},
+		{
+			title:         "aggregated name with the lowest TTL of the nodes",
+			fqdnTemplate:  "{{.Name}}.nodes.example.org",
+			aggregateFQDN: "nodes.example.org",
+			nodes: []*v1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node1", Annotations: map[string]string{ttlAnnotationKey: "300"}},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}}},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node2", Annotations: map[string]string{ttlAnnotationKey: "60"}},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.5"}}},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "node3"},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.6"}}},
+				},
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1.nodes.example.org", Targets: endpoint.Targets{"1.2.3.4"}, RecordTTL: 300},
+				{RecordType: "A", DNSName: "node2.nodes.example.org", Targets: endpoint.Targets{"1.2.3.5"}, RecordTTL: 60},
+				{RecordType: "A", DNSName: "node3.nodes.example.org", Targets: endpoint.Targets{"1.2.3.6"}},
+				{RecordType: "A", DNSName: "nodes.example.org", Targets: endpoint.Targets{"1.2.3.4", "1.2.3.5", "1.2.3.6"}, RecordTTL: 60},
+			},
+		},
+		{
+			title:         "aggregated name equal to the template",
+			fqdnTemplate:  "nodes.example.org",
+			aggregateFQDN: "nodes.example.org",
+			nodes: []*v1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "node1"}, Status: v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "node2"}, Status: v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.5"}}}},
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "nodes.example.org", Targets: endpoint.Targets{"1.2.3.4", "1.2.3.5"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			kubernetes := fake.NewSimpleClientset()
+			for _, node := range tc.nodes {
+				_, err := kubernetes.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+
+			client, err := NewNodeSource(context.TODO(), kubernetes, "", tc.fqdnTemplate, tc.addressTypes, tc.addressFamily, tc.excludeUnready, tc.aggregateFQDN)
+			require.NoError(t, err)
+
+			endpoints, err := client.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testNodeSourceAddEventHandler tests that the event handler is triggered by node changes.
+func testNodeSourceAddEventHandler(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kubernetes := fake.NewSimpleClientset()
+	client, err := NewNodeSource(ctx, kubernetes, "", "", nil, "", false, "")
+	require.NoError(t, err)
+
+	events := make(chan struct{}, 1)
+	client.AddEventHandler(ctx, func() {
+		select {
+		case events <- struct{}{}:
+		default:
+		}
+	})
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+		},
+	}
+	_, err = kubernetes.CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	select {
+	case <-events:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event handler was not triggered by the new node")
+	}
+}
@@ -228,6 +228,7 @@ func (ors *ocpRouteSource) setResourceLabel(ocpRoute *routev1.Route, endpoints [
 	for _, ep := range endpoints {
 		ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("route/%s/%s", ocpRoute.Namespace, ocpRoute.Name)
 	}
+	setResourceLabels(ocpRoute.ObjectMeta, endpoints)
 }
 
 // endpointsFromOcpRoute extracts the endpoints from a OpenShift Route object
@@ -73,7 +73,15 @@ func NewPodSource(ctx context.Context, kubeClient kubernetes.Interface, namespac
 	}, nil
 }
 
-func (*podSource) AddEventHandler(ctx context.Context, handler func()) {
+// AddEventHandler adds an event handler that should be triggered if a watched pod or node changes.
+// The addresses of nodes are published for pods using the host network.
+func (ps *podSource) AddEventHandler(ctx context.Context, handler func()) {
+	log.Debug("Adding event handler for pod")
+
+	// Right now there is no way to remove event handler from informer, see:
+	// https://github.com/kubernetes/kubernetes/issues/79610
+	ps.podInformer.Informer().AddEventHandler(eventHandlerFunc(handler))
+	ps.nodeInformer.Informer().AddEventHandler(eventHandlerFunc(handler))
 }
 
 func (ps *podSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
@@ -133,7 +141,7 @@ func (ps *podSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error
 	}
 	endpoints := []*endpoint.Endpoint{}
 	for domain, targets := range domains {
-		endpoints = append(endpoints, endpoint.NewEndpoint(domain, endpoint.RecordTypeA, targets...))
+		endpoints = append(endpoints, endpointsForAddresses(domain, 0, targets)...)
 	}
 	return endpoints, nil
 }
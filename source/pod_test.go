@@ -19,6 +19,7 @@ package source
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -428,3 +429,50 @@ func TestPodSource(t *testing.T) {
 
 	}
 }
+
+func TestPodSourceAddEventHandler(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kubernetes := fake.NewSimpleClientset()
+	client, err := NewPodSource(ctx, kubernetes, "kube-system", "")
+	require.NoError(t, err)
+
+	events := make(chan struct{}, 1)
+	client.AddEventHandler(ctx, func() {
+		select {
+		case events <- struct{}{}:
+		default:
+		}
+	})
+
+	// the addresses of nodes are published for pods using the host network, so node changes trigger the handler as well
+	for _, create := range []func() error{
+		func() error {
+			_, err := kubernetes.CoreV1().Nodes().Create(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "my-node1"}}, metav1.CreateOptions{})
+			return err
+		},
+		func() error {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "my-pod1",
+					Namespace:   "kube-system",
+					Annotations: map[string]string{hostnameAnnotationKey: "a.foo.example.org"},
+				},
+				Spec: corev1.PodSpec{HostNetwork: true, NodeName: "my-node1"},
+			}
+			_, err := kubernetes.CoreV1().Pods("kube-system").Create(ctx, pod, metav1.CreateOptions{})
+			return err
+		},
+	} {
+		require.NoError(t, create())
+
+		select {
+		case <-events:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the event handler was not triggered")
+		}
+	}
+}
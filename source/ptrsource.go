@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// ptrSource is a Source that adds PTR endpoints for the addresses of the A and AAAA endpoints of its wrapped source.
+type ptrSource struct {
+	source Source
+	zones  []string
+}
+
+// NewPTRSource creates a new ptrSource wrapping the provided Source. PTR endpoints are only created for
+// addresses whose reverse name is in one of the given reverse zones, e.g. 2.0.192.in-addr.arpa.
+func NewPTRSource(source Source, zones []string) Source {
+	normalized := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		if zone = strings.ToLower(strings.Trim(strings.TrimSpace(zone), ".")); zone != "" {
+			normalized = append(normalized, zone)
+		}
+	}
+	return &ptrSource{source: source, zones: normalized}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them along with a PTR endpoint for
+// every address in the reverse zones. The PTR endpoint of an address shared by several endpoints points
+// to all of their names. It takes its TTL, owner and resource from the endpoint with the lowest resource
+// label, so that the owner of the PTR record does not depend on the order of the wrapped source.
+func (ps *ptrSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ps.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ptrs := map[string]*endpoint.Endpoint{}
+	owners := map[string]*endpoint.Endpoint{}
+	var names []string
+	for _, ep := range endpoints {
+		if ep.RecordType != endpoint.RecordTypeA && ep.RecordType != endpoint.RecordTypeAAAA {
+			continue
+		}
+		// a wildcard does not name a single host
+		if strings.Contains(ep.DNSName, "*") {
+			continue
+		}
+		for _, target := range ep.Targets {
+			name, err := reverseName(target)
+			if err != nil {
+				log.Debugf("Skipping PTR record for target %s of %s: %v", target, ep.DNSName, err)
+				continue
+			}
+			if !ps.inZones(name) {
+				continue
+			}
+			ptr, ok := ptrs[name]
+			if !ok {
+				ptr = endpoint.NewEndpoint(name, endpoint.RecordTypePTR)
+				ptrs[name] = ptr
+				names = append(names, name)
+			}
+			if owner, ok := owners[name]; !ok || ownsBefore(ep, owner) {
+				owners[name] = ep
+			}
+			if !containsTarget(ptr.Targets, ep.DNSName) {
+				ptr.Targets = append(ptr.Targets, ep.DNSName)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		ptr, owner := ptrs[name], owners[name]
+		ptr.RecordTTL = owner.RecordTTL
+		for _, key := range []string{endpoint.OwnerLabelKey, endpoint.RequestedOwnerLabelKey, endpoint.ResourceLabelKey} {
+			if value, ok := owner.Labels[key]; ok {
+				ptr.Labels[key] = value
+			}
+		}
+		sort.Strings(ptr.Targets)
+		endpoints = append(endpoints, ptr)
+	}
+
+	return endpoints, nil
+}
+
+func (ps *ptrSource) AddEventHandler(ctx context.Context, handler func()) {
+	ps.source.AddEventHandler(ctx, handler)
+}
+
+// inZones returns true if the reverse name is in one of the reverse zones
+func (ps *ptrSource) inZones(name string) bool {
+	for _, zone := range ps.zones {
+		if strings.HasSuffix(name, "."+zone) {
+			return true
+		}
+	}
+	return false
+}
+
+// ownsBefore returns true if the endpoint a is preferred over b as the owner of a shared PTR record,
+// ordering by resource label first and DNS name second
+func ownsBefore(a, b *endpoint.Endpoint) bool {
+	if a.Labels[endpoint.ResourceLabelKey] != b.Labels[endpoint.ResourceLabelKey] {
+		return a.Labels[endpoint.ResourceLabelKey] < b.Labels[endpoint.ResourceLabelKey]
+	}
+	if a.DNSName != b.DNSName {
+		return a.DNSName < b.DNSName
+	}
+	return a.SetIdentifier < b.SetIdentifier
+}
+
+// reverseName returns the name of the PTR record of an IPv4 or IPv6 address,
+// e.g. 4.3.2.1.in-addr.arpa for 1.2.3.4
+func reverseName(address string) (string, error) {
+	ip, err := netip.ParseAddr(address)
+	if err != nil {
+		return "", fmt.Errorf("not an IP address")
+	}
+	ip = ip.Unmap()
+	if ip.Is4() {
+		octets := ip.As4()
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa", octets[3], octets[2], octets[1], octets[0]), nil
+	}
+	bytes := ip.As16()
+	nibbles := make([]string, 0, 32)
+	for i := len(bytes) - 1; i >= 0; i-- {
+		nibbles = append(nibbles, fmt.Sprintf("%x", bytes[i]&0x0f), fmt.Sprintf("%x", bytes[i]>>4))
+	}
+	return strings.Join(nibbles, ".") + ".ip6.arpa", nil
+}
+
+func containsTarget(targets endpoint.Targets, target string) bool {
+	for _, t := range targets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that ptrSource is a Source
+var _ Source = &ptrSource{}
+
+func TestPTRSource(t *testing.T) {
+	t.Run("Endpoints", testPTRSourceEndpoints)
+	t.Run("Error", testPTRSourceError)
+	t.Run("ReverseName", testPTRSourceReverseName)
+}
+
+// testPTRSourceEndpoints tests that PTR endpoints are added for the addresses in the reverse zones.
+func testPTRSourceEndpoints(t *testing.T) {
+	labels := endpoint.Labels{
+		endpoint.ResourceLabelKey:                  "service/default/foo",
+		endpoint.RequestedOwnerLabelKey:            "team-a",
+		endpoint.ResourceCreationTimestampLabelKey: "2022-01-01T00:00:00Z",
+	}
+	ptrLabels := endpoint.Labels{
+		endpoint.ResourceLabelKey:       "service/default/foo",
+		endpoint.RequestedOwnerLabelKey: "team-a",
+	}
+	barLabels := endpoint.Labels{
+		endpoint.ResourceLabelKey:       "service/default/bar",
+		endpoint.RequestedOwnerLabelKey: "team-b",
+	}
+
+	for _, tc := range []struct {
+		title     string
+		zones     []string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			"no A or AAAA endpoints adds nothing",
+			[]string{"2.0.192.in-addr.arpa"},
+			[]*endpoint.Endpoint{
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeCNAME, "bar.example.org"),
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeTXT, "192.0.2.1"),
+			},
+			[]*endpoint.Endpoint{
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeCNAME, "bar.example.org"),
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeTXT, "192.0.2.1"),
+			},
+		},
+		{
+			"IPv4 address in a reverse zone",
+			[]string{"2.0.192.in-addr.arpa"},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1", "198.51.100.1"}, RecordTTL: 300, Labels: labels},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1", "198.51.100.1"}, RecordTTL: 300, Labels: labels},
+				{DNSName: "1.2.0.192.in-addr.arpa", RecordType: endpoint.RecordTypePTR, Targets: endpoint.Targets{"foo.example.org"}, RecordTTL: 300, Labels: ptrLabels},
+			},
+		},
+		{
+			"IPv6 address in a reverse zone",
+			[]string{"8.b.d.0.1.0.0.2.ip6.arpa."},
+			[]*endpoint.Endpoint{
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeAAAA, "2001:db8::1"),
+			},
+			[]*endpoint.Endpoint{
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeAAAA, "2001:db8::1"),
+				endpoint.NewEndpoint("1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa", endpoint.RecordTypePTR, "foo.example.org"),
+			},
+		},
+		{
+			"address shared by several names",
+			[]string{"2.0.192.in-addr.arpa"},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, RecordTTL: 300, Labels: labels},
+				{DNSName: "bar.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, RecordTTL: 600, Labels: barLabels},
+				endpoint.NewEndpoint("*.example.org", endpoint.RecordTypeA, "192.0.2.1"),
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, RecordTTL: 300, Labels: labels},
+				{DNSName: "bar.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, RecordTTL: 600, Labels: barLabels},
+				endpoint.NewEndpoint("*.example.org", endpoint.RecordTypeA, "192.0.2.1"),
+				{DNSName: "1.2.0.192.in-addr.arpa", RecordType: endpoint.RecordTypePTR, Targets: endpoint.Targets{"bar.example.org", "foo.example.org"}, RecordTTL: 600, Labels: barLabels},
+			},
+		},
+		{
+			"owner of a shared address does not depend on the order",
+			[]string{"2.0.192.in-addr.arpa"},
+			[]*endpoint.Endpoint{
+				{DNSName: "bar.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, RecordTTL: 600, Labels: barLabels},
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, RecordTTL: 300, Labels: labels},
+			},
+			[]*endpoint.Endpoint{
+				{DNSName: "bar.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, RecordTTL: 600, Labels: barLabels},
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, RecordTTL: 300, Labels: labels},
+				{DNSName: "1.2.0.192.in-addr.arpa", RecordType: endpoint.RecordTypePTR, Targets: endpoint.Targets{"bar.example.org", "foo.example.org"}, RecordTTL: 600, Labels: barLabels},
+			},
+		},
+		{
+			"same name with several set identifiers",
+			[]string{"2.0.192.in-addr.arpa"},
+			[]*endpoint.Endpoint{
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "192.0.2.1").WithSetIdentifier("a"),
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "192.0.2.1").WithSetIdentifier("b"),
+			},
+			[]*endpoint.Endpoint{
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "192.0.2.1").WithSetIdentifier("a"),
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "192.0.2.1").WithSetIdentifier("b"),
+				endpoint.NewEndpoint("1.2.0.192.in-addr.arpa", endpoint.RecordTypePTR, "foo.example.org"),
+			},
+		},
+		{
+			"addresses outside of the reverse zones and invalid targets are skipped",
+			[]string{"2.0.192.in-addr.arpa", "8.b.d.0.1.0.0.2.ip6.arpa"},
+			[]*endpoint.Endpoint{
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "203.0.113.1", "bar.example.org"),
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeAAAA, "2001:db9::1"),
+			},
+			[]*endpoint.Endpoint{
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "203.0.113.1", "bar.example.org"),
+				endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeAAAA, "2001:db9::1"),
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			source := NewPTRSource(mockSource, tc.zones)
+
+			endpoints, err := source.Endpoints(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			validateEndpoints(t, endpoints, tc.expected)
+
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+// testPTRSourceError tests that an error of the wrapped source is returned.
+func testPTRSourceError(t *testing.T) {
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{}, errors.New("some error"))
+
+	_, err := NewPTRSource(mockSource, []string{"2.0.192.in-addr.arpa"}).Endpoints(context.Background())
+	assert.Error(t, err)
+}
+
+func testPTRSourceReverseName(t *testing.T) {
+	for address, expected := range map[string]string{
+		"192.0.2.10":       "10.2.0.192.in-addr.arpa",
+		"::ffff:192.0.2.1": "1.2.0.192.in-addr.arpa",
+		"2001:db8::abcd":   "d.c.b.a.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa",
+	} {
+		name, err := reverseName(address)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, name, address)
+	}
+
+	_, err := reverseName("foo.example.org")
+	assert.Error(t, err)
+}
@@ -29,6 +29,7 @@ import (
 	"time"
 	"unicode"
 
+	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -59,6 +60,16 @@ const (
 	controllerAnnotationValue = "dns-controller"
 	// The annotation used for defining the desired hostname
 	internalHostnameAnnotationKey = "external-dns.alpha.kubernetes.io/internal-hostname"
+	// The annotation used for ranking resources competing for the same DNS name
+	conflictPriorityAnnotationKey = "external-dns.alpha.kubernetes.io/conflict-priority"
+	// The annotation used for adopting existing records which are not owned by any instance of ExternalDNS
+	adoptAnnotationKey = "external-dns.alpha.kubernetes.io/adopt"
+	// The annotation used for requesting the owner id the records are managed under
+	ownerIDAnnotationKey = "external-dns.alpha.kubernetes.io/owner-id"
+	// The annotation used for selecting the comma separated types of node addresses to publish, e.g. ExternalIP,InternalIP
+	nodeAddressTypesAnnotationKey = "external-dns.alpha.kubernetes.io/node-address-types"
+	// The annotation used for selecting the family of node addresses to publish, ipv4 or ipv6
+	nodeAddressFamilyAnnotationKey = "external-dns.alpha.kubernetes.io/node-address-family"
 )
 
 const (
@@ -175,6 +186,33 @@ func getAliasFromAnnotations(annotations map[string]string) bool {
 	return exists && aliasAnnotation == "true"
 }
 
+// setResourceLabels adds the labels derived from the metadata of the resource: the ones used by the
+// conflict resolvers to pick one of the resources competing for the same DNS name, the adoption and the requested owner labels.
+func setResourceLabels(meta metav1.ObjectMeta, endpoints []*endpoint.Endpoint) {
+	var priority string
+	if p, exists := meta.Annotations[conflictPriorityAnnotationKey]; exists {
+		if _, err := strconv.Atoi(p); err != nil {
+			log.Warnf("%q is not a valid conflict priority of %s/%s", p, meta.Namespace, meta.Name)
+		} else {
+			priority = p
+		}
+	}
+	for _, ep := range endpoints {
+		if !meta.CreationTimestamp.IsZero() {
+			ep.Labels[endpoint.ResourceCreationTimestampLabelKey] = meta.CreationTimestamp.UTC().Format(time.RFC3339)
+		}
+		if priority != "" {
+			ep.Labels[endpoint.ResourcePriorityLabelKey] = priority
+		}
+		if meta.Annotations[adoptAnnotationKey] == "true" {
+			ep.Labels[endpoint.AdoptLabelKey] = "true"
+		}
+		if ownerID := meta.Annotations[ownerIDAnnotationKey]; ownerID != "" {
+			ep.Labels[endpoint.RequestedOwnerLabelKey] = ownerID
+		}
+	}
+}
+
 func getProviderSpecificAnnotations(annotations map[string]string) (endpoint.ProviderSpecific, string) {
 	providerSpecificAnnotations := endpoint.ProviderSpecific{}
 
@@ -237,9 +275,12 @@ func getTargetsFromTargetAnnotation(annotations map[string]string) endpoint.Targ
 }
 
 // suitableType returns the DNS resource record type suitable for the target.
-// In this case type A for IPs and type CNAME for everything else.
+// In this case type A for IPv4 addresses, type AAAA for IPv6 addresses and type CNAME for everything else.
 func suitableType(target string) string {
-	if net.ParseIP(target) != nil {
+	if ip := net.ParseIP(target); ip != nil {
+		if ip.To4() == nil {
+			return endpoint.RecordTypeAAAA
+		}
 		return endpoint.RecordTypeA
 	}
 	return endpoint.RecordTypeCNAME
@@ -250,12 +291,15 @@ func endpointsForHostname(hostname string, targets endpoint.Targets, ttl endpoin
 	var endpoints []*endpoint.Endpoint
 
 	var aTargets endpoint.Targets
+	var aaaaTargets endpoint.Targets
 	var cnameTargets endpoint.Targets
 
 	for _, t := range targets {
 		switch suitableType(t) {
 		case endpoint.RecordTypeA:
 			aTargets = append(aTargets, t)
+		case endpoint.RecordTypeAAAA:
+			aaaaTargets = append(aaaaTargets, t)
 		default:
 			cnameTargets = append(cnameTargets, t)
 		}
@@ -274,6 +318,19 @@ func endpointsForHostname(hostname string, targets endpoint.Targets, ttl endpoin
 		endpoints = append(endpoints, epA)
 	}
 
+	if len(aaaaTargets) > 0 {
+		epAAAA := &endpoint.Endpoint{
+			DNSName:          strings.TrimSuffix(hostname, "."),
+			Targets:          aaaaTargets,
+			RecordTTL:        ttl,
+			RecordType:       endpoint.RecordTypeAAAA,
+			Labels:           endpoint.NewLabels(),
+			ProviderSpecific: providerSpecific,
+			SetIdentifier:    setIdentifier,
+		}
+		endpoints = append(endpoints, epAAAA)
+	}
+
 	if len(cnameTargets) > 0 {
 		epCNAME := &endpoint.Endpoint{
 			DNSName:          strings.TrimSuffix(hostname, "."),
@@ -290,6 +347,27 @@ func endpointsForHostname(hostname string, targets endpoint.Targets, ttl endpoin
 	return endpoints
 }
 
+// endpointsForAddresses returns an A endpoint with the IPv4 targets and an AAAA endpoint with the IPv6 targets
+func endpointsForAddresses(dnsName string, ttl endpoint.TTL, targets endpoint.Targets) []*endpoint.Endpoint {
+	var aTargets, aaaaTargets endpoint.Targets
+	for _, t := range targets {
+		if suitableType(t) == endpoint.RecordTypeAAAA {
+			aaaaTargets = append(aaaaTargets, t)
+		} else {
+			aTargets = append(aTargets, t)
+		}
+	}
+
+	var endpoints []*endpoint.Endpoint
+	if len(aTargets) > 0 {
+		endpoints = append(endpoints, endpoint.NewEndpointWithTTL(dnsName, endpoint.RecordTypeA, ttl, aTargets...))
+	}
+	if len(aaaaTargets) > 0 {
+		endpoints = append(endpoints, endpoint.NewEndpointWithTTL(dnsName, endpoint.RecordTypeAAAA, ttl, aaaaTargets...))
+	}
+	return endpoints
+}
+
 func getLabelSelector(annotationFilter string) (labels.Selector, error) {
 	labelSelector, err := metav1.ParseToLabelSelector(annotationFilter)
 	if err != nil {
@@ -19,8 +19,10 @@ package source
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"sigs.k8s.io/external-dns/endpoint"
 )
@@ -94,6 +96,8 @@ func TestSuitableType(t *testing.T) {
 		target, recordType, expected string
 	}{
 		{"8.8.8.8", "", "A"},
+		{"2001:db8::1", "", "AAAA"},
+		{"::ffff:8.8.8.8", "", "A"},
 		{"foo.example.org", "", "CNAME"},
 		{"bar.eu-central-1.elb.amazonaws.com", "", "CNAME"},
 	} {
@@ -105,3 +109,79 @@ func TestSuitableType(t *testing.T) {
 		}
 	}
 }
+
+func TestEndpointsForHostname(t *testing.T) {
+	endpoints := endpointsForHostname("foo.example.org.", endpoint.Targets{"1.2.3.4", "2001:db8::1", "lb.example.com", "1.2.3.5"}, endpoint.TTL(60), nil, "")
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, RecordTTL: 60, Targets: endpoint.Targets{"1.2.3.4", "1.2.3.5"}},
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeAAAA, RecordTTL: 60, Targets: endpoint.Targets{"2001:db8::1"}},
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeCNAME, RecordTTL: 60, Targets: endpoint.Targets{"lb.example.com"}},
+	})
+}
+
+func TestEndpointsForAddresses(t *testing.T) {
+	validateEndpoints(t, endpointsForAddresses("foo.example.org", 0, endpoint.Targets{"1.2.3.4", "2001:db8::1", "2001:db8::2"}), []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeAAAA, Targets: endpoint.Targets{"2001:db8::1", "2001:db8::2"}},
+	})
+	validateEndpoints(t, endpointsForAddresses("foo.example.org", 0, endpoint.Targets{"2001:db8::1"}), []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeAAAA, Targets: endpoint.Targets{"2001:db8::1"}},
+	})
+}
+
+func TestSetResourceLabels(t *testing.T) {
+	created := metav1.NewTime(time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC))
+	for _, tc := range []struct {
+		title    string
+		meta     metav1.ObjectMeta
+		expected endpoint.Labels
+	}{
+		{
+			title:    "no creation timestamp and no priority",
+			meta:     metav1.ObjectMeta{},
+			expected: endpoint.Labels{},
+		},
+		{
+			title: "creation timestamp and priority",
+			meta: metav1.ObjectMeta{
+				CreationTimestamp: created,
+				Annotations:       map[string]string{conflictPriorityAnnotationKey: "10"},
+			},
+			expected: endpoint.Labels{
+				endpoint.ResourceCreationTimestampLabelKey: "2022-03-04T05:06:07Z",
+				endpoint.ResourcePriorityLabelKey:          "10",
+			},
+		},
+		{
+			title: "adoption",
+			meta: metav1.ObjectMeta{
+				Annotations: map[string]string{adoptAnnotationKey: "true"},
+			},
+			expected: endpoint.Labels{
+				endpoint.AdoptLabelKey: "true",
+			},
+		},
+		{
+			title: "requested owner",
+			meta: metav1.ObjectMeta{
+				Annotations: map[string]string{ownerIDAnnotationKey: "team-a"},
+			},
+			expected: endpoint.Labels{
+				endpoint.RequestedOwnerLabelKey: "team-a",
+			},
+		},
+		{
+			title: "invalid priority is ignored",
+			meta: metav1.ObjectMeta{
+				Annotations: map[string]string{conflictPriorityAnnotationKey: "high"},
+			},
+			expected: endpoint.Labels{},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			ep := endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")
+			setResourceLabels(tc.meta, []*endpoint.Endpoint{ep})
+			assert.Equal(t, tc.expected, ep.Labels)
+		})
+	}
+}
@@ -57,7 +57,12 @@ type Config struct {
 	PublishInternal                bool
 	PublishHostIP                  bool
 	AlwaysPublishNotReadyAddresses bool
+	NodeAddressTypes               []string
+	NodeAddressFamily              string
+	NodeExcludeUnready             bool
+	NodeAggregateFQDN              string
 	ConnectorServer                string
+	ConnectorPollInterval          time.Duration
 	CRDSourceAPIVersion            string
 	CRDSourceKind                  string
 	KubeConfig                     string
@@ -208,7 +213,7 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 		if err != nil {
 			return nil, err
 		}
-		return NewNodeSource(ctx, client, cfg.AnnotationFilter, cfg.FQDNTemplate)
+		return NewNodeSource(ctx, client, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.NodeAddressTypes, cfg.NodeAddressFamily, cfg.NodeExcludeUnready, cfg.NodeAggregateFQDN)
 	case "service":
 		client, err := p.KubeClient()
 		if err != nil {
@@ -296,7 +301,7 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 	case "fake":
 		return NewFakeSource(cfg.FQDNTemplate)
 	case "connector":
-		return NewConnectorSource(cfg.ConnectorServer)
+		return NewConnectorSource(cfg.ConnectorServer, cfg.ConnectorPollInterval)
 	case "crd":
 		client, err := p.KubeClient()
 		if err != nil {